@@ -0,0 +1,271 @@
+// Package agent implements a signing agent holding Falcon private keys, and
+// its client. The agent expands each key once into the LDL tree form used
+// by the sampler and serves signature requests over a Unix domain socket,
+// so that private keys stay out of the processes that need signatures.
+//
+// The agent only serves peers whose user ID it allows, as reported by the
+// kernel for the socket (SO_PEERCRED, on Linux only), and each key may be
+// restricted further to some user IDs and to a number of signatures.
+package agent
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// ErrNoPeerCredentials is returned when the credentials of a peer cannot be read
+var ErrNoPeerCredentials = errors.New("agent: peer credentials are not available")
+
+// Credentials identify the process at the other end of a connection.
+type Credentials struct {
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// Constraints restrict the use of a key.
+type Constraints struct {
+	// MaxSignatures is the number of signatures the key may make; zero
+	// means no limit.
+	MaxSignatures uint64
+	// UIDs restricts the key to peers running as one of these user IDs; if
+	// empty, every peer accepted by the agent may use the key.
+	UIDs []uint32
+}
+
+// agentKey is a key held by the agent.
+type agentKey struct {
+	expanded    *falcon.ExpandedKey
+	fingerprint string
+	comment     string
+	constraints Constraints
+	used        uint64
+}
+
+// Agent holds expanded private keys and signs with them for its peers.
+type Agent struct {
+	// AllowedUIDs are the user IDs of the peers served by the agent. If
+	// empty, only the user running the agent is served.
+	AllowedUIDs []uint32
+
+	mu   sync.Mutex
+	keys []*agentKey
+}
+
+// New returns an agent without keys.
+func New() *Agent {
+	return &Agent{}
+}
+
+// Add expands a private key and adds it to the agent with the given
+// constraints, returning its fingerprint. A key that is already held is
+// replaced, which resets its usage count.
+func (a *Agent) Add(privKey *falcon.PrivateKey, comment string, constraints Constraints) (string, error) {
+	expanded, err := privKey.Expand()
+	if err != nil {
+		return "", err
+	}
+	fingerprint, err := expanded.PublicKey().Fingerprint()
+	if err != nil {
+		return "", err
+	}
+	key := &agentKey{
+		expanded:    expanded,
+		fingerprint: fingerprint,
+		comment:     comment,
+		constraints: constraints,
+	}
+	key.constraints.UIDs = append([]uint32(nil), constraints.UIDs...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, k := range a.keys {
+		if k.fingerprint == fingerprint {
+			a.keys[i] = key
+			return fingerprint, nil
+		}
+	}
+	a.keys = append(a.keys, key)
+	return fingerprint, nil
+}
+
+// Remove removes the key with the given fingerprint, and reports whether
+// the agent held it.
+func (a *Agent) Remove(fingerprint string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, k := range a.keys {
+		if k.fingerprint == fingerprint {
+			a.keys = append(a.keys[:i], a.keys[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the keys held by the agent, in the order they were added.
+func (a *Agent) List() []Key {
+	return a.list(nil)
+}
+
+// list returns the keys that the peer may use, or every key if cred is nil.
+func (a *Agent) list(cred *Credentials) []Key {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var keys []Key
+	for _, k := range a.keys {
+		if cred != nil && !allowsUID(k.constraints.UIDs, cred.UID, true) {
+			continue
+		}
+		key := Key{
+			Fingerprint: k.fingerprint,
+			PublicKey:   k.expanded.PublicKey(),
+			Comment:     k.comment,
+			Limited:     k.constraints.MaxSignatures > 0,
+		}
+		if key.Limited {
+			key.Remaining = k.constraints.MaxSignatures - k.used
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Serve accepts connections on l and serves each of them in its own
+// goroutine, until l is closed. Connections from peers that are not allowed,
+// or whose credentials cannot be read, are closed at once.
+func (a *Agent) Serve(l *net.UnixListener) error {
+	for {
+		conn, err := l.AcceptUnix()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go a.serveConn(conn)
+	}
+}
+
+func (a *Agent) serveConn(conn *net.UnixConn) {
+	defer conn.Close()
+	cred, err := peerCredentials(conn)
+	if err != nil || !a.allowsPeer(cred) {
+		return
+	}
+	for {
+		req, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+		if err := writeMessage(conn, a.handle(req, cred)); err != nil {
+			return
+		}
+	}
+}
+
+// allowsPeer reports whether the agent serves the peer.
+func (a *Agent) allowsPeer(cred *Credentials) bool {
+	if len(a.AllowedUIDs) == 0 {
+		return cred.UID == uint32(os.Getuid())
+	}
+	return allowsUID(a.AllowedUIDs, cred.UID, false)
+}
+
+// allowsUID reports whether uid is in uids; an empty list allows every uid
+// when emptyAllows is set.
+func allowsUID(uids []uint32, uid uint32, emptyAllows bool) bool {
+	if len(uids) == 0 {
+		return emptyAllows
+	}
+	for _, u := range uids {
+		if u == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// handle returns the response to a request of the peer.
+func (a *Agent) handle(req []byte, cred *Credentials) []byte {
+	var resp []byte
+	var err error
+	switch req[0] {
+	case msgListRequest:
+		if len(req) != 1 {
+			return marshalFailure(failureInvalidRequest, "")
+		}
+		resp, err = marshalListResponse(a.list(cred))
+	case msgSignRequest:
+		r, perr := parseSignRequest(req)
+		if perr != nil {
+			return marshalFailure(failureInvalidRequest, "")
+		}
+		var sig []byte
+		sig, err = a.sign(r, cred)
+		if code, ok := failureCode(err); ok {
+			return marshalFailure(code, err.Error())
+		}
+		if err == nil {
+			resp, err = marshalSignResponse(sig)
+		}
+	default:
+		return marshalFailure(failureInvalidRequest, "")
+	}
+	if err != nil {
+		return marshalFailure(failureSign, err.Error())
+	}
+	return resp
+}
+
+// failureCode returns the code of the errors with a failure code.
+func failureCode(err error) (byte, bool) {
+	for code, e := range failureErrors {
+		if err == e {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// sign signs a request with one of the keys, counting the signature against
+// its usage limit.
+func (a *Agent) sign(req *signRequest, cred *Credentials) ([]byte, error) {
+	a.mu.Lock()
+	var key *agentKey
+	for _, k := range a.keys {
+		if k.fingerprint == req.fingerprint {
+			key = k
+		}
+	}
+	if key == nil {
+		a.mu.Unlock()
+		return nil, ErrUnknownKey
+	}
+	if !allowsUID(key.constraints.UIDs, cred.UID, true) {
+		a.mu.Unlock()
+		return nil, ErrPermissionDenied
+	}
+	limit := key.constraints.MaxSignatures
+	if limit > 0 && key.used >= limit {
+		a.mu.Unlock()
+		return nil, ErrUsageLimit
+	}
+	// The signature is counted before signing, so that concurrent requests
+	// cannot exceed the limit
+	key.used++
+	a.mu.Unlock()
+
+	sig, err := key.expanded.Sign(nil, req.data, &req.opts)
+	if err != nil {
+		a.mu.Lock()
+		key.used--
+		a.mu.Unlock()
+		return nil, err
+	}
+	return sig, nil
+}
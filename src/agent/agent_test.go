@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+func loadKey(t *testing.T, name string) *falcon.PrivateKey {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("../testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privKey
+}
+
+// startAgent serves a on a socket in a temporary directory and returns a
+// client connected to it.
+func startAgent(t *testing.T, a *Agent) *Client {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go a.Serve(l)
+	t.Cleanup(func() { l.Close() })
+	client, err := Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestSign(t *testing.T) {
+	privKey := loadKey(t, "falcon512_priv.pem")
+	a := New()
+	fingerprint, err := a.Add(privKey, "release key", Constraints{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := startAgent(t, a)
+
+	keys, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].Fingerprint != fingerprint || keys[0].Comment != "release key" ||
+		keys[0].Limited || !keys[0].PublicKey.Equal(privKey.GetPublicKey()) {
+		t.Fatalf("List() = %+v", keys)
+	}
+
+	signer, err := client.Signer(fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("release 1.0")
+	sig, err := signer.Sign(nil, message, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := signer.Public().(*falcon.PublicKey)
+	if err := pubKey.VerifyFNDSA(message, sig, nil); err != nil {
+		t.Errorf("pure signature: %v", err)
+	}
+
+	digest := sha256.Sum256(message)
+	if sig, err = signer.Sign(nil, digest[:], crypto.SHA256); err != nil {
+		t.Fatal(err)
+	}
+	if err := pubKey.VerifyFNDSA(message, sig, &falcon.Options{PreHash: falcon.PreHashSHA256}); err != nil {
+		t.Errorf("HashFN-DSA signature: %v", err)
+	}
+
+	opts := &falcon.Options{Context: []byte("releases"), Format: falcon.FormatCT}
+	if sig, err = signer.Sign(nil, message, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := pubKey.VerifyFNDSA(message, sig, opts); err != nil {
+		t.Errorf("signature with a context: %v", err)
+	}
+
+	if _, err := signer.Sign(nil, message, &falcon.Options{Format: 7}); err == nil {
+		t.Error("a signature in an unknown format was made")
+	}
+	if _, err := client.Signer("SHA256:unknown"); err != ErrUnknownKey {
+		t.Errorf("Signer(unknown) returned %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	a := New()
+	limited, err := a.Add(loadKey(t, "falcon512_priv.pem"), "", Constraints{MaxSignatures: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// No process runs as this user
+	other, err := a.Add(loadKey(t, "falcon1024_priv.pem"), "", Constraints{UIDs: []uint32{0xFFFFFFF0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := startAgent(t, a)
+
+	signer, err := client.Signer(limited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := signer.Sign(nil, []byte("message"), nil); err != nil {
+			t.Fatalf("signature %d: %v", i, err)
+		}
+	}
+	if _, err := signer.Sign(nil, []byte("message"), nil); err != ErrUsageLimit {
+		t.Errorf("third signature returned %v, want %v", err, ErrUsageLimit)
+	}
+	keys, err := client.List()
+	if err != nil || len(keys) != 1 || !keys[0].Limited || keys[0].Remaining != 0 {
+		t.Errorf("List() = %+v, %v", keys, err)
+	}
+	// The constraints of a key do not leak to the agent's own listing
+	if all := a.List(); len(all) != 2 || all[1].Fingerprint != other {
+		t.Errorf("Agent.List() = %+v", all)
+	}
+
+	// Keys restricted to other users cannot be listed or used
+	forged := &Signer{client: client, key: Key{Fingerprint: other}}
+	if _, err := forged.Sign(nil, []byte("message"), nil); err != ErrPermissionDenied {
+		t.Errorf("signature with a key of another user returned %v, want %v", err, ErrPermissionDenied)
+	}
+
+	if !a.Remove(limited) || a.Remove(limited) {
+		t.Error("Remove did not remove the key once")
+	}
+	if _, err := signer.Sign(nil, []byte("message"), nil); err != ErrUnknownKey {
+		t.Errorf("signature with a removed key returned %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestPeerCredentials(t *testing.T) {
+	a := New()
+	a.AllowedUIDs = []uint32{0xFFFFFFF0}
+	if _, err := a.Add(loadKey(t, "falcon512_priv.pem"), "", Constraints{}); err != nil {
+		t.Fatal(err)
+	}
+	client := startAgent(t, a)
+	if _, err := client.List(); err == nil {
+		t.Error("an agent served a user it does not allow")
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	a := New()
+	for _, req := range [][]byte{
+		{0},
+		{msgListRequest, 0},
+		{msgSignRequest, 0, 1},
+		{msgListResponse},
+	} {
+		if err := parseFailure(a.handle(req, &Credentials{})); err != ErrInvalidMessage {
+			t.Errorf("request %x returned %v, want %v", req, err, ErrInvalidMessage)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"crypto"
+	"io"
+	"net"
+	"sync"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// SocketEnv is the environment variable holding the path of the agent socket.
+const SocketEnv = "FALCON_AGENT_SOCK"
+
+var _ crypto.Signer = (*Signer)(nil)
+
+// Client talks to an agent. It is safe for concurrent use; requests are
+// sent one at a time.
+type Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Dial connects to the agent listening on the Unix socket at path.
+func Dial(path string) (*Client, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient returns a client of the agent at the other end of conn.
+func NewClient(conn net.Conn) *Client {
+	return &Client{conn: conn}
+}
+
+// Close closes the connection to the agent.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// call sends a request and returns the response, or the error of a failure.
+func (c *Client) call(req []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := writeMessage(c.conn, req); err != nil {
+		return nil, err
+	}
+	resp, err := readMessage(c.conn)
+	if err != nil {
+		return nil, err
+	}
+	if err := parseFailure(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// List returns the keys of the agent that the client may use.
+func (c *Client) List() ([]Key, error) {
+	resp, err := c.call([]byte{msgListRequest})
+	if err != nil {
+		return nil, err
+	}
+	return parseListResponse(resp)
+}
+
+// Signer returns a signer for the key of the agent with the given fingerprint.
+func (c *Client) Signer(fingerprint string) (*Signer, error) {
+	keys, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.Fingerprint == fingerprint {
+			return &Signer{client: c, key: key}, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// Signers returns signers for all the keys of the agent that the client may use.
+func (c *Client) Signers() ([]*Signer, error) {
+	keys, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+	signers := make([]*Signer, len(keys))
+	for i, key := range keys {
+		signers[i] = &Signer{client: c, key: key}
+	}
+	return signers, nil
+}
+
+// Signer is a key held by an agent. It implements crypto.Signer as
+// falcon.PrivateKey does.
+type Signer struct {
+	client *Client
+	key    Key
+}
+
+// Fingerprint returns the fingerprint of the key.
+func (s *Signer) Fingerprint() string {
+	return s.key.Fingerprint
+}
+
+// PublicKey returns the public key of the key.
+func (s *Signer) PublicKey() *falcon.PublicKey {
+	return s.key.PublicKey
+}
+
+// Public returns the public key of the key, as a *falcon.PublicKey.
+func (s *Signer) Public() crypto.PublicKey {
+	return s.key.PublicKey
+}
+
+// Sign has the semantics of falcon.PrivateKey.Sign. The signature
+// randomness is drawn by the agent, so random is ignored.
+func (s *Signer) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	o, err := falcon.SignerOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	req, err := marshalSignRequest(&signRequest{fingerprint: s.key.Fingerprint, data: digest, opts: *o})
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	resp, err := s.client.call(req)
+	if err != nil {
+		return nil, err
+	}
+	return parseSignResponse(resp)
+}
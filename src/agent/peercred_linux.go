@@ -0,0 +1,25 @@
+//go:build linux
+
+package agent
+
+import (
+	"net"
+	"syscall"
+)
+
+// peerCredentials returns the credentials of the peer of conn, with SO_PEERCRED.
+func peerCredentials(conn *net.UnixConn) (*Credentials, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var ucred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil {
+		return nil, ErrNoPeerCredentials
+	}
+	return &Credentials{PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package agent
+
+import "net"
+
+// peerCredentials is only implemented on Linux; elsewhere the agent serves
+// no peer.
+func peerCredentials(conn *net.UnixConn) (*Credentials, error) {
+	return nil, ErrNoPeerCredentials
+}
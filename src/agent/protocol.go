@@ -0,0 +1,264 @@
+package agent
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+/*
+Wire protocol. Every message is a uint32 big-endian length followed by that
+many bytes: a message type and its fields, strings being prefixed by their
+length as in cryptobyte (uint16 unless noted).
+
+  - listRequest:    (empty)
+  - listResponse:   uint32 count, then per key: fingerprint, public key
+    (falcon.MarshalPublicKey), comment, uint8 limited, uint64 remaining
+  - signRequest:    fingerprint, uint32-prefixed data, uint8 pre-hash,
+    uint8 format, uint8-prefixed context
+  - signResponse:   signature
+  - failure:        uint8 code, message
+
+A client sends one request at a time and reads its response.
+*/
+
+const (
+	msgListRequest  byte = 1
+	msgListResponse byte = 2
+	msgSignRequest  byte = 3
+	msgSignResponse byte = 4
+	msgFailure      byte = 5
+)
+
+// Failure codes.
+const (
+	failureInvalidRequest byte = 1
+	failureUnknownKey     byte = 2
+	failureUsageLimit     byte = 3
+	failurePermission     byte = 4
+	failureSign           byte = 5
+)
+
+// maxMessageLen bounds the length of messages, and thus of signed data.
+const maxMessageLen = 1 << 20
+
+var (
+	// ErrInvalidMessage is returned when a message of the protocol cannot be decoded
+	ErrInvalidMessage = errors.New("agent: invalid message")
+	// ErrUnknownKey is returned when the agent holds no key with the requested fingerprint
+	ErrUnknownKey = errors.New("agent: unknown key")
+	// ErrUsageLimit is returned when a key has made all the signatures it is allowed
+	ErrUsageLimit = errors.New("agent: key usage limit reached")
+	// ErrPermissionDenied is returned when the peer is not allowed to use a key
+	ErrPermissionDenied = errors.New("agent: permission denied")
+)
+
+// failureErrors maps failure codes to the errors returned by clients.
+var failureErrors = map[byte]error{
+	failureInvalidRequest: ErrInvalidMessage,
+	failureUnknownKey:     ErrUnknownKey,
+	failureUsageLimit:     ErrUsageLimit,
+	failurePermission:     ErrPermissionDenied,
+}
+
+// Key describes a key held by the agent.
+type Key struct {
+	Fingerprint string
+	PublicKey   *falcon.PublicKey
+	Comment     string
+	// Limited reports whether the key has a usage limit, in which case
+	// Remaining is the number of signatures it may still make.
+	Limited   bool
+	Remaining uint64
+}
+
+// signRequest is a decoded msgSignRequest.
+type signRequest struct {
+	fingerprint string
+	data        []byte
+	opts        falcon.Options
+}
+
+func readMessage(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n == 0 || n > maxMessageLen {
+		return nil, ErrInvalidMessage
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func writeMessage(w io.Writer, msg []byte) error {
+	if len(msg) > maxMessageLen {
+		return ErrInvalidMessage
+	}
+	frame := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(frame, uint32(len(msg)))
+	_, err := w.Write(append(frame, msg...))
+	return err
+}
+
+func marshalListResponse(keys []Key) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint8(msgListResponse)
+	b.AddUint32(uint32(len(keys)))
+	for _, key := range keys {
+		pub, err := falcon.MarshalPublicKey(key.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		addString(&b, []byte(key.Fingerprint))
+		addString(&b, pub)
+		addString(&b, []byte(key.Comment))
+		limited := uint8(0)
+		if key.Limited {
+			limited = 1
+		}
+		b.AddUint8(limited)
+		b.AddUint64(key.Remaining)
+	}
+	return b.Bytes()
+}
+
+func parseListResponse(msg []byte) ([]Key, error) {
+	s := cryptobyte.String(msg)
+	var msgType uint8
+	var count uint32
+	if !s.ReadUint8(&msgType) || msgType != msgListResponse || !s.ReadUint32(&count) {
+		return nil, ErrInvalidMessage
+	}
+	var keys []Key
+	for i := uint32(0); i < count; i++ {
+		var fingerprint, pub, comment cryptobyte.String
+		var limited uint8
+		var key Key
+		if !s.ReadUint16LengthPrefixed(&fingerprint) || !s.ReadUint16LengthPrefixed(&pub) ||
+			!s.ReadUint16LengthPrefixed(&comment) || !s.ReadUint8(&limited) || limited > 1 ||
+			!readUint64(&s, &key.Remaining) {
+			return nil, ErrInvalidMessage
+		}
+		pubKey, err := falcon.ParsePublicKey(pub)
+		if err != nil {
+			return nil, ErrInvalidMessage
+		}
+		key.Fingerprint = string(fingerprint)
+		key.PublicKey = pubKey
+		key.Comment = string(comment)
+		key.Limited = limited == 1
+		keys = append(keys, key)
+	}
+	if !s.Empty() {
+		return nil, ErrInvalidMessage
+	}
+	return keys, nil
+}
+
+func marshalSignRequest(req *signRequest) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint8(msgSignRequest)
+	addString(&b, []byte(req.fingerprint))
+	b.AddUint32LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(req.data)
+	})
+	b.AddUint8(uint8(req.opts.PreHash))
+	b.AddUint8(uint8(req.opts.Format))
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(req.opts.Context)
+	})
+	return b.Bytes()
+}
+
+func parseSignRequest(msg []byte) (*signRequest, error) {
+	s := cryptobyte.String(msg)
+	var msgType, preHash, format uint8
+	var dataLen uint32
+	var data []byte
+	var fingerprint, context cryptobyte.String
+	if !s.ReadUint8(&msgType) || msgType != msgSignRequest ||
+		!s.ReadUint16LengthPrefixed(&fingerprint) || !s.ReadUint32(&dataLen) || !s.ReadBytes(&data, int(dataLen)) ||
+		!s.ReadUint8(&preHash) || !s.ReadUint8(&format) ||
+		!s.ReadUint8LengthPrefixed(&context) || !s.Empty() {
+		return nil, ErrInvalidMessage
+	}
+	return &signRequest{
+		fingerprint: string(fingerprint),
+		data:        data,
+		opts: falcon.Options{
+			PreHash: falcon.PreHash(preHash),
+			Format:  falcon.SignatureFormat(format),
+			Context: context,
+		},
+	}, nil
+}
+
+func marshalSignResponse(sig []byte) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint8(msgSignResponse)
+	addString(&b, sig)
+	return b.Bytes()
+}
+
+func parseSignResponse(msg []byte) ([]byte, error) {
+	s := cryptobyte.String(msg)
+	var msgType uint8
+	var sig cryptobyte.String
+	if !s.ReadUint8(&msgType) || msgType != msgSignResponse || !s.ReadUint16LengthPrefixed(&sig) || !s.Empty() {
+		return nil, ErrInvalidMessage
+	}
+	return sig, nil
+}
+
+func marshalFailure(code byte, message string) []byte {
+	var b cryptobyte.Builder
+	b.AddUint8(msgFailure)
+	b.AddUint8(code)
+	addString(&b, []byte(message))
+	msg, err := b.Bytes()
+	if err != nil {
+		// Only an overlong message can fail
+		return []byte{msgFailure, code, 0, 0}
+	}
+	return msg
+}
+
+// parseFailure returns the error of a failure message, or nil if msg is not one.
+func parseFailure(msg []byte) error {
+	s := cryptobyte.String(msg)
+	var msgType, code uint8
+	var message cryptobyte.String
+	if !s.ReadUint8(&msgType) || msgType != msgFailure {
+		return nil
+	}
+	if !s.ReadUint8(&code) || !s.ReadUint16LengthPrefixed(&message) || !s.Empty() {
+		return ErrInvalidMessage
+	}
+	if err, ok := failureErrors[code]; ok {
+		return err
+	}
+	return errors.New("agent: " + string(message))
+}
+
+func addString(b *cryptobyte.Builder, v []byte) {
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(v)
+	})
+}
+
+func readUint64(s *cryptobyte.String, out *uint64) bool {
+	var v []byte
+	if !s.ReadBytes(&v, 8) {
+		return false
+	}
+	*out = binary.BigEndian.Uint64(v)
+	return true
+}
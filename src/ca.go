@@ -0,0 +1,322 @@
+package falcon
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+/*
+Issuance of X.509 v3 certificates signed by a Falcon CA key.
+
+The certificates are DER encoded with encoding/asn1. The signature value is a
+compressed Falcon signature of the TBSCertificate, as produced by oqs-provider.
+*/
+
+var (
+	// OIDExtKeyUsageServerAuth is the TLS server authentication extended key usage.
+	OIDExtKeyUsageServerAuth = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 1}
+	// OIDExtKeyUsageClientAuth is the TLS client authentication extended key usage.
+	OIDExtKeyUsageClientAuth = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}
+	// OIDExtKeyUsageCodeSigning is the code signing extended key usage.
+	OIDExtKeyUsageCodeSigning = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 3}
+	// OIDExtKeyUsageOCSPSigning is the OCSP response signing extended key usage.
+	OIDExtKeyUsageOCSPSigning = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 9}
+)
+
+var (
+	// ErrKeyMismatch is returned when a signing key does not match the issuer certificate
+	ErrKeyMismatch = errors.New("private key does not match the issuer public key")
+	// ErrInvalidSerialNumber is returned when a serial number is negative or longer than 20 bytes
+	ErrInvalidSerialNumber = errors.New("serial number must be positive and at most 20 bytes")
+	// ErrInvalidValidity is returned when a validity period is empty
+	ErrInvalidValidity = errors.New("invalid validity period")
+)
+
+type tbsCertificate struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm algorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           validity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+type validity struct {
+	NotBefore, NotAfter time.Time
+}
+
+// signedData is the outer structure of certificates, CSRs and CRLs.
+type signedData struct {
+	TBS                asn1.RawValue
+	SignatureAlgorithm algorithmIdentifier
+	Signature          asn1.BitString
+}
+
+// signTBS signs the DER structure tbs with privKey and returns the DER of
+// the signed structure.
+func signTBS(random io.Reader, tbs []byte, privKey *PrivateKey) ([]byte, error) {
+	oid, err := OIDFromDegree(privKey.n)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := privKey.signWithRand(tbs, FormatCompressed, random)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(signedData{
+		TBS:                asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: algorithmIdentifier{oid},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)},
+	})
+}
+
+// subjectKeyID computes a key identifier as the SHA-1 hash of the public key
+// encoding (RFC 5280, section 4.2.1.2, method 1).
+func subjectKeyID(pubKey *PublicKey) ([]byte, error) {
+	encoded, err := MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	h := sha1.Sum(encoded)
+	return h[:], nil
+}
+
+// marshalName returns raw if it is set, and the DER of name otherwise.
+func marshalName(raw []byte, name pkix.Name) ([]byte, error) {
+	if len(raw) > 0 {
+		return raw, nil
+	}
+	return asn1.Marshal(name.ToRDNSequence())
+}
+
+func marshalKeyUsage(usage x509.KeyUsage) (pkix.Extension, error) {
+	var b [2]byte
+	bitLength := 0
+	for i := 0; i < 9; i++ {
+		if usage&(1<<uint(i)) != 0 {
+			b[i/8] |= 0x80 >> uint(i%8)
+			bitLength = i + 1
+		}
+	}
+	value, err := asn1.Marshal(asn1.BitString{Bytes: b[:(bitLength+7)/8], BitLength: bitLength})
+	return pkix.Extension{Id: oidExtensionKeyUsage, Critical: true, Value: value}, err
+}
+
+func marshalExtKeyUsage(usages []asn1.ObjectIdentifier) (pkix.Extension, error) {
+	value, err := asn1.Marshal(usages)
+	return pkix.Extension{Id: oidExtensionExtKeyUsage, Value: value}, err
+}
+
+func marshalBasicConstraints(isCA bool, maxPathLen int) (pkix.Extension, error) {
+	// pathLenConstraint is omitted when maxPathLen is -1
+	value, err := asn1.Marshal(struct {
+		IsCA       bool `asn1:"optional"`
+		MaxPathLen int  `asn1:"optional,default:-1"`
+	}{isCA, maxPathLen})
+	return pkix.Extension{Id: oidExtensionBasicConstraints, Critical: true, Value: value}, err
+}
+
+func marshalSANs(dnsNames, emails []string, ips []net.IP, uris []*url.URL) ([]byte, error) {
+	var names []asn1.RawValue
+	for _, name := range dnsNames {
+		names = append(names, asn1.RawValue{Tag: nameTypeDNS, Class: asn1.ClassContextSpecific, Bytes: []byte(name)})
+	}
+	for _, email := range emails {
+		names = append(names, asn1.RawValue{Tag: nameTypeEmail, Class: asn1.ClassContextSpecific, Bytes: []byte(email)})
+	}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		names = append(names, asn1.RawValue{Tag: nameTypeIP, Class: asn1.ClassContextSpecific, Bytes: ip})
+	}
+	for _, uri := range uris {
+		names = append(names, asn1.RawValue{Tag: nameTypeURI, Class: asn1.ClassContextSpecific, Bytes: []byte(uri.String())})
+	}
+	return asn1.Marshal(names)
+}
+
+// hasSANs reports whether any subject alternative name is set.
+func hasSANs(dnsNames, emails []string, ips []net.IP, uris []*url.URL) bool {
+	return len(dnsNames) > 0 || len(emails) > 0 || len(ips) > 0 || len(uris) > 0
+}
+
+// isEmptyName reports whether the DER name is an empty sequence.
+func isEmptyName(raw []byte) bool {
+	return bytes.Equal(raw, []byte{0x30, 0x00})
+}
+
+// hasExtension reports whether an extension with the given OID is in extensions.
+func hasExtension(oid asn1.ObjectIdentifier, extensions []pkix.Extension) bool {
+	for _, ext := range extensions {
+		if ext.Id.Equal(oid) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildExtensions returns the extensions of a new certificate. An extension of
+// template.ExtraExtensions replaces the one derived from the template fields.
+func buildExtensions(template *Certificate, rawSubject, authorityKeyID, subjectKeyID []byte) ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+	add := func(ext pkix.Extension, err error) error {
+		if err == nil && !hasExtension(ext.Id, template.ExtraExtensions) {
+			extensions = append(extensions, ext)
+		}
+		return err
+	}
+
+	if hasSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs) {
+		value, err := marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
+		// the names must be critical when the subject is empty (RFC 5280, section 4.2.1.6)
+		if err := add(pkix.Extension{Id: oidExtensionSubjectAltName, Critical: isEmptyName(rawSubject), Value: value}, err); err != nil {
+			return nil, err
+		}
+	}
+	if template.KeyUsage != 0 {
+		if err := add(marshalKeyUsage(template.KeyUsage)); err != nil {
+			return nil, err
+		}
+	}
+	if len(template.ExtKeyUsage) > 0 {
+		if err := add(marshalExtKeyUsage(template.ExtKeyUsage)); err != nil {
+			return nil, err
+		}
+	}
+	if template.BasicConstraintsValid {
+		maxPathLen := template.MaxPathLen
+		if !template.IsCA || maxPathLen < 0 || maxPathLen == 0 && !template.MaxPathLenZero {
+			maxPathLen = -1
+		}
+		if err := add(marshalBasicConstraints(template.IsCA, maxPathLen)); err != nil {
+			return nil, err
+		}
+	}
+	if len(subjectKeyID) > 0 {
+		value, err := asn1.Marshal(subjectKeyID)
+		if err := add(pkix.Extension{Id: oidExtensionSubjectKeyId, Value: value}, err); err != nil {
+			return nil, err
+		}
+	}
+	if len(authorityKeyID) > 0 {
+		value, err := asn1.Marshal(struct {
+			KeyIdentifier []byte `asn1:"optional,tag:0"`
+		}{authorityKeyID})
+		if err := add(pkix.Extension{Id: oidExtensionAuthorityKeyId, Value: value}, err); err != nil {
+			return nil, err
+		}
+	}
+	return append(extensions, template.ExtraExtensions...), nil
+}
+
+// newSerialNumber returns a random positive serial number of at most 20 bytes.
+func newSerialNumber(random io.Reader) (*big.Int, error) {
+	serial := make([]byte, 20)
+	if _, err := io.ReadFull(random, serial); err != nil {
+		return nil, err
+	}
+	serial[0] &= 0x7f
+	return new(big.Int).SetBytes(serial), nil
+}
+
+// CreateCertificate issues an X.509 v3 certificate for pubKey, described by
+// template and signed with privKey, the key of parent. The certificate is
+// self-signed when parent is template. It returns the DER certificate.
+//
+// The following template fields are used: SerialNumber (random when nil),
+// Subject (or RawSubject), NotBefore, NotAfter, KeyUsage, ExtKeyUsage,
+// BasicConstraintsValid, IsCA, MaxPathLen, MaxPathLenZero, SubjectKeyId
+// (computed from pubKey when empty), DNSNames, EmailAddresses, IPAddresses,
+// URIs and ExtraExtensions. The authority key identifier is the subject key
+// identifier of parent. random defaults to crypto/rand.Reader.
+func CreateCertificate(random io.Reader, template, parent *Certificate, pubKey *PublicKey, privKey *PrivateKey) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	oid, err := OIDFromDegree(privKey.n)
+	if err != nil {
+		return nil, err
+	}
+	signerKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if parent.PublicKey != nil && (parent.PublicKey.n != privKey.n || !equalInt16(parent.PublicKey.h, signerKey.h)) {
+		return nil, ErrKeyMismatch
+	}
+	if !template.NotAfter.After(template.NotBefore) {
+		return nil, ErrInvalidValidity
+	}
+	spki, err := MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	serial := template.SerialNumber
+	if serial == nil {
+		if serial, err = newSerialNumber(random); err != nil {
+			return nil, err
+		}
+	}
+	if serial.Sign() < 0 || len(serial.Bytes()) > 20 {
+		return nil, ErrInvalidSerialNumber
+	}
+
+	rawSubject, err := marshalName(template.RawSubject, template.Subject)
+	if err != nil {
+		return nil, err
+	}
+	rawIssuer, err := marshalName(parent.RawSubject, parent.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	skid := template.SubjectKeyId
+	if len(skid) == 0 {
+		if skid, err = subjectKeyID(pubKey); err != nil {
+			return nil, err
+		}
+	}
+	var akid []byte
+	if parent != template {
+		akid = parent.SubjectKeyId
+	}
+	extensions, err := buildExtensions(template, rawSubject, akid, skid)
+	if err != nil {
+		return nil, err
+	}
+
+	tbs, err := asn1.Marshal(tbsCertificate{
+		Version:            2,
+		SerialNumber:       serial,
+		SignatureAlgorithm: algorithmIdentifier{oid},
+		Issuer:             asn1.RawValue{FullBytes: rawIssuer},
+		Validity:           validity{template.NotBefore.UTC().Truncate(time.Second), template.NotAfter.UTC().Truncate(time.Second)},
+		Subject:            asn1.RawValue{FullBytes: rawSubject},
+		PublicKey:          asn1.RawValue{FullBytes: spki},
+		Extensions:         extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signTBS(random, tbs, privKey)
+}
+
+// MarshalCertificatePEM encodes a DER certificate as a "CERTIFICATE" PEM block.
+func MarshalCertificatePEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificate, Bytes: der})
+}
@@ -0,0 +1,225 @@
+package falcon
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func loadPKIKey(t *testing.T, name string) *PrivateKey {
+	privKey, err := ParsePrivateKeyPEM(mustReadFile(t, "testdata/pki/"+name+"_key.pem"))
+	if err != nil {
+		t.Fatalf("%s: ParsePrivateKeyPEM returned %v", name, err)
+	}
+	return privKey
+}
+
+func TestCreateCertificate(t *testing.T) {
+	root, intermediate, _ := loadPKI(t)
+	intermediateKey := loadPKIKey(t, "intermediate")
+	leafKey := loadPKIKey(t, "leaf")
+
+	uri, _ := url.Parse("spiffe://example.com/service")
+	template := &Certificate{
+		SerialNumber:          big.NewInt(0x1234),
+		Subject:               pkix.Name{Organization: []string{"falconGo test PKI"}, CommonName: "service.example.com"},
+		NotBefore:             pkiTime.Add(-time.Hour),
+		NotAfter:              pkiTime.Add(90 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []asn1.ObjectIdentifier{OIDExtKeyUsageServerAuth, OIDExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"service.example.com", "*.service.example.com"},
+		EmailAddresses:        []string{"pki@example.com"},
+		IPAddresses:           []net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")},
+		URIs:                  []*url.URL{uri},
+	}
+	der, err := CreateCertificate(deterministicReader("issue"), template, intermediate, leafKey.GetPublicKey(), intermediateKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate returned %v", err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate returned %v", err)
+	}
+
+	if cert.SerialNumber.Int64() != 0x1234 || cert.Subject.CommonName != "service.example.com" ||
+		cert.Issuer.CommonName != "Falcon Test Intermediate CA" || !cert.NotBefore.Equal(template.NotBefore) {
+		t.Errorf("unexpected serial %v, subject %q, issuer %q or validity", cert.SerialNumber, cert.Subject.CommonName, cert.Issuer.CommonName)
+	}
+	if !reflect.DeepEqual(cert.DNSNames, template.DNSNames) || !reflect.DeepEqual(cert.EmailAddresses, template.EmailAddresses) ||
+		len(cert.IPAddresses) != 2 || !cert.IPAddresses[0].Equal(template.IPAddresses[0]) || !cert.IPAddresses[1].Equal(template.IPAddresses[1]) ||
+		len(cert.URIs) != 1 || cert.URIs[0].String() != uri.String() {
+		t.Errorf("subject alternative names do not round-trip")
+	}
+	if cert.KeyUsage != template.KeyUsage || !reflect.DeepEqual(cert.ExtKeyUsage, template.ExtKeyUsage) || cert.IsCA || !cert.BasicConstraintsValid {
+		t.Errorf("usages or basic constraints do not round-trip")
+	}
+	if string(cert.AuthorityKeyId) != string(intermediate.SubjectKeyId) || len(cert.SubjectKeyId) != 20 {
+		t.Errorf("key identifiers were not set")
+	}
+	if !reflect.DeepEqual(cert.PublicKey, leafKey.GetPublicKey()) {
+		t.Errorf("subject public key does not round-trip")
+	}
+
+	chains, err := cert.Verify(pkiOptions(root, intermediate))
+	if err != nil || len(chains) != 1 || len(chains[0]) != 3 {
+		t.Errorf("issued certificate does not verify: %v", err)
+	}
+
+	if _, err := CreateCertificate(nil, template, intermediate, leafKey.GetPublicKey(), leafKey); err != ErrKeyMismatch {
+		t.Errorf("signing with another key returned %v, want %v", err, ErrKeyMismatch)
+	}
+	template.SerialNumber = new(big.Int).Lsh(big.NewInt(1), 160)
+	if _, err := CreateCertificate(nil, template, intermediate, leafKey.GetPublicKey(), intermediateKey); err != ErrInvalidSerialNumber {
+		t.Errorf("21-byte serial returned %v, want %v", err, ErrInvalidSerialNumber)
+	}
+}
+
+func TestCreateSelfSignedCertificate(t *testing.T) {
+	template := &Certificate{
+		Subject:               pkix.Name{CommonName: "Falcon Self-Signed CA"},
+		NotBefore:             pkiTime,
+		NotAfter:              pkiTime.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := CreateCertificate(deterministicReader("self"), template, template, firstPrivKey512.GetPublicKey(), firstPrivKey512)
+	if err != nil {
+		t.Fatalf("CreateCertificate returned %v", err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate returned %v", err)
+	}
+	if cert.SerialNumber.Sign() <= 0 || len(cert.SerialNumber.Bytes()) > 20 {
+		t.Errorf("random serial number %v is not positive or too long", cert.SerialNumber)
+	}
+	if !cert.IsCA || cert.MaxPathLen != 0 || !cert.MaxPathLenZero || len(cert.AuthorityKeyId) != 0 {
+		t.Errorf("unexpected CA fields")
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		t.Errorf("self-signed certificate does not verify: %v", err)
+	}
+}
+
+func TestCertificateRequest(t *testing.T) {
+	root, intermediate, _ := loadPKI(t)
+	intermediateKey := loadPKIKey(t, "intermediate")
+	template := &CertificateRequest{
+		Subject:     pkix.Name{CommonName: "requested.example.com"},
+		DNSNames:    []string{"requested.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("198.51.100.7")},
+	}
+	der, err := CreateCertificateRequest(deterministicReader("csr"), template, firstPrivKey512)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest returned %v", err)
+	}
+	csr, err := ParseCertificateRequestPEM(MarshalCertificateRequestPEM(der))
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest returned %v", err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Errorf("CheckSignature returned %v", err)
+	}
+	if csr.Subject.CommonName != "requested.example.com" || !reflect.DeepEqual(csr.DNSNames, template.DNSNames) ||
+		len(csr.IPAddresses) != 1 || !csr.IPAddresses[0].Equal(template.IPAddresses[0]) {
+		t.Errorf("request fields do not round-trip")
+	}
+
+	// the CA issues a certificate from the request
+	certDER, err := CreateCertificate(deterministicReader("csr"), &Certificate{
+		RawSubject:  csr.RawSubject,
+		NotBefore:   pkiTime,
+		NotAfter:    pkiTime.AddDate(0, 1, 0),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		DNSNames:    csr.DNSNames,
+		IPAddresses: csr.IPAddresses,
+	}, intermediate, csr.PublicKey, intermediateKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate returned %v", err)
+	}
+	cert, _ := ParseCertificate(certDER)
+	if _, err := cert.Verify(pkiOptions(root, intermediate)); err != nil {
+		t.Errorf("certificate issued from the request does not verify: %v", err)
+	}
+
+	forged := *csr
+	forged.RawTBSCertificateRequest = append([]byte{}, csr.RawTBSCertificateRequest...)
+	forged.RawTBSCertificateRequest[len(forged.RawTBSCertificateRequest)-1] ^= 1
+	if err := forged.CheckSignature(); err != ErrCertificateSignature {
+		t.Errorf("modified request: CheckSignature returned %v, want %v", err, ErrCertificateSignature)
+	}
+}
+
+func TestCreateRevocationList(t *testing.T) {
+	_, intermediate, leaf := loadPKI(t)
+	intermediateKey := loadPKIKey(t, "intermediate")
+	template := &RevocationList{
+		Number:     big.NewInt(7),
+		ThisUpdate: pkiTime,
+		NextUpdate: pkiTime.AddDate(0, 0, 7),
+		RevokedCertificates: []pkix.RevokedCertificate{
+			{SerialNumber: leaf.SerialNumber, RevocationTime: pkiTime.Add(-time.Hour)},
+		},
+	}
+	der, err := CreateRevocationList(deterministicReader("crl"), template, intermediate, intermediateKey)
+	if err != nil {
+		t.Fatalf("CreateRevocationList returned %v", err)
+	}
+	rl, err := ParseRevocationList(der)
+	if err != nil {
+		t.Fatalf("ParseRevocationList returned %v", err)
+	}
+	if err := rl.CheckSignatureFrom(intermediate); err != nil {
+		t.Errorf("CheckSignatureFrom returned %v", err)
+	}
+	if rl.Number.Int64() != 7 || !rl.ThisUpdate.Equal(pkiTime) || !rl.NextUpdate.Equal(template.NextUpdate) ||
+		rl.Issuer.CommonName != "Falcon Test Intermediate CA" || string(rl.AuthorityKeyId) != string(intermediate.SubjectKeyId) {
+		t.Errorf("revocation list fields do not round-trip")
+	}
+	if !rl.IsRevoked(leaf.SerialNumber) || rl.IsRevoked(big.NewInt(1)) {
+		t.Errorf("IsRevoked does not match the revoked certificates")
+	}
+
+	// Without revocations, the revokedCertificates field is absent
+	empty := *template
+	empty.RevokedCertificates = []pkix.RevokedCertificate{}
+	der, err = CreateRevocationList(deterministicReader("crl"), &empty, intermediate, intermediateKey)
+	if err != nil {
+		t.Fatalf("CreateRevocationList without revocations returned %v", err)
+	}
+	var list struct {
+		TBS struct {
+			Version    int
+			Signature  asn1.RawValue
+			Issuer     asn1.RawValue
+			ThisUpdate time.Time
+			NextUpdate time.Time
+			Next       asn1.RawValue
+		}
+	}
+	if _, err := asn1.Unmarshal(der, &list); err != nil {
+		t.Fatal(err)
+	}
+	if next := list.TBS.Next; next.Class != asn1.ClassContextSpecific || next.Tag != 0 {
+		t.Errorf("the field after nextUpdate has class %d and tag %d, want the extensions", next.Class, next.Tag)
+	}
+	if rl, err := ParseRevocationList(der); err != nil || len(rl.RevokedCertificates) != 0 {
+		t.Errorf("ParseRevocationList without revocations returned %v", err)
+	}
+
+	if _, err := CreateRevocationList(nil, template, leaf, loadPKIKey(t, "leaf")); err != ErrIncompatibleUsage {
+		t.Errorf("a leaf signed a revocation list: %v", err)
+	}
+	if err := rl.CheckSignatureFrom(leaf); err != ErrIncompatibleUsage {
+		t.Errorf("CheckSignatureFrom(leaf) returned %v, want %v", err, ErrIncompatibleUsage)
+	}
+}
@@ -0,0 +1,573 @@
+package falcon
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+/*
+X.509 certificates signed with Falcon.
+
+crypto/x509 rejects unknown signature algorithms, so certificates whose
+signatureAlgorithm is OIDFalcon512 or OIDFalcon1024 are parsed here. The
+signature value is a Falcon signature of the DER TBSCertificate under the
+issuer's public key, in any of the signature formats.
+*/
+
+const pemTypeCertificate = "CERTIFICATE"
+
+// maxChainLength bounds the number of certificates in a chain built by Verify.
+const maxChainLength = 10
+
+var (
+	oidExtensionSubjectKeyId     = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionAuthorityKeyId   = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidExtensionExtKeyUsage      = asn1.ObjectIdentifier{2, 5, 29, 37}
+)
+
+var (
+	// ErrInvalidCertificate is returned when a certificate cannot be parsed
+	ErrInvalidCertificate = errors.New("malformed certificate")
+	// ErrCertificateSignature is returned when a certificate signature does not verify
+	ErrCertificateSignature = errors.New("certificate signature does not verify")
+	// ErrCertificateExpired is returned when the verification time is outside a validity period
+	ErrCertificateExpired = errors.New("certificate is expired or not yet valid")
+	// ErrNotCA is returned when an issuer is not a certification authority
+	ErrNotCA = errors.New("issuer is not a CA certificate")
+	// ErrPathLength is returned when a chain violates a path length constraint
+	ErrPathLength = errors.New("too many intermediates for path length constraint")
+	// ErrIncompatibleUsage is returned when a key usage does not allow the use of a certificate
+	ErrIncompatibleUsage = errors.New("certificate key usage is incompatible")
+	// ErrUnhandledCriticalExtension is returned when a certificate has an unknown critical extension
+	ErrUnhandledCriticalExtension = errors.New("unhandled critical extension")
+	// ErrUnknownAuthority is returned when no chain leads to a root
+	ErrUnknownAuthority = errors.New("certificate signed by unknown authority")
+)
+
+// Certificate is a parsed X.509 certificate. PublicKey is nil when the
+// subject key is not a Falcon-512 or Falcon-1024 key.
+type Certificate struct {
+	Raw                     []byte
+	RawTBSCertificate       []byte
+	RawSubjectPublicKeyInfo []byte
+	RawSubject              []byte
+	RawIssuer               []byte
+
+	Version            int
+	SerialNumber       *big.Int
+	SignatureAlgorithm asn1.ObjectIdentifier
+	Signature          []byte
+	Issuer             pkix.Name
+	Subject            pkix.Name
+	NotBefore          time.Time
+	NotAfter           time.Time
+
+	PublicKeyAlgorithm asn1.ObjectIdentifier
+	PublicKey          *PublicKey
+
+	Extensions []pkix.Extension
+	// unhandledCritical lists the critical extensions that are not understood.
+	unhandledCritical []asn1.ObjectIdentifier
+
+	KeyUsage    x509.KeyUsage
+	ExtKeyUsage []asn1.ObjectIdentifier
+
+	// BasicConstraintsValid is set when the basic constraints extension is present.
+	// MaxPathLen is -1 when no path length constraint is set. As in crypto/x509,
+	// a zero MaxPathLen is only written to a new certificate with MaxPathLenZero.
+	BasicConstraintsValid bool
+	IsCA                  bool
+	MaxPathLen            int
+	MaxPathLenZero        bool
+
+	SubjectKeyId   []byte
+	AuthorityKeyId []byte
+
+	// Subject alternative names.
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// ExtraExtensions are added as is to a new certificate; they are not
+	// filled by ParseCertificate.
+	ExtraExtensions []pkix.Extension
+}
+
+// ParseCertificate parses a single DER certificate.
+func ParseCertificate(der []byte) (*Certificate, error) {
+	cert := &Certificate{Raw: der, MaxPathLen: -1}
+	input := cryptobyte.String(der)
+	var certificate, tbs cryptobyte.String
+	if !input.ReadASN1(&certificate, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, ErrInvalidCertificate
+	}
+	if !certificate.ReadASN1Element(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, ErrInvalidCertificate
+	}
+	cert.RawTBSCertificate = tbs
+	if !tbs.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) {
+		return nil, ErrInvalidCertificate
+	}
+
+	var sigAlg asn1.ObjectIdentifier
+	var sigBits asn1.BitString
+	if !readAlgorithmOID(&certificate, &cert.SignatureAlgorithm) ||
+		!certificate.ReadASN1BitString(&sigBits) || !certificate.Empty() || sigBits.BitLength%8 != 0 {
+		return nil, ErrInvalidCertificate
+	}
+	cert.Signature = sigBits.Bytes
+
+	var version int64
+	if !tbs.ReadOptionalASN1Integer(&version, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific(), int64(0)) ||
+		version < 0 || version > 2 {
+		return nil, ErrInvalidCertificate
+	}
+	cert.Version = int(version) + 1
+	cert.SerialNumber = new(big.Int)
+	if !tbs.ReadASN1Integer(cert.SerialNumber) || !readAlgorithmOID(&tbs, &sigAlg) || !sigAlg.Equal(cert.SignatureAlgorithm) {
+		return nil, ErrInvalidCertificate
+	}
+
+	var issuer, validity, subject, spki cryptobyte.String
+	if !tbs.ReadASN1Element(&issuer, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1(&validity, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&subject, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&spki, cryptobyte_asn1.SEQUENCE) {
+		return nil, ErrInvalidCertificate
+	}
+	cert.RawIssuer, cert.RawSubject, cert.RawSubjectPublicKeyInfo = issuer, subject, spki
+	if err := parseName(issuer, &cert.Issuer); err != nil {
+		return nil, err
+	}
+	if err := parseName(subject, &cert.Subject); err != nil {
+		return nil, err
+	}
+	if !readTime(&validity, &cert.NotBefore) || !readTime(&validity, &cert.NotAfter) || !validity.Empty() {
+		return nil, ErrInvalidCertificate
+	}
+
+	var spkiAlg cryptobyte.String
+	if !spki.ReadASN1(&spki, cryptobyte_asn1.SEQUENCE) || !spki.ReadASN1(&spkiAlg, cryptobyte_asn1.SEQUENCE) ||
+		!spkiAlg.ReadASN1ObjectIdentifier(&cert.PublicKeyAlgorithm) {
+		return nil, ErrInvalidCertificate
+	}
+	if _, err := DegreeFromOID(cert.PublicKeyAlgorithm); err == nil {
+		pubKey, err := ParsePKIXPublicKey(cert.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return nil, err
+		}
+		cert.PublicKey = pubKey
+	}
+
+	// issuerUniqueID [1] and subjectUniqueID [2] are ignored
+	if !tbs.SkipOptionalASN1(cryptobyte_asn1.Tag(1).ContextSpecific()) ||
+		!tbs.SkipOptionalASN1(cryptobyte_asn1.Tag(2).ContextSpecific()) {
+		return nil, ErrInvalidCertificate
+	}
+	var extensions cryptobyte.String
+	var present bool
+	if !tbs.ReadOptionalASN1(&extensions, &present, cryptobyte_asn1.Tag(3).Constructed().ContextSpecific()) || !tbs.Empty() {
+		return nil, ErrInvalidCertificate
+	}
+	if present {
+		if cert.Version != 3 || !extensions.ReadASN1(&extensions, cryptobyte_asn1.SEQUENCE) {
+			return nil, ErrInvalidCertificate
+		}
+		for !extensions.Empty() {
+			var ext pkix.Extension
+			var extension cryptobyte.String
+			if !extensions.ReadASN1(&extension, cryptobyte_asn1.SEQUENCE) ||
+				!extension.ReadASN1ObjectIdentifier(&ext.Id) ||
+				!readOptionalBoolean(&extension, &ext.Critical) ||
+				!extension.ReadASN1Bytes(&ext.Value, cryptobyte_asn1.OCTET_STRING) || !extension.Empty() {
+				return nil, ErrInvalidCertificate
+			}
+			for _, seen := range cert.Extensions {
+				if seen.Id.Equal(ext.Id) {
+					return nil, ErrInvalidCertificate
+				}
+			}
+			cert.Extensions = append(cert.Extensions, ext)
+			if err := cert.parseExtension(ext); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cert, nil
+}
+
+// readAlgorithmOID reads an AlgorithmIdentifier, ignoring its parameters.
+func readAlgorithmOID(s *cryptobyte.String, oid *asn1.ObjectIdentifier) bool {
+	var algID cryptobyte.String
+	return s.ReadASN1(&algID, cryptobyte_asn1.SEQUENCE) && algID.ReadASN1ObjectIdentifier(oid)
+}
+
+// readOptionalBoolean reads a BOOLEAN DEFAULT FALSE. The DER encoding of the
+// default value is omitted, so an explicit FALSE is rejected.
+// (cryptobyte's ReadOptionalASN1Boolean skips the value it finds.)
+func readOptionalBoolean(s *cryptobyte.String, out *bool) bool {
+	var value cryptobyte.String
+	var present bool
+	if !s.ReadOptionalASN1(&value, &present, cryptobyte_asn1.BOOLEAN) {
+		return false
+	}
+	*out = present
+	return !present || len(value) == 1 && value[0] == 0xff
+}
+
+func readTime(s *cryptobyte.String, t *time.Time) bool {
+	switch {
+	case s.PeekASN1Tag(cryptobyte_asn1.UTCTime):
+		return s.ReadASN1UTCTime(t)
+	case s.PeekASN1Tag(cryptobyte_asn1.GeneralizedTime):
+		return s.ReadASN1GeneralizedTime(t)
+	}
+	return false
+}
+
+func parseName(raw []byte, name *pkix.Name) error {
+	var rdns pkix.RDNSequence
+	if rest, err := asn1.Unmarshal(raw, &rdns); err != nil || len(rest) != 0 {
+		return ErrInvalidCertificate
+	}
+	name.FillFromRDNSequence(&rdns)
+	return nil
+}
+
+// parseExtension fills the fields of cert from the extensions it understands.
+func (cert *Certificate) parseExtension(ext pkix.Extension) error {
+	value := cryptobyte.String(ext.Value)
+	switch {
+	case ext.Id.Equal(oidExtensionBasicConstraints):
+		var constraints cryptobyte.String
+		if !value.ReadASN1(&constraints, cryptobyte_asn1.SEQUENCE) || !value.Empty() ||
+			!readOptionalBoolean(&constraints, &cert.IsCA) {
+			return ErrInvalidCertificate
+		}
+		if !constraints.Empty() {
+			var pathLen int64
+			if !constraints.ReadASN1Integer(&pathLen) || !constraints.Empty() || pathLen < 0 || pathLen > 255 {
+				return ErrInvalidCertificate
+			}
+			cert.MaxPathLen = int(pathLen)
+			cert.MaxPathLenZero = pathLen == 0
+		}
+		cert.BasicConstraintsValid = true
+	case ext.Id.Equal(oidExtensionKeyUsage):
+		var bits asn1.BitString
+		if !value.ReadASN1BitString(&bits) || !value.Empty() {
+			return ErrInvalidCertificate
+		}
+		for i := 0; i < 9; i++ {
+			if bits.At(i) != 0 {
+				cert.KeyUsage |= 1 << uint(i)
+			}
+		}
+	case ext.Id.Equal(oidExtensionExtKeyUsage):
+		var usages cryptobyte.String
+		if !value.ReadASN1(&usages, cryptobyte_asn1.SEQUENCE) || !value.Empty() {
+			return ErrInvalidCertificate
+		}
+		for !usages.Empty() {
+			var oid asn1.ObjectIdentifier
+			if !usages.ReadASN1ObjectIdentifier(&oid) {
+				return ErrInvalidCertificate
+			}
+			cert.ExtKeyUsage = append(cert.ExtKeyUsage, oid)
+		}
+	case ext.Id.Equal(oidExtensionSubjectKeyId):
+		if !value.ReadASN1Bytes(&cert.SubjectKeyId, cryptobyte_asn1.OCTET_STRING) || !value.Empty() {
+			return ErrInvalidCertificate
+		}
+	case ext.Id.Equal(oidExtensionAuthorityKeyId):
+		var akid cryptobyte.String
+		if !value.ReadASN1(&akid, cryptobyte_asn1.SEQUENCE) || !value.Empty() {
+			return ErrInvalidCertificate
+		}
+		// keyIdentifier [0] is implicitly tagged, the issuer and serial fields are ignored
+		var keyID cryptobyte.String
+		var present bool
+		if !akid.ReadOptionalASN1(&keyID, &present, cryptobyte_asn1.Tag(0).ContextSpecific()) {
+			return ErrInvalidCertificate
+		}
+		if present {
+			cert.AuthorityKeyId = keyID
+		}
+	case ext.Id.Equal(oidExtensionSubjectAltName):
+		return parseSANs(ext.Value, &cert.DNSNames, &cert.EmailAddresses, &cert.IPAddresses, &cert.URIs)
+	default:
+		if ext.Critical {
+			cert.unhandledCritical = append(cert.unhandledCritical, ext.Id)
+		}
+	}
+	return nil
+}
+
+// GeneralName tags of the subject alternative names
+const (
+	nameTypeEmail = 1
+	nameTypeDNS   = 2
+	nameTypeURI   = 6
+	nameTypeIP    = 7
+)
+
+// parseSANs parses a GeneralNames sequence. Name types other than DNS names,
+// email addresses, URIs and IP addresses are ignored.
+func parseSANs(der []byte, dnsNames, emails *[]string, ips *[]net.IP, uris *[]*url.URL) error {
+	value := cryptobyte.String(der)
+	var names cryptobyte.String
+	if !value.ReadASN1(&names, cryptobyte_asn1.SEQUENCE) || !value.Empty() {
+		return ErrInvalidCertificate
+	}
+	for !names.Empty() {
+		var name cryptobyte.String
+		var tag cryptobyte_asn1.Tag
+		if !names.ReadAnyASN1(&name, &tag) {
+			return ErrInvalidCertificate
+		}
+		switch tag {
+		case cryptobyte_asn1.Tag(nameTypeEmail).ContextSpecific():
+			*emails = append(*emails, string(name))
+		case cryptobyte_asn1.Tag(nameTypeDNS).ContextSpecific():
+			*dnsNames = append(*dnsNames, string(name))
+		case cryptobyte_asn1.Tag(nameTypeURI).ContextSpecific():
+			uri, err := url.Parse(string(name))
+			if err != nil {
+				return ErrInvalidCertificate
+			}
+			*uris = append(*uris, uri)
+		case cryptobyte_asn1.Tag(nameTypeIP).ContextSpecific():
+			if len(name) != net.IPv4len && len(name) != net.IPv6len {
+				return ErrInvalidCertificate
+			}
+			*ips = append(*ips, net.IP(name))
+		}
+	}
+	return nil
+}
+
+// ParseCertificatesPEM parses every "CERTIFICATE" PEM block of data.
+func ParseCertificatesPEM(data []byte) ([]*Certificate, error) {
+	var certs []*Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != pemTypeCertificate {
+			continue
+		}
+		cert, err := ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, ErrInvalidPEM
+	}
+	return certs, nil
+}
+
+// CheckSignature verifies that signature is a Falcon signature of signed
+// under the public key of cert, with the algorithm identified by algo.
+func (cert *Certificate) CheckSignature(algo asn1.ObjectIdentifier, signed, signature []byte) error {
+	n, err := DegreeFromOID(algo)
+	if err != nil {
+		return err
+	}
+	if cert.PublicKey == nil || cert.PublicKey.n != n {
+		return ErrUnsupportedAlgorithm
+	}
+	if !cert.PublicKey.Verify(signed, signature) {
+		return ErrCertificateSignature
+	}
+	return nil
+}
+
+// CheckSignatureFrom verifies that the signature on cert is a valid signature
+// from parent, and that parent may issue certificates.
+func (cert *Certificate) CheckSignatureFrom(parent *Certificate) error {
+	if parent.Version == 3 && !parent.BasicConstraintsValid || parent.BasicConstraintsValid && !parent.IsCA {
+		return ErrNotCA
+	}
+	if parent.KeyUsage != 0 && parent.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return ErrIncompatibleUsage
+	}
+	return parent.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
+}
+
+// CertPool is a set of certificates indexed by subject.
+type CertPool struct {
+	certs     []*Certificate
+	bySubject map[string][]int
+}
+
+// NewCertPool returns an empty CertPool.
+func NewCertPool() *CertPool {
+	return &CertPool{bySubject: make(map[string][]int)}
+}
+
+// AddCert adds a certificate to the pool.
+func (pool *CertPool) AddCert(cert *Certificate) {
+	for _, i := range pool.bySubject[string(cert.RawSubject)] {
+		if bytes.Equal(pool.certs[i].Raw, cert.Raw) {
+			return
+		}
+	}
+	pool.bySubject[string(cert.RawSubject)] = append(pool.bySubject[string(cert.RawSubject)], len(pool.certs))
+	pool.certs = append(pool.certs, cert)
+}
+
+// AppendCertsFromPEM adds the certificates of the PEM blocks of data to the
+// pool and reports whether any certificate was added.
+func (pool *CertPool) AppendCertsFromPEM(data []byte) bool {
+	certs, err := ParseCertificatesPEM(data)
+	if err != nil {
+		return false
+	}
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return true
+}
+
+// contains reports whether cert is in the pool.
+func (pool *CertPool) contains(cert *Certificate) bool {
+	if pool == nil {
+		return false
+	}
+	for _, i := range pool.bySubject[string(cert.RawSubject)] {
+		if bytes.Equal(pool.certs[i].Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// issuers returns the certificates of the pool that may have issued cert,
+// by name and, when both are present, by key identifier.
+func (pool *CertPool) issuers(cert *Certificate) []*Certificate {
+	if pool == nil {
+		return nil
+	}
+	var candidates []*Certificate
+	for _, i := range pool.bySubject[string(cert.RawIssuer)] {
+		parent := pool.certs[i]
+		if len(cert.AuthorityKeyId) > 0 && len(parent.SubjectKeyId) > 0 &&
+			!bytes.Equal(cert.AuthorityKeyId, parent.SubjectKeyId) {
+			continue
+		}
+		candidates = append(candidates, parent)
+	}
+	return candidates
+}
+
+// VerifyOptions are the parameters of chain verification.
+type VerifyOptions struct {
+	// Roots are the trust anchors. Their own signatures are not checked.
+	Roots *CertPool
+	// Intermediates are certificates that may be used to build a chain.
+	Intermediates *CertPool
+	// CurrentTime is the verification time; the zero value selects time.Now().
+	CurrentTime time.Time
+	// KeyUsage, if not zero, lists key usage bits the leaf must allow when
+	// it has a key usage extension.
+	KeyUsage x509.KeyUsage
+}
+
+// Verify builds the chains from cert to a certificate of opts.Roots and
+// returns the valid ones, each starting with cert and ending with a root.
+// When no chain is valid, the error of the last rejected candidate is returned.
+func (cert *Certificate) Verify(opts VerifyOptions) ([][]*Certificate, error) {
+	if opts.CurrentTime.IsZero() {
+		opts.CurrentTime = time.Now()
+	}
+	if err := cert.isValid(opts.CurrentTime); err != nil {
+		return nil, err
+	}
+	if opts.KeyUsage != 0 && cert.KeyUsage != 0 && cert.KeyUsage&opts.KeyUsage != opts.KeyUsage {
+		return nil, ErrIncompatibleUsage
+	}
+	if opts.Roots.contains(cert) {
+		return [][]*Certificate{{cert}}, nil
+	}
+	var chains [][]*Certificate
+	err := cert.buildChains([]*Certificate{cert}, &opts, &chains)
+	if len(chains) > 0 {
+		return chains, nil
+	}
+	return nil, err
+}
+
+// isValid checks the validity period and the critical extensions of cert.
+func (cert *Certificate) isValid(now time.Time) error {
+	if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return ErrCertificateExpired
+	}
+	if len(cert.unhandledCritical) > 0 {
+		return ErrUnhandledCriticalExtension
+	}
+	return nil
+}
+
+// buildChains extends chain, whose last certificate is cert, with every
+// candidate issuer, and appends the chains that reach a root to chains.
+func (cert *Certificate) buildChains(chain []*Certificate, opts *VerifyOptions, chains *[][]*Certificate) error {
+	err := ErrUnknownAuthority
+	if len(chain) >= maxChainLength {
+		return err
+	}
+	for _, pool := range []*CertPool{opts.Roots, opts.Intermediates} {
+		isRoot := pool == opts.Roots
+	candidates:
+		for _, parent := range pool.issuers(cert) {
+			for _, c := range chain {
+				if bytes.Equal(c.Raw, parent.Raw) {
+					continue candidates
+				}
+			}
+			if e := parent.isValid(opts.CurrentTime); e != nil {
+				err = e
+				continue
+			}
+			if e := cert.CheckSignatureFrom(parent); e != nil {
+				err = e
+				continue
+			}
+			// chain[1:] are the intermediates below parent
+			if parent.MaxPathLen >= 0 && len(chain)-1 > parent.MaxPathLen {
+				err = ErrPathLength
+				continue
+			}
+			extended := append(chain[:len(chain):len(chain)], parent)
+			if isRoot {
+				*chains = append(*chains, extended)
+				continue
+			}
+			if e := parent.buildChains(extended, opts, chains); e != nil {
+				err = e
+			}
+		}
+	}
+	if len(*chains) > 0 {
+		return nil
+	}
+	return err
+}
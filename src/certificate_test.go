@@ -0,0 +1,201 @@
+package falcon
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testdata/pki holds a static three-level Falcon PKI: a Falcon-1024 root
+// (pathlen 1), a Falcon-512 intermediate (pathlen 0) and a Falcon-512 leaf
+// for leaf.example.com valid from 2026-06-01 to 2027-06-01. server.pem is a
+// second leaf with subject alternative names, request.pem a certificate
+// request and crl.pem a revocation list of the intermediate CA.
+
+var pkiTime = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+func loadCertificate(t *testing.T, name string) *Certificate {
+	certs, err := ParseCertificatesPEM(mustReadFile(t, "testdata/pki/"+name+".pem"))
+	if err != nil {
+		t.Fatalf("%s: ParseCertificatesPEM returned %v", name, err)
+	}
+	return certs[0]
+}
+
+func loadPKI(t *testing.T) (root, intermediate, leaf *Certificate) {
+	return loadCertificate(t, "root"), loadCertificate(t, "intermediate"), loadCertificate(t, "leaf")
+}
+
+func pkiOptions(root, intermediate *Certificate) VerifyOptions {
+	opts := VerifyOptions{Roots: NewCertPool(), Intermediates: NewCertPool(), CurrentTime: pkiTime}
+	opts.Roots.AddCert(root)
+	opts.Intermediates.AddCert(intermediate)
+	return opts
+}
+
+func TestParseCertificate(t *testing.T) {
+	root, intermediate, leaf := loadPKI(t)
+
+	if leaf.Version != 3 || leaf.SerialNumber.Int64() != 3 {
+		t.Errorf("leaf version %d serial %v", leaf.Version, leaf.SerialNumber)
+	}
+	if leaf.Subject.CommonName != "leaf.example.com" || leaf.Issuer.CommonName != "Falcon Test Intermediate CA" {
+		t.Errorf("leaf subject %q issuer %q", leaf.Subject.CommonName, leaf.Issuer.CommonName)
+	}
+	if !leaf.SignatureAlgorithm.Equal(OIDFalcon512) || !intermediate.SignatureAlgorithm.Equal(OIDFalcon1024) {
+		t.Errorf("unexpected signature algorithms %v, %v", leaf.SignatureAlgorithm, intermediate.SignatureAlgorithm)
+	}
+	if root.PublicKey == nil || root.PublicKey.Degree() != 1024 || leaf.PublicKey == nil || leaf.PublicKey.Degree() != 512 {
+		t.Errorf("subject public keys were not decoded")
+	}
+	if !root.IsCA || root.MaxPathLen != 1 || !intermediate.IsCA || intermediate.MaxPathLen != 0 {
+		t.Errorf("CA basic constraints not parsed")
+	}
+	if leaf.IsCA || !leaf.BasicConstraintsValid || leaf.MaxPathLen != -1 {
+		t.Errorf("leaf basic constraints not parsed")
+	}
+	if leaf.KeyUsage != x509.KeyUsageDigitalSignature || root.KeyUsage != x509.KeyUsageCertSign|x509.KeyUsageCRLSign {
+		t.Errorf("key usages %v, %v", leaf.KeyUsage, root.KeyUsage)
+	}
+	if string(leaf.AuthorityKeyId) != string(intermediate.SubjectKeyId) || len(leaf.AuthorityKeyId) != 20 {
+		t.Errorf("key identifiers do not chain")
+	}
+
+	pub, err := ParsePublicKeyPEM(mustReadFile(t, "testdata/pki/leaf_key.pem"))
+	if err == nil || pub != nil {
+		t.Errorf("a private key PEM was parsed as a public key")
+	}
+	priv, err := ParsePrivateKeyPEM(mustReadFile(t, "testdata/pki/leaf_key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalInt16(priv.GetPublicKey().h, leaf.PublicKey.h) {
+		t.Errorf("leaf_key.pem does not match the leaf certificate")
+	}
+
+	for _, n := range []int{0, 10, len(leaf.Raw) - 1} {
+		if _, err := ParseCertificate(leaf.Raw[:n]); err == nil {
+			t.Errorf("truncated certificate of %d bytes was parsed", n)
+		}
+	}
+}
+
+func TestVerifyChain(t *testing.T) {
+	root, intermediate, leaf := loadPKI(t)
+	chains, err := leaf.Verify(pkiOptions(root, intermediate))
+	if err != nil {
+		t.Fatalf("Verify returned %v", err)
+	}
+	if len(chains) != 1 || len(chains[0]) != 3 || chains[0][0] != leaf || chains[0][1] != intermediate || chains[0][2] != root {
+		t.Errorf("unexpected chains %v", chains)
+	}
+
+	opts := pkiOptions(root, intermediate)
+	opts.KeyUsage = x509.KeyUsageDigitalSignature
+	if _, err := leaf.Verify(opts); err != nil {
+		t.Errorf("Verify with digitalSignature usage returned %v", err)
+	}
+	chains, err = root.Verify(pkiOptions(root, intermediate))
+	if err != nil || len(chains) != 1 || len(chains[0]) != 1 {
+		t.Errorf("a root does not verify as its own chain: %v", err)
+	}
+}
+
+func TestVerifyChainErrors(t *testing.T) {
+	root, intermediate, leaf := loadPKI(t)
+	copyCert := func(c *Certificate) *Certificate {
+		cc := *c
+		return &cc
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(opts *VerifyOptions) *Certificate
+		want   error
+	}{
+		{"leaf not yet valid", func(opts *VerifyOptions) *Certificate {
+			opts.CurrentTime = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+			return leaf
+		}, ErrCertificateExpired},
+		{"leaf expired", func(opts *VerifyOptions) *Certificate {
+			opts.CurrentTime = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+			return leaf
+		}, ErrCertificateExpired},
+		{"no intermediate", func(opts *VerifyOptions) *Certificate {
+			opts.Intermediates = nil
+			return leaf
+		}, ErrUnknownAuthority},
+		{"unknown root", func(opts *VerifyOptions) *Certificate {
+			opts.Roots = NewCertPool()
+			return leaf
+		}, ErrUnknownAuthority},
+		{"incompatible usage", func(opts *VerifyOptions) *Certificate {
+			opts.KeyUsage = x509.KeyUsageKeyEncipherment
+			return leaf
+		}, ErrIncompatibleUsage},
+		{"issuer is not a CA", func(opts *VerifyOptions) *Certificate {
+			notCA := copyCert(intermediate)
+			notCA.IsCA = false
+			opts.Intermediates = NewCertPool()
+			opts.Intermediates.AddCert(notCA)
+			return leaf
+		}, ErrNotCA},
+		{"issuer cannot sign certificates", func(opts *VerifyOptions) *Certificate {
+			noCertSign := copyCert(intermediate)
+			noCertSign.KeyUsage = x509.KeyUsageDigitalSignature
+			opts.Intermediates = NewCertPool()
+			opts.Intermediates.AddCert(noCertSign)
+			return leaf
+		}, ErrIncompatibleUsage},
+		{"path length", func(opts *VerifyOptions) *Certificate {
+			constrained := copyCert(root)
+			constrained.MaxPathLen = 0
+			opts.Roots = NewCertPool()
+			opts.Roots.AddCert(constrained)
+			return leaf
+		}, ErrPathLength},
+		{"bad signature", func(opts *VerifyOptions) *Certificate {
+			forged := copyCert(leaf)
+			forged.Signature = append([]byte{}, leaf.Signature...)
+			forged.Signature[60] ^= 0x40
+			return forged
+		}, ErrCertificateSignature},
+		{"signature by another key", func(opts *VerifyOptions) *Certificate {
+			forged := copyCert(leaf)
+			forged.RawTBSCertificate = intermediate.RawTBSCertificate
+			return forged
+		}, ErrCertificateSignature},
+		{"unhandled critical extension", func(opts *VerifyOptions) *Certificate {
+			critical := copyCert(leaf)
+			critical.unhandledCritical = []asn1.ObjectIdentifier{{1, 2, 3}}
+			return critical
+		}, ErrUnhandledCriticalExtension},
+	}
+	for _, tc := range testCases {
+		opts := pkiOptions(root, intermediate)
+		cert := tc.modify(&opts)
+		if _, err := cert.Verify(opts); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Verify returned %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestCertPool(t *testing.T) {
+	root, intermediate, leaf := loadPKI(t)
+	pool := NewCertPool()
+	if !pool.AppendCertsFromPEM(append(mustReadFile(t, "testdata/pki/root.pem"), mustReadFile(t, "testdata/pki/intermediate.pem")...)) {
+		t.Fatal("AppendCertsFromPEM returned false")
+	}
+	pool.AddCert(root)
+	if len(pool.certs) != 2 {
+		t.Errorf("pool holds %d certificates, want 2", len(pool.certs))
+	}
+	if issuers := pool.issuers(leaf); len(issuers) != 1 || issuers[0].SerialNumber.Cmp(intermediate.SerialNumber) != 0 {
+		t.Errorf("issuers(leaf) = %v", issuers)
+	}
+	if pool.AppendCertsFromPEM([]byte("no certificate")) {
+		t.Errorf("AppendCertsFromPEM accepted data without certificates")
+	}
+}
@@ -0,0 +1,146 @@
+// Command falcon-agent holds Falcon private keys and signs with them for the
+// processes of the same user, over a Unix domain socket:
+//
+//	falcon-agent [-socket path] [-max-signatures n] [-allow-uid uid,...] key_file ...
+//
+// Key files are PKCS #8 PEM or binary private keys. Every key is expanded
+// once at startup. With -max-signatures, each key makes at most n
+// signatures; with -allow-uid, the agent serves the given users instead of
+// the user running it. The agent prints the shell command setting
+// FALCON_AGENT_SOCK, which agent.Dial clients connect to, and runs until it
+// is interrupted.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/agent"
+)
+
+const (
+	exitUsage   = 2
+	exitFailure = 3
+)
+
+func main() {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, stop))
+}
+
+func run(args []string, stdout, stderr io.Writer, stop <-chan os.Signal) int {
+	fs := flag.NewFlagSet("falcon-agent", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	socket := fs.String("socket", defaultSocket(), "path of the socket")
+	maxSignatures := fs.Uint64("max-signatures", 0, "number of signatures each key may make, or 0 for no limit")
+	allowUIDs := fs.String("allow-uid", "", "comma-separated user IDs served by the agent")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: falcon-agent [-socket path] [-max-signatures n] [-allow-uid uid,...] key_file ...")
+		return exitUsage
+	}
+	a := agent.New()
+	uids, err := parseUIDs(*allowUIDs)
+	if err != nil {
+		fmt.Fprintln(stderr, "falcon-agent:", err)
+		return exitUsage
+	}
+	a.AllowedUIDs = uids
+	for _, name := range fs.Args() {
+		privKey, err := loadKey(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "falcon-agent: %s: %v\n", name, err)
+			return exitFailure
+		}
+		fingerprint, err := a.Add(privKey, filepath.Base(name), agent.Constraints{MaxSignatures: *maxSignatures})
+		if err != nil {
+			fmt.Fprintf(stderr, "falcon-agent: %s: %v\n", name, err)
+			return exitFailure
+		}
+		fmt.Fprintf(stderr, "Loaded Falcon-%d key %s from %s\n", privKey.Degree(), fingerprint, name)
+	}
+
+	if err := serve(a, *socket, stdout, stop); err != nil {
+		fmt.Fprintln(stderr, "falcon-agent:", err)
+		return exitFailure
+	}
+	return 0
+}
+
+// serve listens on the socket, which only the user may open, until a
+// signal is received, and then removes the socket.
+func serve(a *agent.Agent, socket string, stdout io.Writer, stop <-chan os.Signal) error {
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: socket, Net: "unix"})
+	if err != nil {
+		return err
+	}
+	l.SetUnlinkOnClose(true)
+	if err := os.Chmod(socket, 0600); err != nil {
+		l.Close()
+		return err
+	}
+	fmt.Fprintf(stdout, "%s=%s; export %s;\n", agent.SocketEnv, socket, agent.SocketEnv)
+
+	done := make(chan error, 1)
+	go func() { done <- a.Serve(l) }()
+	select {
+	case <-stop:
+		l.Close()
+		return <-done
+	case err := <-done:
+		l.Close()
+		return err
+	}
+}
+
+// defaultSocket returns the socket path in the runtime directory of the user.
+func defaultSocket() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "falcon-agent.sock")
+}
+
+func parseUIDs(list string) ([]uint32, error) {
+	var uids []uint32
+	if list == "" {
+		return nil, nil
+	}
+	for _, field := range strings.Split(list, ",") {
+		uid, err := strconv.ParseUint(field, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q", field)
+		}
+		uids = append(uids, uint32(uid))
+	}
+	return uids, nil
+}
+
+// loadKey reads a PKCS #8 PEM or binary private key.
+func loadKey(name string) (*falcon.PrivateKey, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if privKey, err := falcon.ParsePrivateKeyPEM(data); err == nil {
+		return privKey, nil
+	}
+	if privKey, err := falcon.ParsePrivateKey(data); err == nil {
+		return privKey, nil
+	}
+	return nil, errors.New("not a Falcon private key")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Indra4091/falconGo/src/agent"
+)
+
+func TestRun(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	var stdout, stderr bytes.Buffer
+	stop := make(chan os.Signal)
+	done := make(chan int)
+	go func() {
+		done <- run([]string{"-socket", socket, "-max-signatures", "1", "../../testdata/falcon512_priv.pem"}, &stdout, &stderr, stop)
+	}()
+
+	var client *agent.Client
+	var err error
+	for i := 0; i < 100; i++ {
+		if client, err = agent.Dial(socket); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket: %v, %v", info, err)
+	}
+
+	signers, err := client.Signers()
+	if err != nil || len(signers) != 1 {
+		t.Fatalf("Signers() = %v, %v", signers, err)
+	}
+	if !strings.Contains(stderr.String(), signers[0].Fingerprint()) {
+		t.Errorf("the loaded keys are not listed: %q", stderr.String())
+	}
+	message := []byte("message")
+	sig, err := signers[0].Sign(nil, message, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signers[0].PublicKey().VerifyFNDSA(message, sig, nil); err != nil {
+		t.Error(err)
+	}
+	if _, err := signers[0].Sign(nil, message, nil); err != agent.ErrUsageLimit {
+		t.Errorf("second signature returned %v, want %v", err, agent.ErrUsageLimit)
+	}
+
+	stop <- os.Interrupt
+	if code := <-done; code != 0 {
+		t.Errorf("run exited with %d: %s", code, stderr.String())
+	}
+	if stdout.String() != agent.SocketEnv+"="+socket+"; export "+agent.SocketEnv+";\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("the socket was not removed: %v", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(nil, &stdout, &stderr, nil); code != exitUsage {
+		t.Errorf("run without keys exited with %d", code)
+	}
+	if code := run([]string{"-allow-uid", "root", "key"}, &stdout, &stderr, nil); code != exitUsage {
+		t.Errorf("run with an invalid user ID exited with %d", code)
+	}
+	if code := run([]string{"../../testdata/falcon512_pub.pem"}, &stdout, &stderr, nil); code != exitFailure {
+		t.Errorf("run with a public key exited with %d", code)
+	}
+}
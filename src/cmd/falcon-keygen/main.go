@@ -0,0 +1,458 @@
+// Command falcon-keygen generates Falcon SSH keys and makes and checks SSHSIG
+// signatures with the options of ssh-keygen, so that it can be used as
+// git's gpg.ssh.program:
+//
+//	falcon-keygen -t falcon512 [-C comment] [-f key_file]
+//	falcon-keygen -Y sign -f key_file [-U] -n namespace [-O hashalg=sha256] [file ...]
+//	falcon-keygen -Y verify -f allowed_signers -I principal -n namespace -s signature_file [-O verify-time=time]
+//	falcon-keygen -Y find-principals -f allowed_signers -s signature_file [-O verify-time=time]
+//	falcon-keygen -Y check-novalidate -n namespace -s signature_file
+//
+// Messages are read from the standard input, except for sign which signs
+// each file into file.sig, or the standard input to the standard output. The
+// key file of sign may be a private key, or a public key with the private key
+// next to it without the .pub suffix. With -U, or for a public key without
+// its private key, as git passes for a literal signing key, the private key
+// is held by the SSH agent of $SSH_AUTH_SOCK. Other key types of OpenSSH key
+// files are supported for signing and verification.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/falconssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const (
+	exitUsage   = 1
+	exitFailure = 255
+)
+
+var errUsage = errors.New("usage: falcon-keygen -t falcon512|falcon1024 [-C comment] [-f key_file]\n" +
+	"       falcon-keygen -Y sign -f key_file [-U] -n namespace [file ...]\n" +
+	"       falcon-keygen -Y verify -f allowed_signers -I principal -n namespace -s signature_file\n" +
+	"       falcon-keygen -Y find-principals -f allowed_signers -s signature_file\n" +
+	"       falcon-keygen -Y check-novalidate -n namespace -s signature_file")
+
+// options are the parsed command line options.
+type options struct {
+	operation string
+	namespace string
+	file      string
+	principal string
+	signature string
+	keyType   string
+	comment   string
+	useAgent  bool
+
+	hashAlgorithm string
+	verifyTime    time.Time
+	printPubkey   bool
+
+	args []string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseOptions(args)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	switch opts.operation {
+	case "":
+		if opts.keyType == "" {
+			err = errUsage
+			break
+		}
+		err = generate(opts, stdout)
+	case "sign":
+		err = sign(opts, stdin, stdout, stderr)
+	case "verify":
+		err = verify(opts, stdin, stdout)
+	case "find-principals":
+		err = findPrincipals(opts, stdout)
+	case "check-novalidate":
+		err = checkNoValidate(opts, stdin, stdout)
+	default:
+		err = fmt.Errorf("unsupported operation %q", opts.operation)
+	}
+	if err == errUsage {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitFailure
+	}
+	return 0
+}
+
+// parseOptions parses args as getopt with the ssh-keygen option letters: an
+// option argument may follow its letter or be the next argument, and options
+// stop at the first operand.
+func parseOptions(args []string) (*options, error) {
+	opts := &options{verifyTime: time.Now()}
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			args = args[1:]
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			break
+		}
+		args = args[1:]
+		for i := 1; i < len(arg); i++ {
+			c := arg[i]
+			if c == 'U' {
+				opts.useAgent = true
+				continue
+			}
+			if c == 'q' {
+				continue
+			}
+			value := arg[i+1:]
+			if value == "" {
+				if len(args) == 0 {
+					return nil, errUsage
+				}
+				value, args = args[0], args[1:]
+			}
+			if err := opts.set(c, value); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	opts.args = args
+	return opts, nil
+}
+
+func (opts *options) set(c byte, value string) error {
+	switch c {
+	case 'Y':
+		opts.operation = value
+	case 'n':
+		opts.namespace = value
+	case 'f':
+		opts.file = value
+	case 'I':
+		opts.principal = value
+	case 's':
+		opts.signature = value
+	case 't':
+		opts.keyType = value
+	case 'C':
+		opts.comment = value
+	case 'O':
+		return opts.setOption(value)
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func (opts *options) setOption(option string) error {
+	name, value, _ := strings.Cut(option, "=")
+	switch name {
+	case "hashalg":
+		opts.hashAlgorithm = value
+	case "print-pubkey":
+		opts.printPubkey = true
+	case "verify-time":
+		t, err := parseTime(value)
+		if err != nil {
+			return err
+		}
+		opts.verifyTime = t
+	default:
+		return fmt.Errorf("invalid option %q", option)
+	}
+	return nil
+}
+
+// parseTime decodes a YYYYMMDD[HHMM[SS]][Z] time, as ssh-keygen.
+func parseTime(value string) (time.Time, error) {
+	loc := time.Local
+	if strings.HasSuffix(value, "Z") {
+		loc, value = time.UTC, value[:len(value)-1]
+	}
+	for _, layout := range []string{"20060102", "200601021504", "20060102150405"} {
+		if len(layout) == len(value) {
+			return time.ParseInLocation(layout, value, loc)
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+func generate(opts *options, stdout io.Writer) error {
+	var n uint16
+	switch opts.keyType {
+	case "falcon512", falconssh.KeyAlgoFalcon512:
+		n = 512
+	case "falcon1024", falconssh.KeyAlgoFalcon1024:
+		n = 1024
+	default:
+		return fmt.Errorf("unsupported key type %q", opts.keyType)
+	}
+	file := opts.file
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		file = filepath.Join(home, ".ssh", fmt.Sprintf("id_falcon%d", n))
+	}
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("%s already exists", file)
+	}
+	privKey, _, err := falcon.NewKeyPair(n)
+	if err != nil {
+		return err
+	}
+	encoded, err := falconssh.MarshalPrivateKey(privKey, opts.comment)
+	if err != nil {
+		return err
+	}
+	signer, err := falconssh.NewSigner(privKey)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, encoded, 0600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file+".pub", authorizedKey(signer.PublicKey(), opts.comment), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Your identification has been saved in %s\n", file)
+	fmt.Fprintf(stdout, "Your public key has been saved in %s.pub\n", file)
+	fmt.Fprintf(stdout, "The key fingerprint is:\n%s %s\n", ssh.FingerprintSHA256(signer.PublicKey()), opts.comment)
+	return nil
+}
+
+func authorizedKey(pub ssh.PublicKey, comment string) []byte {
+	line := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(pub), []byte("\n"))
+	if comment != "" {
+		line = append(line, ' ')
+		line = append(line, comment...)
+	}
+	return append(line, '\n')
+}
+
+// loadSigner reads a private key file, or the private key next to a public
+// key file. As ssh-keygen, it signs with the SSH agent if useAgent is set or
+// if the file is a public key without a private key next to it.
+func loadSigner(file string, useAgent bool) (ssh.Signer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if useAgent {
+		return agentSigner(file, data)
+	}
+	if !bytes.Contains(data, []byte("PRIVATE KEY")) {
+		private := strings.TrimSuffix(file, ".pub")
+		if _, err := os.Stat(private); private == file || errors.Is(err, fs.ErrNotExist) {
+			return agentSigner(file, data)
+		}
+		if data, err = os.ReadFile(private); err != nil {
+			return nil, err
+		}
+	}
+	if signer, err := falconssh.ParsePrivateKey(data); err == nil {
+		return signer, nil
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load private key %s: %w", file, err)
+	}
+	return signer, nil
+}
+
+// sshAgentSigner signs with a key held by an SSH agent.
+type sshAgentSigner struct {
+	pub    ssh.PublicKey
+	client agent.ExtendedAgent
+}
+
+func (s *sshAgentSigner) PublicKey() ssh.PublicKey {
+	return s.pub
+}
+
+func (s *sshAgentSigner) Sign(random io.Reader, data []byte) (*ssh.Signature, error) {
+	var flags agent.SignatureFlags
+	if s.pub.Type() == ssh.KeyAlgoRSA {
+		// RSA signatures use SHA-512, as in ssh-keygen
+		flags = agent.SignatureFlagRsaSha512
+	}
+	return s.client.SignWithFlags(s.pub, data, flags)
+}
+
+// agentSigner returns a signer for the public key read from file, using the
+// SSH agent of $SSH_AUTH_SOCK, which must hold the private key.
+func agentSigner(file string, data []byte) (ssh.Signer, error) {
+	var pub ssh.PublicKey
+	if falconPub, _, err := falconssh.ParseAuthorizedKey(data); err == nil {
+		pub = falconPub
+	} else if pub, _, _, _, err = ssh.ParseAuthorizedKey(data); err != nil {
+		return nil, fmt.Errorf("cannot load public key %s: %w", file, err)
+	}
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, errors.New("SSH_AUTH_SOCK is not set")
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to the SSH agent: %w", err)
+	}
+	return &sshAgentSigner{pub: pub, client: agent.NewClient(conn)}, nil
+}
+
+func sign(opts *options, stdin io.Reader, stdout, stderr io.Writer) error {
+	if opts.file == "" || opts.namespace == "" {
+		return errUsage
+	}
+	signer, err := loadSigner(opts.file, opts.useAgent)
+	if err != nil {
+		return err
+	}
+	if len(opts.args) == 0 || (len(opts.args) == 1 && opts.args[0] == "-") {
+		sig, err := falconssh.SignSSHSig(signer, stdin, opts.namespace, opts.hashAlgorithm)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(sig.MarshalArmored())
+		return err
+	}
+	for _, file := range opts.args {
+		sig, err := signFile(signer, file, opts)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(file+".sig", sig.MarshalArmored(), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "Write signature to %s.sig\n", file)
+	}
+	return nil
+}
+
+func signFile(signer ssh.Signer, file string, opts *options) (*falconssh.SSHSig, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return falconssh.SignSSHSig(signer, f, opts.namespace, opts.hashAlgorithm)
+}
+
+func readSignature(opts *options) (*falconssh.SSHSig, error) {
+	if opts.signature == "" {
+		return nil, errUsage
+	}
+	data, err := os.ReadFile(opts.signature)
+	if err != nil {
+		return nil, err
+	}
+	return falconssh.ParseArmoredSSHSig(data)
+}
+
+func readAllowedSigners(opts *options) ([]*falconssh.AllowedSigner, error) {
+	if opts.file == "" {
+		return nil, errUsage
+	}
+	data, err := os.ReadFile(opts.file)
+	if err != nil {
+		return nil, err
+	}
+	return falconssh.ParseAllowedSigners(data)
+}
+
+// keyTypeName returns the key type name printed by ssh-keygen, such as
+// ED25519 or FALCON512.
+func keyTypeName(pub ssh.PublicKey) string {
+	t := pub.Type()
+	switch {
+	case strings.HasPrefix(t, "ecdsa-"):
+		return "ECDSA"
+	case strings.HasPrefix(t, "sk-ecdsa-"):
+		return "ECDSA-SK"
+	case strings.HasPrefix(t, "sk-ssh-ed25519"):
+		return "ED25519-SK"
+	}
+	return strings.ToUpper(strings.TrimPrefix(t, "ssh-"))
+}
+
+func verify(opts *options, stdin io.Reader, stdout io.Writer) error {
+	if opts.principal == "" || opts.namespace == "" {
+		return errUsage
+	}
+	signers, err := readAllowedSigners(opts)
+	if err != nil {
+		return err
+	}
+	sig, err := readSignature(opts)
+	if err != nil {
+		return err
+	}
+	if err := falconssh.VerifyAllowed(signers, sig, stdin, opts.principal, opts.namespace, opts.verifyTime); err != nil {
+		return fmt.Errorf("could not verify signature: %w", err)
+	}
+	fmt.Fprintf(stdout, "Good %q signature for %s with %s key %s\n",
+		opts.namespace, opts.principal, keyTypeName(sig.PublicKey), ssh.FingerprintSHA256(sig.PublicKey))
+	if opts.printPubkey {
+		stdout.Write(ssh.MarshalAuthorizedKey(sig.PublicKey))
+	}
+	return nil
+}
+
+func findPrincipals(opts *options, stdout io.Writer) error {
+	signers, err := readAllowedSigners(opts)
+	if err != nil {
+		return err
+	}
+	sig, err := readSignature(opts)
+	if err != nil {
+		return err
+	}
+	principals := falconssh.FindPrincipals(signers, sig, opts.verifyTime)
+	if len(principals) == 0 {
+		return errors.New("no principal matched")
+	}
+	for _, principal := range principals {
+		fmt.Fprintln(stdout, principal)
+	}
+	return nil
+}
+
+func checkNoValidate(opts *options, stdin io.Reader, stdout io.Writer) error {
+	if opts.namespace == "" {
+		return errUsage
+	}
+	sig, err := readSignature(opts)
+	if err != nil {
+		return err
+	}
+	if err := sig.Verify(stdin, opts.namespace); err != nil {
+		return fmt.Errorf("could not verify signature: %w", err)
+	}
+	fmt.Fprintf(stdout, "Good %q signature with %s key %s\n",
+		opts.namespace, keyTypeName(sig.PublicKey), ssh.FingerprintSHA256(sig.PublicKey))
+	return nil
+}
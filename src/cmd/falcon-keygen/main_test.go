@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Indra4091/falconGo/src/falconssh"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+const testdata = "../../falconssh/testdata"
+
+// programEnv makes the test binary run as falcon-keygen, for git.
+const programEnv = "FALCON_KEYGEN_TEST_PROGRAM"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(programEnv) == "1" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	message := filepath.Join(dir, "message")
+	if err := os.WriteFile(message, []byte("commit contents\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	allowed := filepath.Join(testdata, "allowed_signers")
+
+	// git passes the public key file and the buffer file to sign
+	key := copyKey(t, dir)
+	if code, _, stderr := runCommand(t, "", "-Y", "sign", "-n", "git", "-f", key+".pub", message); code != 0 {
+		t.Fatalf("sign exited with %d: %s", code, stderr)
+	}
+	sig := message + ".sig"
+
+	code, stdout, _ := runCommand(t, "", "-Y", "find-principals", "-f", allowed, "-s", sig, "-Overify-time=20261001Z")
+	if code != 0 || stdout != "falcon512@example.com,*@falcon.example\n" {
+		t.Errorf("find-principals = %d, %q", code, stdout)
+	}
+
+	code, stdout, _ = runCommand(t, "commit contents\n",
+		"-Y", "verify", "-n", "git", "-f", allowed, "-I", "falcon512@example.com", "-s", sig, "-Overify-time=20261001Z")
+	if code != 0 || !strings.HasPrefix(stdout, `Good "git" signature for falcon512@example.com with FALCON512 key SHA256:`) {
+		t.Errorf("verify = %d, %q", code, stdout)
+	}
+	code, _, _ = runCommand(t, "other contents\n",
+		"-Y", "verify", "-n", "git", "-f", allowed, "-I", "falcon512@example.com", "-s", sig)
+	if code != exitFailure {
+		t.Errorf("verify of another message exited with %d", code)
+	}
+	code, _, _ = runCommand(t, "commit contents\n",
+		"-Y", "verify", "-n", "git", "-f", allowed, "-I", "mallory@example.com", "-s", sig)
+	if code != exitFailure {
+		t.Errorf("verify for another principal exited with %d", code)
+	}
+
+	code, stdout, _ = runCommand(t, "commit contents\n", "-Y", "check-novalidate", "-n", "git", "-s", sig)
+	if code != 0 || !strings.HasPrefix(stdout, `Good "git" signature with FALCON512 key SHA256:`) {
+		t.Errorf("check-novalidate = %d, %q", code, stdout)
+	}
+	code, _, _ = runCommand(t, "commit contents\n", "-Y", "check-novalidate", "-n", "file", "-s", sig)
+	if code != exitFailure {
+		t.Errorf("check-novalidate for another namespace exited with %d", code)
+	}
+
+	// Signing the standard input writes the signature to the standard output
+	code, stdout, _ = runCommand(t, "commit contents\n", "-Y", "sign", "-n", "git", "-f", key)
+	if code != 0 || !strings.HasPrefix(stdout, "-----BEGIN SSH SIGNATURE-----\n") {
+		t.Errorf("sign of the standard input = %d, %q", code, stdout)
+	}
+}
+
+// copyKey copies the Falcon-512 test key pair to dir and returns the path of
+// the private key.
+func copyKey(t *testing.T, dir string) string {
+	t.Helper()
+	for _, name := range []string{"id_falcon512", "id_falcon512.pub"} {
+		data, err := os.ReadFile(filepath.Join(testdata, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "id_falcon512")
+}
+
+// testAgent is an SSH agent holding a single key; only Sign is implemented.
+type testAgent struct {
+	agent.Agent
+	signer ssh.Signer
+}
+
+func (a *testAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	if !bytes.Equal(key.Marshal(), a.signer.PublicKey().Marshal()) {
+		return nil, falconssh.ErrInvalidKey
+	}
+	return a.signer.Sign(nil, data)
+}
+
+// startAgent serves an SSH agent holding the test key and returns its socket.
+func startAgent(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(testdata, "id_falcon512"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := falconssh.ParsePrivateKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "agent.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("no Unix domain socket: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if err := agent.ServeAgent(&testAgent{signer: signer}, conn); err != nil && err != io.EOF {
+					t.Logf("agent: %v", err)
+				}
+			}()
+		}
+	}()
+	return socket
+}
+
+func TestSignAgent(t *testing.T) {
+	dir := t.TempDir()
+	key := copyKey(t, dir)
+	message := filepath.Join(dir, "message")
+	if err := os.WriteFile(message, []byte("commit contents\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SSH_AUTH_SOCK", startAgent(t, dir))
+	// The private key is not read: move it away
+	if err := os.Rename(key, key+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	// With -U, and for a public key alone as ssh-keygen
+	for _, args := range [][]string{{"-U", message}, {message}} {
+		args = append([]string{"-Y", "sign", "-n", "git", "-f", key + ".pub"}, args...)
+		if code, _, stderr := runCommand(t, "", args...); code != 0 {
+			t.Fatalf("sign %q exited with %d: %s", args, code, stderr)
+		}
+		code, stdout, _ := runCommand(t, "commit contents\n", "-Y", "verify", "-n", "git",
+			"-f", filepath.Join(testdata, "allowed_signers"), "-I", "falcon512@example.com", "-s", message+".sig")
+		if code != 0 || !strings.HasPrefix(stdout, `Good "git" signature for falcon512@example.com`) {
+			t.Errorf("verify of the agent signature of %q = %d, %q", args, code, stdout)
+		}
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", filepath.Join(dir, "missing.sock"))
+	if code, _, _ := runCommand(t, "", "-Y", "sign", "-n", "git", "-f", key+".pub", "-U", message); code != exitFailure {
+		t.Errorf("sign without an agent exited with %d", code)
+	}
+}
+
+// TestGit signs and verifies commits in a throwaway repository with git
+// running the test binary as its gpg.ssh.program.
+func TestGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	program, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allowed, err := filepath.Abs(filepath.Join(testdata, "allowed_signers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	key := copyKey(t, dir)
+	repo := filepath.Join(dir, "repo")
+	env := append(os.Environ(), programEnv+"=1", "SSH_AUTH_SOCK="+startAgent(t, dir),
+		"HOME="+dir, "GIT_CONFIG_NOSYSTEM=1", "GIT_CONFIG_GLOBAL=/dev/null")
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = env
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	for _, kv := range [][2]string{
+		{"user.name", "Falcon Test"},
+		{"user.email", "falcon512@example.com"},
+		{"gpg.format", "ssh"},
+		{"gpg.ssh.program", program},
+		{"gpg.ssh.allowedSignersFile", allowed},
+		{"user.signingKey", key + ".pub"},
+	} {
+		git("config", kv[0], kv[1])
+	}
+	if err := os.WriteFile(filepath.Join(repo, "file"), []byte("contents\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", "file")
+	git("commit", "-q", "-S", "-m", "signed with the key file")
+	if out := git("verify-commit", "HEAD"); !strings.Contains(out, `Good "git" signature for falcon512@example.com`) {
+		t.Errorf("verify-commit of the key file signature: %s", out)
+	}
+
+	// git signs with the agent when the signing key is the public key itself
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	git("config", "user.signingKey", strings.TrimSpace(string(pub)))
+	git("commit", "-q", "-S", "--allow-empty", "-m", "signed with the agent")
+	if out := git("verify-commit", "HEAD"); !strings.Contains(out, `Good "git" signature for falcon512@example.com`) {
+		t.Errorf("verify-commit of the agent signature: %s", out)
+	}
+	if out := git("log", "--format=%G? %s"); out != "G signed with the agent\nG signed with the key file\n" {
+		t.Errorf("git log signature status:\n%s", out)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "id")
+	if code, _, stderr := runCommand(t, "", "-t", "falcon512", "-C", "dev@example.com", "-f", key); code != 0 {
+		t.Fatalf("key generation exited with %d: %s", code, stderr)
+	}
+	pub, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(pub), "ssh-falcon512 ") || !strings.HasSuffix(string(pub), " dev@example.com\n") {
+		t.Errorf("unexpected public key file %.40q", pub)
+	}
+	if code, _, _ := runCommand(t, "", "-t", "falcon512", "-f", key); code != exitFailure {
+		t.Errorf("key generation over an existing file exited with %d", code)
+	}
+	if code, _, _ := runCommand(t, "", "-t", "rsa", "-f", key+"2"); code != exitFailure {
+		t.Errorf("key generation of an RSA key exited with %d", code)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := parseOptions([]string{"-Y", "sign", "-ngit", "-U", "-Ohashalg=sha256", "-f", "key", "file", "-n", "x"})
+	if err != nil {
+		t.Fatalf("parseOptions returned %v", err)
+	}
+	if opts.operation != "sign" || opts.namespace != "git" || !opts.useAgent || opts.hashAlgorithm != "sha256" || opts.file != "key" {
+		t.Errorf("unexpected options %+v", opts)
+	}
+	if len(opts.args) != 3 || opts.args[0] != "file" {
+		t.Errorf("operands = %q", opts.args)
+	}
+	for _, args := range [][]string{{"-n"}, {"-x", "y"}, {"-Ounknown"}, {"-Overify-time=2026"}} {
+		if _, err := parseOptions(args); err == nil {
+			t.Errorf("parseOptions(%q) succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Command falcon-verifyd is an HTTP service verifying Falcon signatures for
+// services that cannot link the library:
+//
+//	falcon-verifyd [-addr :8080] [-keys keys.json] [-max-body bytes] [-max-batch n]
+//
+// POST /verify takes a JSON object with the base64 message and signature,
+// and either the base64 public key (falcon.MarshalPublicKey) or the ID of a
+// registered key:
+//
+//	{"key_id": "releases", "message": "...", "signature": "..."}
+//
+// and answers {"valid": true} or {"valid": false}. POST /verify/batch takes
+// {"requests": [...]} and answers {"results": [...]} in the same order,
+// where malformed items carry an "error" instead of failing the batch.
+// Malformed requests get a 400 response, and bodies over -max-body bytes a
+// 413 response. The keys file is a JSON object mapping key IDs to base64
+// public keys. Counters of accepted, rejected and malformed signatures and a
+// histogram of verification latencies are published by expvar at
+// /debug/vars.
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	keysFile := flag.String("keys", "", "JSON file of registered public keys")
+	maxBody := flag.Int64("max-body", 1<<20, "maximum bytelength of request bodies")
+	maxBatch := flag.Int("max-batch", 256, "maximum number of requests in a batch")
+	flag.Parse()
+	if flag.NArg() != 0 || *maxBody <= 0 || *maxBatch <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	s := &server{keys: map[string]*falcon.PublicKey{}, maxBody: *maxBody, maxBatch: *maxBatch}
+	if *keysFile != "" {
+		f, err := os.Open(*keysFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		s.keys, err = loadKeys(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *keysFile, err)
+		}
+	}
+	log.Printf("serving %d registered keys on %s", len(s.keys), *addr)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       time.Minute,
+	}
+	log.Fatal(srv.ListenAndServe())
+}
+
+// loadKeys reads a JSON object mapping key IDs to base64 public keys.
+func loadKeys(r io.Reader) (map[string]*falcon.PublicKey, error) {
+	var encoded map[string]string
+	if err := json.NewDecoder(r).Decode(&encoded); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*falcon.PublicKey, len(encoded))
+	for id, value := range encoded {
+		raw, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", id, err)
+		}
+		pubKey, err := falcon.ParsePublicKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %v", id, err)
+		}
+		keys[id] = pubKey
+	}
+	return keys, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// Metrics, published under /debug/vars.
+var (
+	metrics = expvar.NewMap("falcon_verifyd")
+	// latencies counts verifications by their duration in microseconds,
+	// in buckets named by their upper bound.
+	latencies      = new(expvar.Map).Init()
+	latencyBuckets = []struct {
+		bound time.Duration
+		name  string
+	}{
+		{100 * time.Microsecond, "le_100"},
+		{250 * time.Microsecond, "le_250"},
+		{500 * time.Microsecond, "le_500"},
+		{time.Millisecond, "le_1000"},
+		{2500 * time.Microsecond, "le_2500"},
+		{10 * time.Millisecond, "le_10000"},
+	}
+)
+
+func init() {
+	metrics.Set("latency_us", latencies)
+}
+
+var (
+	errUnknownKey   = errors.New("unknown key_id")
+	errKeyChoice    = errors.New("exactly one of public_key and key_id must be set")
+	errInvalidKey   = errors.New("invalid public_key")
+	errInvalidInput = errors.New("message and signature must be base64")
+	errBatchSize    = errors.New("too many requests in the batch")
+)
+
+// verifyRequest is the body of POST /verify and an item of POST /verify/batch.
+// Binary fields are in standard base64.
+type verifyRequest struct {
+	PublicKey string `json:"public_key,omitempty"`
+	KeyID     string `json:"key_id,omitempty"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+}
+
+type verifyResponse struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+type batchRequest struct {
+	Requests []verifyRequest `json:"requests"`
+}
+
+type batchResponse struct {
+	Results []verifyResponse `json:"results"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// server verifies signatures under submitted or registered public keys.
+type server struct {
+	keys     map[string]*falcon.PublicKey
+	maxBody  int64
+	maxBatch int
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", s.handleVerify)
+	mux.HandleFunc("/verify/batch", s.handleBatch)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+func (s *server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	var req verifyRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	resp, err := s.verify(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleBatch verifies every request of the batch; an invalid item yields
+// an error in its result rather than failing the batch.
+func (s *server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if !s.decode(w, r, &req) {
+		return
+	}
+	if len(req.Requests) > s.maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, errBatchSize)
+		return
+	}
+	resp := batchResponse{Results: make([]verifyResponse, len(req.Requests))}
+	for i := range req.Requests {
+		result, err := s.verify(&req.Requests[i])
+		if err != nil {
+			result = verifyResponse{Error: err.Error()}
+		}
+		resp.Results[i] = result
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// decode reads the JSON body of a POST request into v, or writes the error
+// response and returns false.
+func (s *server) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return false
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.maxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		metrics.Add("malformed", 1)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, errors.New("request body too large"))
+			return false
+		}
+		writeError(w, http.StatusBadRequest, errors.New("invalid JSON body"))
+		return false
+	}
+	return true
+}
+
+// verify checks one request, returning an error if it is malformed.
+func (s *server) verify(req *verifyRequest) (verifyResponse, error) {
+	pubKey, err := s.publicKey(req)
+	if err != nil {
+		metrics.Add("malformed", 1)
+		return verifyResponse{}, err
+	}
+	message, err1 := base64.StdEncoding.DecodeString(req.Message)
+	signature, err2 := base64.StdEncoding.DecodeString(req.Signature)
+	if err1 != nil || err2 != nil {
+		metrics.Add("malformed", 1)
+		return verifyResponse{}, errInvalidInput
+	}
+
+	start := time.Now()
+	valid := pubKey.Verify(message, signature)
+	observeLatency(time.Since(start))
+	if valid {
+		metrics.Add("accepted", 1)
+	} else {
+		metrics.Add("rejected", 1)
+	}
+	return verifyResponse{Valid: valid}, nil
+}
+
+// publicKey returns the submitted or registered key of a request.
+func (s *server) publicKey(req *verifyRequest) (*falcon.PublicKey, error) {
+	if (req.PublicKey == "") == (req.KeyID == "") {
+		return nil, errKeyChoice
+	}
+	if req.KeyID != "" {
+		pubKey, ok := s.keys[req.KeyID]
+		if !ok {
+			return nil, errUnknownKey
+		}
+		return pubKey, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(req.PublicKey)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	pubKey, err := falcon.ParsePublicKey(raw)
+	if err != nil {
+		return nil, errInvalidKey
+	}
+	return pubKey, nil
+}
+
+func observeLatency(d time.Duration) {
+	metrics.Add("latency_us_sum", d.Microseconds())
+	for _, bucket := range latencyBuckets {
+		if d <= bucket.bound {
+			latencies.Add(bucket.name, 1)
+			return
+		}
+	}
+	latencies.Add("inf", 1)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
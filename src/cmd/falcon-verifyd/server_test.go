@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+var b64 = base64.StdEncoding.EncodeToString
+
+// newTestServer returns a server registering the public key of the test
+// key as "releases", with a valid signature of message.
+func newTestServer(t *testing.T) (*httptest.Server, *falcon.PublicKey, []byte, []byte) {
+	t.Helper()
+	data, err := os.ReadFile("../../testdata/falcon512_priv.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := privKey.GetPublicKey()
+	raw, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := loadKeys(strings.NewReader(`{"releases": "` + b64(raw) + `"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("release 1.0")
+	sig, err := privKey.SignFormat(message, falcon.FormatCompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &server{keys: keys, maxBody: 1 << 16, maxBatch: 4}
+	ts := httptest.NewServer(s.handler())
+	t.Cleanup(ts.Close)
+	return ts, pubKey, message, sig
+}
+
+func post(t *testing.T, url string, body any) (int, map[string]any) {
+	t.Helper()
+	var payload []byte
+	if s, ok := body.(string); ok {
+		payload = []byte(s)
+	} else {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := http.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, decoded
+}
+
+func counter(name string) int64 {
+	if v, ok := metrics.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestVerify(t *testing.T) {
+	ts, pubKey, message, sig := newTestServer(t)
+	raw, _ := falcon.MarshalPublicKey(pubKey)
+	accepted, rejected := counter("accepted"), counter("rejected")
+
+	testCases := []struct {
+		req    verifyRequest
+		status int
+		valid  bool
+	}{
+		{verifyRequest{KeyID: "releases", Message: b64(message), Signature: b64(sig)}, http.StatusOK, true},
+		{verifyRequest{PublicKey: b64(raw), Message: b64(message), Signature: b64(sig)}, http.StatusOK, true},
+		{verifyRequest{KeyID: "releases", Message: b64([]byte("release 1.1")), Signature: b64(sig)}, http.StatusOK, false},
+		{verifyRequest{KeyID: "releases", Message: b64(message), Signature: b64(sig[:10])}, http.StatusOK, false},
+		{verifyRequest{KeyID: "nightly", Message: b64(message), Signature: b64(sig)}, http.StatusBadRequest, false},
+		{verifyRequest{KeyID: "releases", PublicKey: b64(raw), Message: b64(message), Signature: b64(sig)}, http.StatusBadRequest, false},
+		{verifyRequest{PublicKey: b64(raw[1:]), Message: b64(message), Signature: b64(sig)}, http.StatusBadRequest, false},
+		{verifyRequest{KeyID: "releases", Message: "not base64!", Signature: b64(sig)}, http.StatusBadRequest, false},
+	}
+	for i, tc := range testCases {
+		status, resp := post(t, ts.URL+"/verify", tc.req)
+		if status != tc.status || (status == http.StatusOK && resp["valid"] != tc.valid) {
+			t.Errorf("case %d: status %d, response %v", i, status, resp)
+		}
+		if status != http.StatusOK && resp["error"] == nil {
+			t.Errorf("case %d: no error message", i)
+		}
+	}
+	if counter("accepted")-accepted != 2 || counter("rejected")-rejected != 2 {
+		t.Errorf("%d accepted and %d rejected signatures were counted",
+			counter("accepted")-accepted, counter("rejected")-rejected)
+	}
+
+	resp, err := http.Get(ts.URL + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(vars["falcon_verifyd"], []byte(`"latency_us"`)) {
+		t.Errorf("metrics %s", vars["falcon_verifyd"])
+	}
+}
+
+func TestVerifyBatch(t *testing.T) {
+	ts, _, message, sig := newTestServer(t)
+	good := verifyRequest{KeyID: "releases", Message: b64(message), Signature: b64(sig)}
+	bad := verifyRequest{KeyID: "releases", Message: b64([]byte("other")), Signature: b64(sig)}
+	unknown := verifyRequest{KeyID: "nightly", Message: b64(message), Signature: b64(sig)}
+
+	status, resp := post(t, ts.URL+"/verify/batch", batchRequest{Requests: []verifyRequest{good, bad, unknown}})
+	results, _ := resp["results"].([]any)
+	if status != http.StatusOK || len(results) != 3 {
+		t.Fatalf("status %d, response %v", status, resp)
+	}
+	want := []string{`{"valid":true}`, `{"valid":false}`, `{"error":"unknown key_id","valid":false}`}
+	for i, result := range results {
+		if got, _ := json.Marshal(result); string(got) != want[i] {
+			t.Errorf("result %d = %s, want %s", i, got, want[i])
+		}
+	}
+
+	if status, _ := post(t, ts.URL+"/verify/batch", batchRequest{Requests: []verifyRequest{good, good, good, good, good}}); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("an oversized batch returned %d", status)
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	ts, _, _, _ := newTestServer(t)
+	if status, _ := post(t, ts.URL+"/verify", `{"key_id": "releases", "message": "`+strings.Repeat("A", 1<<17)+`"}`); status != http.StatusRequestEntityTooLarge {
+		t.Errorf("an oversized body returned %d", status)
+	}
+	if status, _ := post(t, ts.URL+"/verify", `{"key_id": "releases"`); status != http.StatusBadRequest {
+		t.Errorf("truncated JSON returned %d", status)
+	}
+	if status, _ := post(t, ts.URL+"/verify", `{"key": "releases"}`); status != http.StatusBadRequest {
+		t.Errorf("an unknown field returned %d", status)
+	}
+	resp, err := http.Get(ts.URL + "/verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != http.MethodPost {
+		t.Errorf("GET /verify returned %d", resp.StatusCode)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	for _, keys := range []string{`{"a": "AAAA"}`, `{"a": "!"}`, `["a"]`} {
+		if _, err := loadKeys(strings.NewReader(keys)); err == nil {
+			t.Errorf("loadKeys(%s) succeeded", keys)
+		}
+	}
+}
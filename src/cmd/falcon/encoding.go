@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// Encodings of keys and signatures on disk.
+const (
+	encodingBinary = "bin"
+	encodingPEM    = "pem"
+	encodingHex    = "hex"
+	encodingText   = "text"
+)
+
+const pemTypeSignature = "FALCON SIGNATURE"
+
+var errUnknownObject = errors.New("input is not a Falcon key or signature")
+
+// object is a decoded key or signature; exactly one field is set.
+type object struct {
+	pub  *falcon.PublicKey
+	priv *falcon.PrivateKey
+	sig  []byte
+}
+
+// kind returns a description of the object.
+func (obj *object) kind() string {
+	switch {
+	case obj.pub != nil:
+		return "public key"
+	case obj.priv != nil:
+		return "private key"
+	}
+	return "signature"
+}
+
+// publicKey returns the public key of a key object.
+func (obj *object) publicKey() (*falcon.PublicKey, error) {
+	switch {
+	case obj.pub != nil:
+		return obj.pub, nil
+	case obj.priv != nil:
+		return obj.priv.DerivePublicKey()
+	}
+	return nil, errors.New("expected a key, got a signature")
+}
+
+func checkEncoding(encoding string) error {
+	switch encoding {
+	case encodingBinary, encodingPEM, encodingHex, encodingText:
+		return nil
+	}
+	return fmt.Errorf("unknown encoding %q, want bin, pem, hex or text", encoding)
+}
+
+// detectEncoding guesses the encoding of data: PEM blocks, a single token of
+// hexadecimal digits, whitespace-separated decimal integers as written by the
+// reference implementation, and binary otherwise.
+func detectEncoding(data []byte) string {
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		return encodingPEM
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return encodingBinary
+	}
+	if len(fields) == 1 && len(fields[0])%2 == 0 {
+		if _, err := hex.DecodeString(fields[0]); err == nil {
+			return encodingHex
+		}
+	}
+	for _, field := range fields {
+		if _, err := strconv.Atoi(field); err != nil {
+			return encodingBinary
+		}
+	}
+	return encodingText
+}
+
+// decodeObject decodes a key or a signature in any of the encodings, and
+// returns it with the detected encoding.
+func decodeObject(data []byte) (*object, string, error) {
+	encoding := detectEncoding(data)
+	var obj *object
+	var err error
+	switch encoding {
+	case encodingPEM:
+		obj, err = decodePEM(data)
+	case encodingHex:
+		var raw []byte
+		if raw, err = hex.DecodeString(strings.TrimSpace(string(data))); err == nil {
+			obj, err = decodeBinary(raw)
+		}
+	case encodingText:
+		obj, err = decodeText(string(data))
+	default:
+		obj, err = decodeBinary(data)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return obj, encoding, nil
+}
+
+// decodeBinary decodes the binary encodings of the reference implementation.
+// Private keys and CT signatures share a header and are told apart by length.
+func decodeBinary(data []byte) (*object, error) {
+	if pub, err := falcon.ParsePublicKey(data); err == nil {
+		return &object{pub: pub}, nil
+	}
+	if priv, err := falcon.ParsePrivateKey(data); err == nil {
+		return &object{priv: priv}, nil
+	}
+	if _, err := signatureDegree(data); err == nil {
+		return &object{sig: data}, nil
+	}
+	return nil, errUnknownObject
+}
+
+func decodePEM(data []byte) (*object, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, falcon.ErrInvalidPEM
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err := falcon.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &object{pub: pub}, nil
+	case "PRIVATE KEY":
+		priv, err := falcon.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return &object{priv: priv}, nil
+	case pemTypeSignature:
+		if _, err := signatureDegree(block.Bytes); err != nil {
+			return nil, err
+		}
+		return &object{sig: block.Bytes}, nil
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// decodeText decodes the decimal dumps of the reference implementation: a
+// private key is f, g, F and G on four lines, a public key is h on one line,
+// and a signature is its bytes on one line. A line with a value above 255 is
+// a public key.
+func decodeText(data string) (*object, error) {
+	var lines [][]int
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		values := make([]int, len(fields))
+		for i, field := range fields {
+			v, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		lines = append(lines, values)
+	}
+	switch len(lines) {
+	case 1:
+		isKey := false
+		for _, v := range lines[0] {
+			if v < 0 || v > 255 {
+				isKey = true
+			}
+		}
+		if isKey {
+			pub, err := falcon.PublicKeyFromPolynomial(toInt16(lines[0]))
+			if err != nil {
+				return nil, err
+			}
+			return &object{pub: pub}, nil
+		}
+		sig := make([]byte, len(lines[0]))
+		for i, v := range lines[0] {
+			sig[i] = byte(v)
+		}
+		if _, err := signatureDegree(sig); err != nil {
+			return nil, err
+		}
+		return &object{sig: sig}, nil
+	case 4:
+		var polys [4][]int16
+		for i, line := range lines {
+			polys[i] = toInt16(line)
+		}
+		priv, _, err := falcon.NewKeyPairFromPrivateKey(uint16(len(polys[0])), polys)
+		if err != nil {
+			return nil, err
+		}
+		// Decoding the binary encoding recomputes G and checks the NTRU equation
+		encoded, err := falcon.MarshalPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		checked, err := falcon.ParsePrivateKey(encoded)
+		if err != nil || !checked.Equal(priv) {
+			return nil, falcon.ErrInvalidKeyEncoding
+		}
+		return &object{priv: priv}, nil
+	}
+	return nil, errUnknownObject
+}
+
+func toInt16(values []int) []int16 {
+	out := make([]int16, len(values))
+	for i, v := range values {
+		out[i] = int16(v)
+	}
+	return out
+}
+
+// encodeObject encodes a key or a signature.
+func encodeObject(obj *object, encoding string) ([]byte, error) {
+	if encoding == encodingText {
+		return encodeText(obj), nil
+	}
+	if encoding == encodingPEM {
+		switch {
+		case obj.pub != nil:
+			return falcon.MarshalPublicKeyPEM(obj.pub)
+		case obj.priv != nil:
+			return falcon.MarshalPrivateKeyPEM(obj.priv)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemTypeSignature, Bytes: obj.sig}), nil
+	}
+	var raw []byte
+	var err error
+	switch {
+	case obj.pub != nil:
+		raw, err = falcon.MarshalPublicKey(obj.pub)
+	case obj.priv != nil:
+		raw, err = falcon.MarshalPrivateKey(obj.priv)
+	default:
+		raw = obj.sig
+	}
+	if err != nil {
+		return nil, err
+	}
+	if encoding == encodingHex {
+		return []byte(hex.EncodeToString(raw) + "\n"), nil
+	}
+	return raw, nil
+}
+
+func encodeText(obj *object) []byte {
+	var polys [][]int16
+	switch {
+	case obj.pub != nil:
+		polys = [][]int16{obj.pub.Polynomial()}
+	case obj.priv != nil:
+		p := obj.priv.Polynomials()
+		polys = p[:]
+	default:
+		line := make([]int16, len(obj.sig))
+		for i, b := range obj.sig {
+			line[i] = int16(b)
+		}
+		polys = [][]int16{line}
+	}
+	var buf bytes.Buffer
+	for _, poly := range polys {
+		for i, v := range poly {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(strconv.Itoa(int(v)))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// signatureDegree returns the degree announced by the header of a signature.
+func signatureDegree(sig []byte) (uint16, error) {
+	if len(sig) < falcon.HeadLen+falcon.SaltLen {
+		return 0, errors.New("signature is too short")
+	}
+	logn := sig[0] & 0x0F
+	if (sig[0]&0xF0 != 0x30 && sig[0]&0xF0 != 0x50) || logn < 1 || logn > 10 {
+		return 0, falcon.ErrInvalidHeader
+	}
+	return 1 << logn, nil
+}
+
+// signatureFormat returns the name of the format of a signature. Padded and
+// compressed signatures share a header; only padded ones have the full length.
+func signatureFormat(sig []byte, n uint16) string {
+	if sig[0]&0xF0 == 0x50 {
+		return falcon.FormatCT.String()
+	}
+	if len(sig) == falcon.GetParamSet(n).SignatureLen() {
+		return falcon.FormatPadded.String()
+	}
+	return falcon.FormatCompressed.String()
+}
@@ -0,0 +1,406 @@
+// Command falcon generates Falcon keys, signs and verifies messages, and
+// inspects and converts keys and signatures:
+//
+//	falcon keygen [-n 512|1024] [-seed hex] [-encoding pem] [-out falcon]
+//	falcon sign -key key_file [-format padded|compressed|ct] [-encoding bin] [-in message] [-out signature]
+//	falcon verify -pub key_file -sig signature [-in message]
+//	falcon inspect [-pub key_file -in message] [-tree] file
+//	falcon convert -to bin|pem|hex|text [-public] [-out file] file
+//
+// Keys and signatures are read in any of the encodings bin (the binary
+// encodings of the reference implementation), pem (PKIX and PKCS #8 keys, and
+// "FALCON SIGNATURE" blocks), hex, and text (the decimal dumps of the
+// reference implementation, such as pubkeyC.txt and signatureC.txt); the
+// encoding is detected from the content. Messages are read from the standard
+// input when -in is not given, and outputs are written to the standard output
+// when -out is not given. A file name of "-" is the standard input.
+//
+// The exit status is 0 on success, 1 when a signature does not verify, 2 on
+// usage errors and 3 on other failures.
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	exitInvalid = 1
+	exitUsage   = 2
+	exitFailure = 3
+)
+
+// minSeedLen is the minimum bytelength of keygen seeds.
+const minSeedLen = 32
+
+const usage = `usage: falcon keygen [-n 512|1024] [-seed hex] [-encoding pem] [-out falcon]
+       falcon sign -key key_file [-format padded|compressed|ct] [-encoding bin] [-in message] [-out signature]
+       falcon verify -pub key_file -sig signature [-in message]
+       falcon inspect [-pub key_file -in message] [-tree] file
+       falcon convert -to bin|pem|hex|text [-public] [-out file] file`
+
+var (
+	errUsage   = errors.New(usage)
+	errInvalid = errors.New("signature verification failed")
+)
+
+// command is a subcommand, which reads its flags from fs.
+type command struct {
+	fs  *flag.FlagSet
+	run func(args []string) error
+}
+
+// env holds the standard streams of a run.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, errUsage)
+		return exitUsage
+	}
+	e := &env{stdin: stdin, stdout: stdout, stderr: stderr}
+	var cmd *command
+	switch args[0] {
+	case "keygen":
+		cmd = e.keygen()
+	case "sign":
+		cmd = e.sign()
+	case "verify":
+		cmd = e.verify()
+	case "inspect":
+		cmd = e.inspect()
+	case "convert":
+		cmd = e.convert()
+	default:
+		fmt.Fprintln(stderr, errUsage)
+		return exitUsage
+	}
+	cmd.fs.SetOutput(stderr)
+	if err := cmd.fs.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+	err := cmd.run(cmd.fs.Args())
+	switch err {
+	case nil:
+		return 0
+	case errUsage:
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	case errInvalid:
+		fmt.Fprintln(stderr, err)
+		return exitInvalid
+	}
+	fmt.Fprintf(stderr, "falcon %s: %v\n", args[0], err)
+	return exitFailure
+}
+
+func (e *env) keygen() *command {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	n := fs.Uint("n", 512, "degree of the key, 512 or 1024")
+	seed := fs.String("seed", "", "hexadecimal seed of at least 32 bytes, for a deterministic key")
+	encoding := fs.String("encoding", encodingPEM, "encoding of the key files")
+	out := fs.String("out", "falcon", "private key file; the public key is written to the same name with .pub")
+	return &command{fs, func(args []string) error {
+		if len(args) != 0 || (*n != 512 && *n != 1024) || checkEncoding(*encoding) != nil {
+			return errUsage
+		}
+		var priv *falcon.PrivateKey
+		var err error
+		if *seed != "" {
+			s, err := hex.DecodeString(*seed)
+			if err != nil || len(s) < minSeedLen {
+				return fmt.Errorf("the seed must be at least %d hexadecimal bytes", minSeedLen)
+			}
+			// The seed is expanded into the randomness of key generation
+			shake := sha3.NewShake256()
+			shake.Write(s)
+			priv, err = falcon.GeneratePrivateKeyFrom(uint16(*n), shake)
+			if err != nil {
+				return err
+			}
+		} else if priv, err = falcon.GeneratePrivateKey(uint16(*n)); err != nil {
+			return err
+		}
+		privData, err := encodeObject(&object{priv: priv}, *encoding)
+		if err != nil {
+			return err
+		}
+		pub, err := priv.DerivePublicKey()
+		if err != nil {
+			return err
+		}
+		pubData, err := encodeObject(&object{pub: pub}, *encoding)
+		if err != nil {
+			return err
+		}
+		if err := createFile(*out, privData, 0600); err != nil {
+			return err
+		}
+		if err := createFile(*out+".pub", pubData, 0644); err != nil {
+			return err
+		}
+		fingerprint, err := pub.Fingerprint()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "Falcon-%d key saved in %s and %s.pub\n%s\n", *n, *out, *out, fingerprint)
+		return nil
+	}}
+}
+
+func (e *env) sign() *command {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyFile := fs.String("key", "", "private key file")
+	format := fs.String("format", falcon.FormatPadded.String(), "signature format: padded, compressed or ct")
+	encoding := fs.String("encoding", encodingBinary, "encoding of the signature")
+	in := fs.String("in", "-", "message file")
+	out := fs.String("out", "-", "signature file")
+	return &command{fs, func(args []string) error {
+		sigFormat, ok := parseFormat(*format)
+		if len(args) != 0 || *keyFile == "" || !ok || checkEncoding(*encoding) != nil {
+			return errUsage
+		}
+		key, err := e.readObject(*keyFile)
+		if err != nil {
+			return err
+		}
+		if key.priv == nil {
+			return fmt.Errorf("%s is not a private key", *keyFile)
+		}
+		message, err := e.readFile(*in)
+		if err != nil {
+			return err
+		}
+		sig, err := key.priv.SignFormat(message, sigFormat)
+		if err != nil {
+			return err
+		}
+		data, err := encodeObject(&object{sig: sig}, *encoding)
+		if err != nil {
+			return err
+		}
+		return e.writeFile(*out, data)
+	}}
+}
+
+func (e *env) verify() *command {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keyFile := fs.String("pub", "", "public or private key file")
+	sigFile := fs.String("sig", "", "signature file")
+	in := fs.String("in", "-", "message file")
+	return &command{fs, func(args []string) error {
+		if len(args) != 0 || *keyFile == "" || *sigFile == "" {
+			return errUsage
+		}
+		pub, sig, message, err := e.readVerifyInputs(*keyFile, *sigFile, *in)
+		if err != nil {
+			return err
+		}
+		if !pub.Verify(message, sig) {
+			return errInvalid
+		}
+		fmt.Fprintln(e.stdout, "Signature OK")
+		return nil
+	}}
+}
+
+func (e *env) inspect() *command {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	keyFile := fs.String("pub", "", "public key file, to check a signature")
+	in := fs.String("in", "", "message file, to check a signature")
+	tree := fs.Bool("tree", false, "print the LDL tree of the expanded private key")
+	return &command{fs, func(args []string) error {
+		if len(args) != 1 || (*keyFile == "") != (*in == "") {
+			return errUsage
+		}
+		obj, encoding, err := e.readObjectEncoding(args[0])
+		if err != nil {
+			return err
+		}
+		w := e.stdout
+		fmt.Fprintf(w, "type: %s\n", obj.kind())
+		fmt.Fprintf(w, "encoding: %s\n", encoding)
+		if obj.sig != nil {
+			return e.inspectSignature(obj.sig, *keyFile, *in)
+		}
+		pub, _ := obj.publicKey()
+		raw, err := encodeObject(obj, encodingBinary)
+		if err != nil {
+			return err
+		}
+		fingerprint, err := pub.Fingerprint()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "header: 0x%02x\n", raw[0])
+		fmt.Fprintf(w, "degree: %d\n", pub.Degree())
+		fmt.Fprintf(w, "length: %d\n", len(raw))
+		fmt.Fprintf(w, "fingerprint: %s\n", fingerprint)
+		if *tree {
+			if obj.priv == nil {
+				return errors.New("-tree requires a private key")
+			}
+			tree, err := obj.priv.PrintTree()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "tree:\n%s", tree)
+		}
+		return nil
+	}}
+}
+
+// inspectSignature prints the header of sig, and its verification by the
+// key of keyFile when one is given.
+func (e *env) inspectSignature(sig []byte, keyFile, in string) error {
+	w := e.stdout
+	n, err := signatureDegree(sig)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "header: 0x%02x\n", sig[0])
+	fmt.Fprintf(w, "degree: %d\n", n)
+	fmt.Fprintf(w, "format: %s\n", signatureFormat(sig, n))
+	fmt.Fprintf(w, "length: %d\n", len(sig))
+	fmt.Fprintf(w, "salt: %x\n", sig[falcon.HeadLen:falcon.HeadLen+falcon.SaltLen])
+	if keyFile == "" {
+		return nil
+	}
+	pub, _, message, err := e.readVerifyInputs(keyFile, "", in)
+	if err != nil {
+		return err
+	}
+	witness, err := pub.ExplainVerify(message, sig)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "norm: %d\n", witness.Norm)
+	fmt.Fprintf(w, "bound: %d\n", witness.Bound)
+	fmt.Fprintf(w, "valid: %v\n", witness.Valid)
+	return nil
+}
+
+func (e *env) convert() *command {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "", "output encoding: bin, pem, hex or text")
+	public := fs.Bool("public", false, "write the public key of a private key")
+	out := fs.String("out", "-", "output file")
+	return &command{fs, func(args []string) error {
+		if len(args) != 1 || checkEncoding(*to) != nil {
+			return errUsage
+		}
+		obj, err := e.readObject(args[0])
+		if err != nil {
+			return err
+		}
+		if *public {
+			pub, err := obj.publicKey()
+			if err != nil {
+				return err
+			}
+			obj = &object{pub: pub}
+		}
+		data, err := encodeObject(obj, *to)
+		if err != nil {
+			return err
+		}
+		return e.writeFile(*out, data)
+	}}
+}
+
+// readVerifyInputs reads the public key of keyFile, the signature of sigFile
+// if it is not empty, and the message of in.
+func (e *env) readVerifyInputs(keyFile, sigFile, in string) (*falcon.PublicKey, []byte, []byte, error) {
+	key, err := e.readObject(keyFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	pub, err := key.publicKey()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s: %v", keyFile, err)
+	}
+	var sig []byte
+	if sigFile != "" {
+		obj, err := e.readObject(sigFile)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if obj.sig == nil {
+			return nil, nil, nil, fmt.Errorf("%s is not a signature", sigFile)
+		}
+		sig = obj.sig
+	}
+	message, err := e.readFile(in)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return pub, sig, message, nil
+}
+
+func parseFormat(name string) (falcon.SignatureFormat, bool) {
+	for _, format := range []falcon.SignatureFormat{falcon.FormatPadded, falcon.FormatCompressed, falcon.FormatCT} {
+		if format.String() == name {
+			return format, true
+		}
+	}
+	return 0, false
+}
+
+func (e *env) readObject(name string) (*object, error) {
+	obj, _, err := e.readObjectEncoding(name)
+	return obj, err
+}
+
+func (e *env) readObjectEncoding(name string) (*object, string, error) {
+	data, err := e.readFile(name)
+	if err != nil {
+		return nil, "", err
+	}
+	obj, encoding, err := decodeObject(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %v", name, err)
+	}
+	return obj, encoding, nil
+}
+
+func (e *env) readFile(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(e.stdin)
+	}
+	return os.ReadFile(name)
+}
+
+func (e *env) writeFile(name string, data []byte) error {
+	if name == "-" {
+		_, err := e.stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+// createFile writes a new file, refusing to overwrite an existing key.
+func createFile(name string, data []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
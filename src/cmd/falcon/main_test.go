@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testdata = "../../testdata"
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// firstLine writes the first line of a reference text dump to dir, with
+// single spaces between the values as written by the text encoding.
+func firstLine(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("../..", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	line := strings.Join(strings.Fields(strings.SplitN(string(data), "\n", 2)[0]), " ") + "\n"
+	if err := os.WriteFile(path, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReferenceText(t *testing.T) {
+	dir := t.TempDir()
+	pub := firstLine(t, dir, "pubkeyC.txt")
+	sig := firstLine(t, dir, "signatureC.txt")
+	messageText := firstLine(t, dir, "messageC.txt")
+	var message []byte
+	for _, field := range strings.Fields(mustRead(t, messageText)) {
+		var b byte
+		for _, c := range field {
+			b = b*10 + byte(c-'0')
+		}
+		message = append(message, b)
+	}
+	messageFile := filepath.Join(dir, "message")
+	if err := os.WriteFile(messageFile, message, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, stdout, stderr := runCommand(t, string(message), "verify", "-pub", pub, "-sig", sig); code != 0 || stdout != "Signature OK\n" {
+		t.Fatalf("verify = %d, %q, %q", code, stdout, stderr)
+	}
+	if code, _, _ := runCommand(t, "other message", "verify", "-pub", pub, "-sig", sig); code != exitInvalid {
+		t.Errorf("verify of another message exited with %d", code)
+	}
+
+	// Every conversion of the text dumps verifies and converts back
+	for _, encoding := range []string{encodingBinary, encodingPEM, encodingHex, encodingText} {
+		pubOut := filepath.Join(dir, "pub."+encoding)
+		sigOut := filepath.Join(dir, "sig."+encoding)
+		if code, _, stderr := runCommand(t, "", "convert", "-to", encoding, "-out", pubOut, pub); code != 0 {
+			t.Fatalf("convert -to %s: %s", encoding, stderr)
+		}
+		if code, _, stderr := runCommand(t, "", "convert", "-to", encoding, "-out", sigOut, sig); code != 0 {
+			t.Fatalf("convert -to %s: %s", encoding, stderr)
+		}
+		if code, _, stderr := runCommand(t, string(message), "verify", "-pub", pubOut, "-sig", sigOut); code != 0 {
+			t.Errorf("verify of the %s encoding exited with %d: %s", encoding, code, stderr)
+		}
+		code, stdout, _ := runCommand(t, "", "convert", "-to", encodingText, pubOut)
+		if code != 0 || stdout != mustRead(t, pub) {
+			t.Errorf("the %s public key does not convert back to text", encoding)
+		}
+		code, stdout, _ = runCommand(t, "", "convert", "-to", encodingText, sigOut)
+		if code != 0 || stdout != mustRead(t, sig) {
+			t.Errorf("the %s signature does not convert back to text", encoding)
+		}
+	}
+
+	code, stdout, _ := runCommand(t, "", "inspect", "-pub", pub, "-in", messageFile, sig)
+	for _, want := range []string{"type: signature\n", "encoding: text\n", "header: 0x39\n", "degree: 512\n", "format: padded\n", "valid: true\n"} {
+		if code != 0 || !strings.Contains(stdout, want) {
+			t.Errorf("inspect output %q does not contain %q", stdout, want)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(testdata, "falcon512_priv.pem")
+	pub := filepath.Join(dir, "key.pub")
+	if code, _, stderr := runCommand(t, "", "convert", "-public", "-to", encodingHex, "-out", pub, key); code != 0 {
+		t.Fatalf("convert -public: %s", stderr)
+	}
+	message := filepath.Join(dir, "message")
+	if err := os.WriteFile(message, []byte("release 1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{"padded", "compressed", "ct"} {
+		sig := filepath.Join(dir, format+".sig")
+		if code, _, stderr := runCommand(t, "", "sign", "-key", key, "-format", format, "-encoding", encodingPEM, "-in", message, "-out", sig); code != 0 {
+			t.Fatalf("sign -format %s: %s", format, stderr)
+		}
+		if code, _, stderr := runCommand(t, "", "verify", "-pub", pub, "-sig", sig, "-in", message); code != 0 {
+			t.Errorf("verify of a %s signature exited with %d: %s", format, code, stderr)
+		}
+		if code, _, _ := runCommand(t, "release 1.1\n", "verify", "-pub", pub, "-sig", sig); code != exitInvalid {
+			t.Errorf("verify of another message exited with %d", code)
+		}
+		code, stdout, _ := runCommand(t, "", "inspect", "-pub", pub, "-in", message, sig)
+		if code != 0 || !strings.Contains(stdout, "format: "+format+"\n") || !strings.Contains(stdout, "valid: true\n") {
+			t.Errorf("inspect of a %s signature: %q", format, stdout)
+		}
+	}
+
+	if code, _, _ := runCommand(t, "", "sign", "-key", pub, "-in", message); code != exitFailure {
+		t.Errorf("sign with a public key exited with %d", code)
+	}
+	if code, _, _ := runCommand(t, "", "sign", "-format", "raw", "-key", key); code != exitUsage {
+		t.Errorf("sign with an unknown format exited with %d", code)
+	}
+	if code, _, _ := runCommand(t, "", "frobnicate"); code != exitUsage {
+		t.Errorf("an unknown command exited with %d", code)
+	}
+}
+
+func TestKeygen(t *testing.T) {
+	dir := t.TempDir()
+	seed := strings.Repeat("2a", minSeedLen)
+	var fingerprints []string
+	for _, name := range []string{"a", "b"} {
+		out := filepath.Join(dir, name)
+		code, stdout, stderr := runCommand(t, "", "keygen", "-seed", seed, "-encoding", encodingText, "-out", out)
+		if code != 0 {
+			t.Fatalf("keygen: %s", stderr)
+		}
+		fingerprints = append(fingerprints, strings.SplitN(stdout, "\n", 3)[1])
+		if info, err := os.Stat(out); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("private key file: %v, %v", info, err)
+		}
+	}
+	if fingerprints[0] != fingerprints[1] || !strings.HasPrefix(fingerprints[0], "SHA256:") {
+		t.Errorf("keys derived from the same seed have fingerprints %q", fingerprints)
+	}
+	if mustRead(t, filepath.Join(dir, "a")) != mustRead(t, filepath.Join(dir, "b")) {
+		t.Error("keys derived from the same seed differ")
+	}
+
+	code, stdout, _ := runCommand(t, "", "inspect", "-tree", filepath.Join(dir, "a"))
+	for _, want := range []string{"type: private key\n", "header: 0x59\n", fingerprints[0] + "\n", "tree:\n", "|_____> "} {
+		if code != 0 || !strings.Contains(stdout, want) {
+			t.Errorf("inspect output does not contain %q", want)
+		}
+	}
+
+	if code, _, _ := runCommand(t, "", "keygen", "-seed", seed, "-out", filepath.Join(dir, "a")); code != exitFailure {
+		t.Errorf("keygen over an existing key exited with %d", code)
+	}
+	if code, _, _ := runCommand(t, "", "keygen", "-seed", "2a2a", "-out", filepath.Join(dir, "c")); code != exitFailure {
+		t.Errorf("keygen with a short seed exited with %d", code)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,156 @@
+// Package composite implements composite signatures combining Falcon-512
+// with Ed25519 or ECDSA P-256, following the construction of the IETF LAMPS
+// composite signatures draft (draft-ietf-lamps-pq-composite-sigs):
+//
+//	M'      = Prefix ‖ Label ‖ len(ctx) ‖ ctx ‖ PH(M)
+//	falcon  = FN-DSA.Sign(falconSK, M', ctx = Label), in the padded format
+//	trad    = Ed25519.Sign(tradSK, M') or ECDSA.Sign(tradSK, SHA-256(M'))
+//	sig     = falcon ‖ trad
+//
+// A signature is valid only if both component signatures are valid. Keys
+// and signatures are the concatenation of the Falcon encoding, which has a
+// fixed length, and of the traditional one.
+//
+// The draft does not assign code points to Falcon, so the labels of this
+// package are provisional.
+package composite
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// Algorithm identifies a composite signature algorithm.
+type Algorithm uint8
+
+const (
+	// Falcon512Ed25519 combines Falcon-512 and Ed25519, with SHA-512 pre-hashing.
+	Falcon512Ed25519 Algorithm = iota + 1
+	// Falcon512P256 combines Falcon-512 and ECDSA P-256 with SHA-256, with
+	// SHA-256 pre-hashing.
+	Falcon512P256
+)
+
+// prefix starts every message representative.
+const prefix = "CompositeAlgorithmSignatures2025"
+
+// Sizes of the Falcon-512 components.
+const (
+	falconDegree         = 512
+	falconPublicKeySize  = 897
+	falconPrivateKeySize = 1281
+	falconSignatureSize  = 666
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned for an unknown composite algorithm
+	ErrUnsupportedAlgorithm = errors.New("composite: unsupported algorithm")
+	// ErrInvalidKey is returned when a key cannot be decoded or does not match the algorithm
+	ErrInvalidKey = errors.New("composite: invalid key")
+	// ErrInvalidSignature is returned when a component signature does not verify
+	ErrInvalidSignature = errors.New("composite: invalid signature")
+)
+
+type algorithmInfo struct {
+	label   string
+	preHash func() hash.Hash
+}
+
+var algorithms = map[Algorithm]algorithmInfo{
+	Falcon512Ed25519: {"COMPSIG-FALCON512-Ed25519-SHA512", sha512.New},
+	Falcon512P256:    {"COMPSIG-FALCON512-ECDSA-P256-SHA256", sha256.New},
+}
+
+// String returns the label of the algorithm.
+func (alg Algorithm) String() string {
+	if info, ok := algorithms[alg]; ok {
+		return info.label
+	}
+	return "unknown"
+}
+
+// messageRepresentative computes M' for message and the context ctx.
+func messageRepresentative(alg Algorithm, message, ctx []byte) ([]byte, error) {
+	info, ok := algorithms[alg]
+	if !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(ctx) > falcon.MaxContextLen {
+		return nil, falcon.ErrContextTooLong
+	}
+	h := info.preHash()
+	h.Write(message)
+	var mp bytes.Buffer
+	mp.WriteString(prefix)
+	mp.WriteString(info.label)
+	mp.WriteByte(byte(len(ctx)))
+	mp.Write(ctx)
+	mp.Write(h.Sum(nil))
+	return mp.Bytes(), nil
+}
+
+// falconOptions returns the FN-DSA options of the Falcon component.
+func falconOptions(alg Algorithm) *falcon.Options {
+	return &falcon.Options{Context: []byte(alg.String()), Format: falcon.FormatPadded}
+}
+
+// Sign signs message with the context ctx, of at most 255 bytes.
+func (priv *PrivateKey) Sign(message, ctx []byte) ([]byte, error) {
+	mp, err := messageRepresentative(priv.alg, message, ctx)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := priv.falcon.SignFNDSA(mp, falconOptions(priv.alg))
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != falconSignatureSize {
+		return nil, ErrInvalidSignature
+	}
+	switch priv.alg {
+	case Falcon512Ed25519:
+		return append(sig, ed25519.Sign(priv.ed25519, mp)...), nil
+	case Falcon512P256:
+		digest := sha256.Sum256(mp)
+		tradSig, err := ecdsa.SignASN1(rand.Reader, priv.ecdsa, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		return append(sig, tradSig...), nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// Verify checks a composite signature of message with the context ctx. Both
+// component signatures must be valid.
+func (pub *PublicKey) Verify(message, signature, ctx []byte) error {
+	mp, err := messageRepresentative(pub.alg, message, ctx)
+	if err != nil {
+		return err
+	}
+	if len(signature) <= falconSignatureSize {
+		return ErrInvalidSignature
+	}
+	falconSig, tradSig := signature[:falconSignatureSize], signature[falconSignatureSize:]
+	falconOK := pub.falcon.VerifyFNDSA(mp, falconSig, falconOptions(pub.alg)) == nil
+	var tradOK bool
+	switch pub.alg {
+	case Falcon512Ed25519:
+		tradOK = len(tradSig) == ed25519.SignatureSize && ed25519.Verify(pub.ed25519, mp, tradSig)
+	case Falcon512P256:
+		digest := sha256.Sum256(mp)
+		tradOK = ecdsa.VerifyASN1(pub.ecdsa, digest[:], tradSig)
+	}
+	if !falconOK || !tradOK {
+		return ErrInvalidSignature
+	}
+	return nil
+}
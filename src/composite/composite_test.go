@@ -0,0 +1,167 @@
+package composite
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+func loadFalconKey(t *testing.T) *falcon.PrivateKey {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/falcon512_priv.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func testKeys(t *testing.T) []*PrivateKey {
+	t.Helper()
+	falconKey := loadFalconKey(t)
+	edKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	priv1, err := NewPrivateKey(Falcon512Ed25519, falconKey, edKey)
+	if err != nil {
+		t.Fatalf("NewPrivateKey returned %v", err)
+	}
+	priv2, err := GenerateKey(Falcon512P256)
+	if err != nil {
+		t.Fatalf("GenerateKey returned %v", err)
+	}
+	return []*PrivateKey{priv1, priv2}
+}
+
+func TestMessageRepresentative(t *testing.T) {
+	testCases := []struct {
+		alg  Algorithm
+		ctx  string
+		want string
+	}{
+		{Falcon512Ed25519, "ctx", "436f6d706f73697465416c676f726974686d5369676e61747572657332303235434f4d505349472d46414c434f4e3531322d456432353531392d53484135313203637478ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{Falcon512P256, "", "436f6d706f73697465416c676f726974686d5369676e61747572657332303235434f4d505349472d46414c434f4e3531322d45434453412d503235362d53484132353600ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+	}
+	for _, tc := range testCases {
+		mp, err := messageRepresentative(tc.alg, []byte("abc"), []byte(tc.ctx))
+		if err != nil {
+			t.Fatalf("%v: messageRepresentative returned %v", tc.alg, err)
+		}
+		if got := hex.EncodeToString(mp); got != tc.want {
+			t.Errorf("%v: M' = %s, want %s", tc.alg, got, tc.want)
+		}
+	}
+	if _, err := messageRepresentative(Falcon512Ed25519, nil, make([]byte, 256)); err != falcon.ErrContextTooLong {
+		t.Errorf("messageRepresentative with a long context returned %v", err)
+	}
+	if _, err := messageRepresentative(0, nil, nil); err != ErrUnsupportedAlgorithm {
+		t.Errorf("messageRepresentative(0) returned %v", err)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	keys := testKeys(t)
+	message, ctx := []byte("message"), []byte("context")
+	var sigs [][]byte
+	for _, priv := range keys {
+		pub := priv.PublicKey()
+		sig, err := priv.Sign(message, ctx)
+		if err != nil {
+			t.Fatalf("%v: Sign returned %v", priv.Algorithm(), err)
+		}
+		sigs = append(sigs, sig)
+		if err := pub.Verify(message, sig, ctx); err != nil {
+			t.Errorf("%v: Verify returned %v", priv.Algorithm(), err)
+		}
+		if err := pub.Verify([]byte("other"), sig, ctx); err != ErrInvalidSignature {
+			t.Errorf("%v: Verify(other message) returned %v", priv.Algorithm(), err)
+		}
+		if err := pub.Verify(message, sig, nil); err != ErrInvalidSignature {
+			t.Errorf("%v: Verify(other context) returned %v", priv.Algorithm(), err)
+		}
+		for _, i := range []int{10, falconSignatureSize + 10, len(sig) - 1} {
+			bad := append([]byte(nil), sig...)
+			bad[i] ^= 1
+			if err := pub.Verify(message, bad, ctx); err != ErrInvalidSignature {
+				t.Errorf("%v: Verify with byte %d flipped returned %v", priv.Algorithm(), i, err)
+			}
+		}
+		if err := pub.Verify(message, sig[:falconSignatureSize], ctx); err != ErrInvalidSignature {
+			t.Errorf("%v: Verify of the Falcon component alone returned %v", priv.Algorithm(), err)
+		}
+	}
+
+	// A component signature is bound to its composite algorithm: both
+	// algorithms share the Falcon key of the first one here, so a Falcon
+	// signature of one is not valid for the other.
+	falconKey := loadFalconKey(t)
+	other, err := NewPrivateKey(Falcon512P256, falconKey, keys[1].ecdsa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mixed := append(append([]byte(nil), sigs[0][:falconSignatureSize]...), sigs[1][falconSignatureSize:]...)
+	if err := other.PublicKey().Verify(message, mixed, ctx); err != ErrInvalidSignature {
+		t.Errorf("Verify of a mixed signature returned %v", err)
+	}
+}
+
+func TestKeyEncoding(t *testing.T) {
+	sizes := map[Algorithm][2]int{
+		Falcon512Ed25519: {falconPublicKeySize + 32, falconPrivateKeySize + 32},
+		Falcon512P256:    {falconPublicKeySize + 65, 0},
+	}
+	for _, priv := range testKeys(t) {
+		alg := priv.Algorithm()
+		pub := priv.PublicKey()
+		pubBytes, err := MarshalPublicKey(pub)
+		if err != nil {
+			t.Fatalf("%v: MarshalPublicKey returned %v", alg, err)
+		}
+		if len(pubBytes) != sizes[alg][0] {
+			t.Errorf("%v: public key of %d bytes, want %d", alg, len(pubBytes), sizes[alg][0])
+		}
+		parsedPub, err := ParsePublicKey(alg, pubBytes)
+		if err != nil {
+			t.Fatalf("%v: ParsePublicKey returned %v", alg, err)
+		}
+		if !parsedPub.Equal(pub) {
+			t.Errorf("%v: public key does not round-trip", alg)
+		}
+
+		privBytes, err := MarshalPrivateKey(priv)
+		if err != nil {
+			t.Fatalf("%v: MarshalPrivateKey returned %v", alg, err)
+		}
+		if n := sizes[alg][1]; n != 0 && len(privBytes) != n {
+			t.Errorf("%v: private key of %d bytes, want %d", alg, len(privBytes), n)
+		}
+		parsedPriv, err := ParsePrivateKey(alg, privBytes)
+		if err != nil {
+			t.Fatalf("%v: ParsePrivateKey returned %v", alg, err)
+		}
+		if !parsedPriv.PublicKey().Equal(pub) {
+			t.Errorf("%v: private key does not round-trip", alg)
+		}
+
+		for _, data := range [][]byte{nil, pubBytes[:falconPublicKeySize], pubBytes[:len(pubBytes)-1], append(pubBytes, 0)} {
+			if _, err := ParsePublicKey(alg, data); err != ErrInvalidKey {
+				t.Errorf("%v: ParsePublicKey of %d bytes returned %v", alg, len(data), err)
+			}
+		}
+		if _, err := ParsePrivateKey(alg, privBytes[:len(privBytes)-1]); err != ErrInvalidKey {
+			t.Errorf("%v: ParsePrivateKey of a truncated key returned %v", alg, err)
+		}
+	}
+
+	if _, err := ParsePublicKey(0, nil); err != ErrUnsupportedAlgorithm {
+		t.Errorf("ParsePublicKey(0) returned %v", err)
+	}
+	if _, err := NewPrivateKey(Falcon512P256, loadFalconKey(t), ed25519.NewKeyFromSeed(make([]byte, 32))); err != ErrInvalidKey {
+		t.Errorf("NewPrivateKey with an Ed25519 key for P-256 returned %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package composite
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+/*
+Composite keys.
+
+Public keys are the Falcon public key encoding followed by the Ed25519 public
+key or the uncompressed P-256 point. Private keys are the Falcon private key
+encoding followed by the Ed25519 seed or the DER ECPrivateKey structure
+(RFC 5915) of the P-256 key.
+*/
+
+// PublicKey is a composite public key.
+type PublicKey struct {
+	alg     Algorithm
+	falcon  *falcon.PublicKey
+	ed25519 ed25519.PublicKey
+	ecdsa   *ecdsa.PublicKey
+}
+
+// PrivateKey is a composite private key.
+type PrivateKey struct {
+	alg       Algorithm
+	falcon    *falcon.PrivateKey
+	falconPub *falcon.PublicKey
+	ed25519   ed25519.PrivateKey
+	ecdsa     *ecdsa.PrivateKey
+}
+
+// GenerateKey generates a composite key pair for alg.
+func GenerateKey(alg Algorithm) (*PrivateKey, error) {
+	falconKey, _, err := falcon.NewKeyPair(falconDegree)
+	if err != nil {
+		return nil, err
+	}
+	var trad crypto.PrivateKey
+	switch alg {
+	case Falcon512Ed25519:
+		_, trad, err = ed25519.GenerateKey(rand.Reader)
+	case Falcon512P256:
+		trad, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(alg, falconKey, trad)
+}
+
+// NewPrivateKey combines a Falcon-512 private key with an ed25519.PrivateKey
+// or a P-256 *ecdsa.PrivateKey, depending on alg.
+func NewPrivateKey(alg Algorithm, falconKey *falcon.PrivateKey, trad crypto.PrivateKey) (*PrivateKey, error) {
+	if falconKey.Degree() != falconDegree {
+		return nil, ErrInvalidKey
+	}
+	falconPub, err := falconKey.DerivePublicKey()
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	priv := &PrivateKey{alg: alg, falcon: falconKey, falconPub: falconPub}
+	switch alg {
+	case Falcon512Ed25519:
+		key, ok := trad.(ed25519.PrivateKey)
+		if !ok || len(key) != ed25519.PrivateKeySize {
+			return nil, ErrInvalidKey
+		}
+		priv.ed25519 = key
+	case Falcon512P256:
+		key, ok := trad.(*ecdsa.PrivateKey)
+		if !ok || key.Curve != elliptic.P256() {
+			return nil, ErrInvalidKey
+		}
+		priv.ecdsa = key
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return priv, nil
+}
+
+// NewPublicKey combines a Falcon-512 public key with an ed25519.PublicKey
+// or a P-256 *ecdsa.PublicKey, depending on alg.
+func NewPublicKey(alg Algorithm, falconKey *falcon.PublicKey, trad crypto.PublicKey) (*PublicKey, error) {
+	if falconKey.Degree() != falconDegree {
+		return nil, ErrInvalidKey
+	}
+	pub := &PublicKey{alg: alg, falcon: falconKey}
+	switch alg {
+	case Falcon512Ed25519:
+		key, ok := trad.(ed25519.PublicKey)
+		if !ok || len(key) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+		pub.ed25519 = key
+	case Falcon512P256:
+		key, ok := trad.(*ecdsa.PublicKey)
+		if !ok || key.Curve != elliptic.P256() {
+			return nil, ErrInvalidKey
+		}
+		pub.ecdsa = key
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return pub, nil
+}
+
+// Algorithm returns the composite algorithm of the key.
+func (pub *PublicKey) Algorithm() Algorithm {
+	return pub.alg
+}
+
+// Falcon returns the Falcon component of the key.
+func (pub *PublicKey) Falcon() *falcon.PublicKey {
+	return pub.falcon
+}
+
+// Traditional returns the traditional component of the key, an
+// ed25519.PublicKey or a *ecdsa.PublicKey.
+func (pub *PublicKey) Traditional() crypto.PublicKey {
+	if pub.alg == Falcon512Ed25519 {
+		return pub.ed25519
+	}
+	return pub.ecdsa
+}
+
+// Equal reports whether pub and x are the same composite public key.
+func (pub *PublicKey) Equal(x crypto.PublicKey) bool {
+	other, ok := x.(*PublicKey)
+	if !ok || other == nil || pub.alg != other.alg || !pub.falcon.Equal(other.falcon) {
+		return false
+	}
+	if pub.alg == Falcon512Ed25519 {
+		return pub.ed25519.Equal(other.ed25519)
+	}
+	return pub.ecdsa.Equal(other.ecdsa)
+}
+
+// Algorithm returns the composite algorithm of the key.
+func (priv *PrivateKey) Algorithm() Algorithm {
+	return priv.alg
+}
+
+// Public returns the composite public key, as a *PublicKey.
+func (priv *PrivateKey) Public() crypto.PublicKey {
+	return priv.PublicKey()
+}
+
+// PublicKey returns the composite public key.
+func (priv *PrivateKey) PublicKey() *PublicKey {
+	pub := &PublicKey{alg: priv.alg, falcon: priv.falconPub}
+	if priv.alg == Falcon512Ed25519 {
+		pub.ed25519 = priv.ed25519.Public().(ed25519.PublicKey)
+	} else {
+		pub.ecdsa = &priv.ecdsa.PublicKey
+	}
+	return pub
+}
+
+// MarshalPublicKey encodes a composite public key.
+func MarshalPublicKey(pub *PublicKey) ([]byte, error) {
+	out, err := falcon.MarshalPublicKey(pub.falcon)
+	if err != nil {
+		return nil, err
+	}
+	switch pub.alg {
+	case Falcon512Ed25519:
+		return append(out, pub.ed25519...), nil
+	case Falcon512P256:
+		return append(out, elliptic.Marshal(elliptic.P256(), pub.ecdsa.X, pub.ecdsa.Y)...), nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// ParsePublicKey decodes a composite public key of algorithm alg.
+func ParsePublicKey(alg Algorithm, data []byte) (*PublicKey, error) {
+	if _, ok := algorithms[alg]; !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(data) < falconPublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	falconKey, err := falcon.ParsePublicKey(data[:falconPublicKeySize])
+	if err != nil || falconKey.Degree() != falconDegree {
+		return nil, ErrInvalidKey
+	}
+	trad := data[falconPublicKeySize:]
+	switch alg {
+	case Falcon512Ed25519:
+		if len(trad) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+		return NewPublicKey(alg, falconKey, ed25519.PublicKey(append([]byte(nil), trad...)))
+	default:
+		x, y := elliptic.Unmarshal(elliptic.P256(), trad)
+		if x == nil {
+			return nil, ErrInvalidKey
+		}
+		return NewPublicKey(alg, falconKey, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	}
+}
+
+// MarshalPrivateKey encodes a composite private key.
+func MarshalPrivateKey(priv *PrivateKey) ([]byte, error) {
+	out, err := falcon.MarshalPrivateKey(priv.falcon)
+	if err != nil {
+		return nil, err
+	}
+	switch priv.alg {
+	case Falcon512Ed25519:
+		return append(out, priv.ed25519.Seed()...), nil
+	case Falcon512P256:
+		der, err := x509.MarshalECPrivateKey(priv.ecdsa)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, der...), nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// ParsePrivateKey decodes a composite private key of algorithm alg.
+func ParsePrivateKey(alg Algorithm, data []byte) (*PrivateKey, error) {
+	if _, ok := algorithms[alg]; !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if len(data) < falconPrivateKeySize {
+		return nil, ErrInvalidKey
+	}
+	falconKey, err := falcon.ParsePrivateKey(data[:falconPrivateKeySize])
+	if err != nil || falconKey.Degree() != falconDegree {
+		return nil, ErrInvalidKey
+	}
+	trad := data[falconPrivateKeySize:]
+	switch alg {
+	case Falcon512Ed25519:
+		if len(trad) != ed25519.SeedSize {
+			return nil, ErrInvalidKey
+		}
+		return NewPrivateKey(alg, falconKey, ed25519.NewKeyFromSeed(trad))
+	default:
+		key, err := x509.ParseECPrivateKey(trad)
+		if err != nil {
+			return nil, ErrInvalidKey
+		}
+		return NewPrivateKey(alg, falconKey, key)
+	}
+}
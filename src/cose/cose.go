@@ -0,0 +1,204 @@
+// Package cose implements COSE_Sign1 and COSE_Sign messages (RFC 9052) and
+// COSE_Key representations of Falcon keys.
+//
+// Signatures are pure FN-DSA signatures with an empty context over the
+// Sig_structure of RFC 9052, section 4.4. Keys use the "AKP" (algorithm key
+// pair) key type of the COSE post-quantum drafts, with the public key encoding
+// of falcon.MarshalPublicKey and the private key encoding of
+// falcon.MarshalPrivateKey.
+//
+// The FN-DSA algorithms are not registered with IANA yet: AlgFNDSA512 and
+// AlgFNDSA1024 are taken from the private-use range and will change once
+// values are assigned.
+package cose
+
+import (
+	"errors"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/internal/cbor"
+)
+
+const (
+	// AlgFNDSA512 identifies Falcon-512 signatures.
+	AlgFNDSA512 int64 = -65537
+	// AlgFNDSA1024 identifies Falcon-1024 signatures.
+	AlgFNDSA1024 int64 = -65538
+)
+
+// Common header parameter labels (RFC 9052, section 3.1).
+const (
+	headerAlgorithm   int64 = 1
+	headerCritical    int64 = 2
+	headerContentType int64 = 3
+	headerKeyID       int64 = 4
+)
+
+// CBOR tags of the messages.
+const (
+	tagSign1 = 18
+	tagSign  = 98
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned when an algorithm or key type is not a Falcon one
+	ErrUnsupportedAlgorithm = errors.New("cose: unsupported algorithm")
+	// ErrInvalidMessage is returned when a message cannot be decoded
+	ErrInvalidMessage = errors.New("cose: malformed message")
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("cose: invalid signature")
+	// ErrUnsupportedCritical is returned when a protected header has critical parameters
+	ErrUnsupportedCritical = errors.New("cose: unsupported critical header parameter")
+	// ErrDetachedPayload is returned when a detached payload is missing
+	ErrDetachedPayload = errors.New("cose: missing detached payload")
+)
+
+// Algorithm returns the algorithm identifier of Falcon keys of degree n.
+func Algorithm(n uint16) (int64, error) {
+	switch n {
+	case 512:
+		return AlgFNDSA512, nil
+	case 1024:
+		return AlgFNDSA1024, nil
+	}
+	return 0, ErrUnsupportedAlgorithm
+}
+
+// degree returns the degree of the keys of an algorithm identifier.
+func degree(alg int64) (uint16, error) {
+	switch alg {
+	case AlgFNDSA512:
+		return 512, nil
+	case AlgFNDSA1024:
+		return 1024, nil
+	}
+	return 0, ErrUnsupportedAlgorithm
+}
+
+// Header holds the common header parameters of a header bucket.
+// The zero value of a field leaves the parameter out.
+type Header struct {
+	Algorithm   int64
+	KeyID       []byte
+	ContentType string
+}
+
+// Headers are the protected and unprotected header buckets of a message or
+// signature. Signing sets the algorithm in the protected bucket.
+type Headers struct {
+	Protected   Header
+	Unprotected Header
+}
+
+func (header *Header) toMap() cbor.Map {
+	m := cbor.Map{}
+	if header.Algorithm != 0 {
+		m = append(m, cbor.Entry{Key: headerAlgorithm, Value: header.Algorithm})
+	}
+	if header.ContentType != "" {
+		m = append(m, cbor.Entry{Key: headerContentType, Value: header.ContentType})
+	}
+	if len(header.KeyID) > 0 {
+		m = append(m, cbor.Entry{Key: headerKeyID, Value: header.KeyID})
+	}
+	return m
+}
+
+// fromMap reads the common parameters of a header map; other parameters are ignored.
+func (header *Header) fromMap(m cbor.Map) error {
+	var ok bool
+	if v, present := m.Get(headerAlgorithm); present {
+		// text algorithm names are not used by Falcon
+		if header.Algorithm, ok = v.(int64); !ok {
+			return ErrUnsupportedAlgorithm
+		}
+	}
+	if v, present := m.Get(headerContentType); present {
+		if header.ContentType, ok = v.(string); !ok {
+			return ErrInvalidMessage
+		}
+	}
+	if v, present := m.Get(headerKeyID); present {
+		if header.KeyID, ok = v.([]byte); !ok {
+			return ErrInvalidMessage
+		}
+	}
+	return nil
+}
+
+// encodeProtected encodes a protected header bucket as a bstr content; an
+// empty bucket is encoded as a zero-length string.
+func encodeProtected(header *Header) ([]byte, error) {
+	m := header.toMap()
+	if len(m) == 0 {
+		return []byte{}, nil
+	}
+	return cbor.Marshal(m)
+}
+
+// decodeHeaders decodes the protected bstr and the unprotected map of a
+// message or signature.
+func decodeHeaders(protected, unprotected any) (Headers, []byte, error) {
+	var headers Headers
+	rawProtected, ok := protected.([]byte)
+	unprotectedMap, ok2 := unprotected.(cbor.Map)
+	if !ok || !ok2 {
+		return headers, nil, ErrInvalidMessage
+	}
+	if len(rawProtected) > 0 {
+		v, err := cbor.Unmarshal(rawProtected)
+		if err != nil {
+			return headers, nil, ErrInvalidMessage
+		}
+		protectedMap, ok := v.(cbor.Map)
+		if !ok {
+			return headers, nil, ErrInvalidMessage
+		}
+		if _, present := protectedMap.Get(headerCritical); present {
+			return headers, nil, ErrUnsupportedCritical
+		}
+		if err := headers.Protected.fromMap(protectedMap); err != nil {
+			return headers, nil, err
+		}
+	}
+	if err := headers.Unprotected.fromMap(unprotectedMap); err != nil {
+		return headers, nil, err
+	}
+	return headers, rawProtected, nil
+}
+
+// algorithm returns the algorithm of a header pair, preferring the protected bucket.
+func (headers *Headers) algorithm() int64 {
+	if headers.Protected.Algorithm != 0 {
+		return headers.Protected.Algorithm
+	}
+	return headers.Unprotected.Algorithm
+}
+
+// signBytes signs a Sig_structure with privKey.
+func signBytes(privKey *falcon.PrivateKey, sigStructure []any) ([]byte, error) {
+	toBeSigned, err := cbor.Marshal(sigStructure)
+	if err != nil {
+		return nil, err
+	}
+	return privKey.SignFNDSA(toBeSigned, nil)
+}
+
+// verifyBytes verifies a signature of a Sig_structure made with alg.
+func verifyBytes(pubKey *falcon.PublicKey, alg int64, sigStructure []any, signature []byte) error {
+	n, err := degree(alg)
+	if err != nil {
+		return err
+	}
+	if n != pubKey.Degree() {
+		return ErrInvalidSignature
+	}
+	toBeSigned, err := cbor.Marshal(sigStructure)
+	if err != nil {
+		return err
+	}
+	if pubKey.VerifyFNDSA(toBeSigned, signature, nil) != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cose
+
+import (
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/internal/cbor"
+)
+
+// KeyTypeAKP is the COSE key type of algorithm key pairs.
+const KeyTypeAKP int64 = 7
+
+// COSE_Key parameter labels: common parameters (RFC 9052, section 7.1) and
+// AKP parameters.
+const (
+	keyLabelKty     int64 = 1
+	keyLabelKid     int64 = 2
+	keyLabelAlg     int64 = 3
+	keyLabelPublic  int64 = -1
+	keyLabelPrivate int64 = -2
+)
+
+// Key is a COSE_Key holding a Falcon public key, and optionally the matching
+// private key.
+type Key struct {
+	KeyID     []byte
+	Algorithm int64
+	Public    []byte
+	Private   []byte
+}
+
+// NewKey returns the COSE_Key of a public key.
+func NewKey(pubKey *falcon.PublicKey) (*Key, error) {
+	alg, err := Algorithm(pubKey.Degree())
+	if err != nil {
+		return nil, err
+	}
+	pub, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Key{Algorithm: alg, Public: pub}, nil
+}
+
+// NewPrivateKey returns the COSE_Key of a private key, which includes its public key.
+func NewPrivateKey(privKey *falcon.PrivateKey) (*Key, error) {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	key, err := NewKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if key.Private, err = falcon.MarshalPrivateKey(privKey); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PublicKey decodes the public key.
+func (key *Key) PublicKey() (*falcon.PublicKey, error) {
+	n, err := degree(key.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := falcon.ParsePublicKey(key.Public)
+	if err != nil {
+		return nil, err
+	}
+	if pubKey.Degree() != n {
+		return nil, falcon.ErrInvalidKeyEncoding
+	}
+	return pubKey, nil
+}
+
+// PrivateKey decodes the private key and checks that it matches the public key.
+func (key *Key) PrivateKey() (*falcon.PrivateKey, error) {
+	n, err := degree(key.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.Private) == 0 {
+		return nil, falcon.ErrInvalidKeyEncoding
+	}
+	privKey, err := falcon.ParsePrivateKey(key.Private)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, falcon.ErrInvalidKeyEncoding
+	}
+	pub, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil || privKey.Degree() != n || string(pub) != string(key.Public) {
+		return nil, falcon.ErrInvalidKeyEncoding
+	}
+	return privKey, nil
+}
+
+// MarshalCBOR encodes the key as a COSE_Key map.
+func (key *Key) MarshalCBOR() ([]byte, error) {
+	if _, err := degree(key.Algorithm); err != nil {
+		return nil, err
+	}
+	m := cbor.Map{
+		{Key: keyLabelKty, Value: KeyTypeAKP},
+		{Key: keyLabelAlg, Value: key.Algorithm},
+		{Key: keyLabelPublic, Value: key.Public},
+	}
+	if len(key.KeyID) > 0 {
+		m = append(m, cbor.Entry{Key: keyLabelKid, Value: key.KeyID})
+	}
+	if len(key.Private) > 0 {
+		m = append(m, cbor.Entry{Key: keyLabelPrivate, Value: key.Private})
+	}
+	return cbor.Marshal(m)
+}
+
+// ParseKey decodes a COSE_Key map and checks its keys.
+func ParseKey(data []byte) (*Key, error) {
+	v, err := cbor.Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(cbor.Map)
+	if !ok {
+		return nil, ErrInvalidMessage
+	}
+	if kty, _ := m.Get(keyLabelKty); kty != KeyTypeAKP {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	key := &Key{}
+	alg, _ := m.Get(keyLabelAlg)
+	if key.Algorithm, ok = alg.(int64); !ok {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	pub, _ := m.Get(keyLabelPublic)
+	if key.Public, ok = pub.([]byte); !ok {
+		return nil, ErrInvalidMessage
+	}
+	if kid, present := m.Get(keyLabelKid); present {
+		if key.KeyID, ok = kid.([]byte); !ok {
+			return nil, ErrInvalidMessage
+		}
+	}
+	if _, err := key.PublicKey(); err != nil {
+		return nil, err
+	}
+	if priv, present := m.Get(keyLabelPrivate); present {
+		if key.Private, ok = priv.([]byte); !ok {
+			return nil, ErrInvalidMessage
+		}
+		if _, err := key.PrivateKey(); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
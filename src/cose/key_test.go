@@ -0,0 +1,95 @@
+package cose
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/internal/cbor"
+)
+
+func TestKey(t *testing.T) {
+	data := readHex(t, "falcon512_private_key.hex")
+	key, err := ParseKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.Algorithm != AlgFNDSA512 || string(key.KeyID) != "falcon512-test" || len(key.Public) != 897 || len(key.Private) != 1281 {
+		t.Errorf("unexpected key fields")
+	}
+	encoded, err := key.MarshalCBOR()
+	if err != nil || !bytes.Equal(encoded, data) {
+		t.Errorf("MarshalCBOR does not reproduce the vector: %v", err)
+	}
+	// {1: 7, 2: 'falcon512-test', 3: -65537, -1: h'09...'
+	if !bytes.HasPrefix(data, append(append([]byte{0xa5, 0x01, 0x07, 0x02, 0x4e}, "falcon512-test"...), 0x03, 0x3a, 0x00, 0x01, 0x00, 0x00, 0x20, 0x59, 0x03, 0x81, 0x09)) {
+		t.Errorf("unexpected COSE_Key encoding %x", data[:32])
+	}
+
+	privKey, err := key.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemKey, _ := falcon.ParsePrivateKeyPEM(mustRead(t, "../testdata/falcon512_priv.pem"))
+	if !reflect.DeepEqual(privKey, pemKey) {
+		t.Errorf("COSE_Key private key differs from the PEM key")
+	}
+
+	public, err := NewKey(privKey.GetPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicData, _ := public.MarshalCBOR()
+	parsed, err := ParseKey(publicData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pubKey, err := parsed.PublicKey(); err != nil || !reflect.DeepEqual(pubKey, privKey.GetPublicKey()) {
+		t.Errorf("public COSE_Key does not round-trip: %v", err)
+	}
+	if _, err := parsed.PrivateKey(); err == nil {
+		t.Errorf("PrivateKey of a public COSE_Key succeeded")
+	}
+}
+
+func TestParseKeyInvalid(t *testing.T) {
+	key, _ := ParseKey(readHex(t, "falcon512_private_key.hex"))
+	testCases := []cbor.Map{
+		{{Key: keyLabelKty, Value: int64(1)}, {Key: keyLabelAlg, Value: AlgFNDSA512}, {Key: keyLabelPublic, Value: key.Public}},
+		{{Key: keyLabelKty, Value: KeyTypeAKP}, {Key: keyLabelAlg, Value: AlgFNDSA1024}, {Key: keyLabelPublic, Value: key.Public}},
+		{{Key: keyLabelKty, Value: KeyTypeAKP}, {Key: keyLabelAlg, Value: AlgFNDSA512}, {Key: keyLabelPublic, Value: key.Public[:100]}},
+		{{Key: keyLabelKty, Value: KeyTypeAKP}, {Key: keyLabelAlg, Value: AlgFNDSA512}, {Key: keyLabelPublic, Value: "text"}},
+		{{Key: keyLabelKty, Value: KeyTypeAKP}, {Key: keyLabelAlg, Value: AlgFNDSA512}, {Key: keyLabelPublic, Value: key.Public}, {Key: keyLabelPrivate, Value: key.Private[:1280]}},
+	}
+	for i, m := range testCases {
+		data, _ := cbor.Marshal(m)
+		if _, err := ParseKey(data); err == nil {
+			t.Errorf("case %d: invalid key was parsed", i)
+		}
+	}
+	// a private key that does not match the public key
+	other, _ := NewKey(mustParsePublicKeyPEM(t, "../testdata/falcon1024_pub.pem"))
+	other.Algorithm, other.Private = AlgFNDSA1024, key.Private
+	data, _ := other.MarshalCBOR()
+	if _, err := ParseKey(data); err == nil {
+		t.Errorf("mismatched key pair was parsed")
+	}
+}
+
+func mustRead(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func mustParsePublicKeyPEM(t *testing.T, name string) *falcon.PublicKey {
+	pubKey, err := falcon.ParsePublicKeyPEM(mustRead(t, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pubKey
+}
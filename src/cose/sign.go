@@ -0,0 +1,267 @@
+package cose
+
+import (
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/internal/cbor"
+)
+
+// payloadValue returns the payload item of a message: nil (null) when detached.
+func payloadValue(payload []byte, detached bool) any {
+	if detached {
+		return nil
+	}
+	return payload
+}
+
+// decodePayload decodes the payload item of a message.
+func decodePayload(v any) ([]byte, bool, error) {
+	switch payload := v.(type) {
+	case nil:
+		return nil, true, nil
+	case []byte:
+		return payload, false, nil
+	}
+	return nil, false, ErrInvalidMessage
+}
+
+// decodeMessage decodes a message with the given tag and array length. The
+// tag is optional.
+func decodeMessage(data []byte, tag uint64, length int) ([]any, error) {
+	v, err := cbor.Unmarshal(data)
+	if err != nil {
+		return nil, ErrInvalidMessage
+	}
+	if tagged, ok := v.(cbor.Tag); ok {
+		if tagged.Number != tag {
+			return nil, ErrInvalidMessage
+		}
+		v = tagged.Content
+	}
+	array, ok := v.([]any)
+	if !ok || len(array) != length {
+		return nil, ErrInvalidMessage
+	}
+	return array, nil
+}
+
+// Sign1Message is a COSE_Sign1 message: a payload with a single signature.
+type Sign1Message struct {
+	Headers
+	Payload []byte
+	// Detached leaves the payload out of the encoded message. A decoded
+	// message with a detached payload needs Payload to be set before Verify.
+	Detached  bool
+	Signature []byte
+
+	rawProtected []byte
+}
+
+func (msg *Sign1Message) sigStructure(externalAAD []byte) []any {
+	if externalAAD == nil {
+		externalAAD = []byte{}
+	}
+	return []any{"Signature1", msg.rawProtected, externalAAD, msg.Payload}
+}
+
+// Sign sets the algorithm of privKey in the protected header and signs the
+// message with the external additional authenticated data externalAAD.
+func (msg *Sign1Message) Sign(privKey *falcon.PrivateKey, externalAAD []byte) error {
+	alg, err := Algorithm(privKey.Degree())
+	if err != nil {
+		return err
+	}
+	msg.Protected.Algorithm = alg
+	if msg.rawProtected, err = encodeProtected(&msg.Protected); err != nil {
+		return err
+	}
+	if msg.Payload == nil {
+		msg.Payload = []byte{}
+	}
+	msg.Signature, err = signBytes(privKey, msg.sigStructure(externalAAD))
+	return err
+}
+
+// Verify verifies the signature of the message under pubKey with the
+// external additional authenticated data externalAAD.
+func (msg *Sign1Message) Verify(pubKey *falcon.PublicKey, externalAAD []byte) error {
+	if msg.Payload == nil {
+		return ErrDetachedPayload
+	}
+	if msg.rawProtected == nil {
+		return ErrInvalidSignature
+	}
+	return verifyBytes(pubKey, msg.algorithm(), msg.sigStructure(externalAAD), msg.Signature)
+}
+
+// MarshalCBOR encodes the signed message as a tagged COSE_Sign1.
+func (msg *Sign1Message) MarshalCBOR() ([]byte, error) {
+	if msg.rawProtected == nil {
+		return nil, ErrInvalidSignature
+	}
+	return cbor.Marshal(cbor.Tag{Number: tagSign1, Content: []any{
+		msg.rawProtected, msg.Unprotected.toMap(), payloadValue(msg.Payload, msg.Detached), msg.Signature,
+	}})
+}
+
+// ParseSign1 decodes a tagged or untagged COSE_Sign1 message. The signature is
+// not checked, see Verify.
+func ParseSign1(data []byte) (*Sign1Message, error) {
+	array, err := decodeMessage(data, tagSign1, 4)
+	if err != nil {
+		return nil, err
+	}
+	msg := &Sign1Message{}
+	if msg.Headers, msg.rawProtected, err = decodeHeaders(array[0], array[1]); err != nil {
+		return nil, err
+	}
+	if msg.Payload, msg.Detached, err = decodePayload(array[2]); err != nil {
+		return nil, err
+	}
+	var ok bool
+	if msg.Signature, ok = array[3].([]byte); !ok {
+		return nil, ErrInvalidMessage
+	}
+	return msg, nil
+}
+
+// Signature is one signature of a COSE_Sign message.
+type Signature struct {
+	Headers
+	Signature []byte
+
+	rawProtected []byte
+}
+
+// Signer is a key and the headers of one signature of a COSE_Sign message.
+type Signer struct {
+	Key     *falcon.PrivateKey
+	Headers Headers
+}
+
+// SignMessage is a COSE_Sign message: a payload with one or more signatures.
+type SignMessage struct {
+	Headers
+	Payload []byte
+	// Detached leaves the payload out of the encoded message, see Sign1Message.
+	Detached   bool
+	Signatures []Signature
+
+	rawProtected []byte
+}
+
+func (msg *SignMessage) sigStructure(signature *Signature, externalAAD []byte) []any {
+	if externalAAD == nil {
+		externalAAD = []byte{}
+	}
+	return []any{"Signature", msg.rawProtected, signature.rawProtected, externalAAD, msg.Payload}
+}
+
+// Sign replaces the signatures of the message with one signature per signer,
+// with the external additional authenticated data externalAAD. The body
+// headers are fixed by the first call.
+func (msg *SignMessage) Sign(externalAAD []byte, signers ...Signer) error {
+	if len(signers) == 0 {
+		return ErrInvalidMessage
+	}
+	var err error
+	if msg.rawProtected, err = encodeProtected(&msg.Protected); err != nil {
+		return err
+	}
+	if msg.Payload == nil {
+		msg.Payload = []byte{}
+	}
+	signatures := make([]Signature, len(signers))
+	for i, signer := range signers {
+		signature := &signatures[i]
+		signature.Headers = signer.Headers
+		if signature.Protected.Algorithm, err = Algorithm(signer.Key.Degree()); err != nil {
+			return err
+		}
+		if signature.rawProtected, err = encodeProtected(&signature.Protected); err != nil {
+			return err
+		}
+		if signature.Signature, err = signBytes(signer.Key, msg.sigStructure(signature, externalAAD)); err != nil {
+			return err
+		}
+	}
+	msg.Signatures = signatures
+	return nil
+}
+
+// VerifySignature verifies the i-th signature of the message under pubKey.
+func (msg *SignMessage) VerifySignature(i int, pubKey *falcon.PublicKey, externalAAD []byte) error {
+	if msg.Payload == nil {
+		return ErrDetachedPayload
+	}
+	if i < 0 || i >= len(msg.Signatures) || msg.rawProtected == nil {
+		return ErrInvalidSignature
+	}
+	signature := &msg.Signatures[i]
+	return verifyBytes(pubKey, signature.algorithm(), msg.sigStructure(signature, externalAAD), signature.Signature)
+}
+
+// Verify reports whether any signature of the message verifies under pubKey.
+// It returns the index of the first such signature.
+func (msg *SignMessage) Verify(pubKey *falcon.PublicKey, externalAAD []byte) (int, error) {
+	err := ErrInvalidSignature
+	for i := range msg.Signatures {
+		e := msg.VerifySignature(i, pubKey, externalAAD)
+		if e == nil {
+			return i, nil
+		}
+		if e == ErrDetachedPayload {
+			err = e
+		}
+	}
+	return -1, err
+}
+
+// MarshalCBOR encodes the signed message as a tagged COSE_Sign.
+func (msg *SignMessage) MarshalCBOR() ([]byte, error) {
+	if msg.rawProtected == nil || len(msg.Signatures) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	signatures := make([]any, len(msg.Signatures))
+	for i := range msg.Signatures {
+		signature := &msg.Signatures[i]
+		signatures[i] = []any{signature.rawProtected, signature.Unprotected.toMap(), signature.Signature}
+	}
+	return cbor.Marshal(cbor.Tag{Number: tagSign, Content: []any{
+		msg.rawProtected, msg.Unprotected.toMap(), payloadValue(msg.Payload, msg.Detached), signatures,
+	}})
+}
+
+// ParseSign decodes a tagged or untagged COSE_Sign message. The signatures
+// are not checked, see Verify.
+func ParseSign(data []byte) (*SignMessage, error) {
+	array, err := decodeMessage(data, tagSign, 4)
+	if err != nil {
+		return nil, err
+	}
+	msg := &SignMessage{}
+	if msg.Headers, msg.rawProtected, err = decodeHeaders(array[0], array[1]); err != nil {
+		return nil, err
+	}
+	if msg.Payload, msg.Detached, err = decodePayload(array[2]); err != nil {
+		return nil, err
+	}
+	signatures, ok := array[3].([]any)
+	if !ok || len(signatures) == 0 {
+		return nil, ErrInvalidMessage
+	}
+	for _, item := range signatures {
+		fields, ok := item.([]any)
+		if !ok || len(fields) != 3 {
+			return nil, ErrInvalidMessage
+		}
+		var signature Signature
+		if signature.Headers, signature.rawProtected, err = decodeHeaders(fields[0], fields[1]); err != nil {
+			return nil, err
+		}
+		if signature.Signature, ok = fields[2].([]byte); !ok {
+			return nil, ErrInvalidMessage
+		}
+		msg.Signatures = append(msg.Signatures, signature)
+	}
+	return msg, nil
+}
@@ -0,0 +1,188 @@
+package cose
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/internal/cbor"
+)
+
+// The vectors in testdata are made with the Falcon-512 key of
+// ../testdata/falcon512_priv.pem.
+
+func readHex(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func loadKeys(t *testing.T) (*falcon.PrivateKey, *falcon.PublicKey) {
+	key, err := ParseKey(readHex(t, "falcon512_private_key.hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := key.PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privKey, privKey.GetPublicKey()
+}
+
+func TestSigStructure(t *testing.T) {
+	msg := &Sign1Message{Payload: []byte("This is the content.")}
+	msg.Protected.Algorithm = AlgFNDSA512
+	msg.rawProtected, _ = encodeProtected(&msg.Protected)
+	got, err := cbor.Marshal(msg.sigStructure(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ["Signature1", << {1: -65537} >>, h'', 'This is the content.']
+	want := "846a5369676e61747572653147a1013a0001000040" + "54" + hex.EncodeToString([]byte("This is the content."))
+	if hex.EncodeToString(got) != want {
+		t.Errorf("Sig_structure = %x, want %s", got, want)
+	}
+}
+
+func TestVerifySign1Vector(t *testing.T) {
+	_, pubKey := loadKeys(t)
+	data := readHex(t, "sign1.hex")
+	msg, err := ParseSign1(data)
+	if err != nil {
+		t.Fatalf("ParseSign1 returned %v", err)
+	}
+	if err := msg.Verify(pubKey, []byte("external")); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+	if string(msg.Payload) != "This is the content." || msg.Protected.Algorithm != AlgFNDSA512 ||
+		msg.Protected.ContentType != "text/plain" || string(msg.Unprotected.KeyID) != "falcon512-test" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if err := msg.Verify(pubKey, nil); err != ErrInvalidSignature {
+		t.Errorf("Verify without the external data returned %v", err)
+	}
+	encoded, err := msg.MarshalCBOR()
+	if err != nil || !bytes.Equal(encoded, data) {
+		t.Errorf("MarshalCBOR does not reproduce the vector: %v", err)
+	}
+
+	// the payload is covered by the signature
+	msg.Payload = []byte("This is another content.")
+	if err := msg.Verify(pubKey, []byte("external")); err != ErrInvalidSignature {
+		t.Errorf("Verify of a modified payload returned %v", err)
+	}
+}
+
+func TestSign1Detached(t *testing.T) {
+	privKey, pubKey := loadKeys(t)
+	msg := &Sign1Message{Payload: []byte("detached content"), Detached: true}
+	if err := msg.Sign(privKey, nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := msg.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("detached content")) {
+		t.Errorf("detached payload was encoded")
+	}
+	parsed, err := ParseSign1(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.Detached || parsed.Verify(pubKey, nil) != ErrDetachedPayload {
+		t.Errorf("missing detached payload was not reported")
+	}
+	parsed.Payload = []byte("detached content")
+	if err := parsed.Verify(pubKey, nil); err != nil {
+		t.Errorf("Verify with the detached payload returned %v", err)
+	}
+}
+
+func TestVerifySignVector(t *testing.T) {
+	_, pubKey := loadKeys(t)
+	msg, err := ParseSign(readHex(t, "sign.hex"))
+	if err != nil {
+		t.Fatalf("ParseSign returned %v", err)
+	}
+	if len(msg.Signatures) != 2 || string(msg.Signatures[1].Unprotected.KeyID) != "second" {
+		t.Fatalf("unexpected signatures")
+	}
+	for i := range msg.Signatures {
+		if err := msg.VerifySignature(i, pubKey, nil); err != nil {
+			t.Errorf("signature %d: VerifySignature returned %v", i, err)
+		}
+	}
+	if i, err := msg.Verify(pubKey, nil); i != 0 || err != nil {
+		t.Errorf("Verify returned %d, %v", i, err)
+	}
+
+	// a signature cannot be moved to a COSE_Sign1 message
+	sign1 := &Sign1Message{Payload: msg.Payload, Signature: msg.Signatures[0].Signature, rawProtected: msg.Signatures[0].rawProtected}
+	sign1.Headers = msg.Signatures[0].Headers
+	if err := sign1.Verify(pubKey, nil); err != ErrInvalidSignature {
+		t.Errorf("COSE_Sign signature verified as COSE_Sign1: %v", err)
+	}
+}
+
+func TestSignMessage(t *testing.T) {
+	privKey, pubKey := loadKeys(t)
+	otherKey, otherPub, err := falcon.NewKeyPair(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &SignMessage{Payload: []byte("payload")}
+	msg.Protected.ContentType = "application/cbor"
+	if err := msg.Sign([]byte("aad"), Signer{Key: otherKey}, Signer{Key: privKey}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := msg.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseSign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err := parsed.Verify(pubKey, []byte("aad")); i != 1 || err != nil {
+		t.Errorf("Verify(pubKey) returned %d, %v", i, err)
+	}
+	if i, err := parsed.Verify(otherPub, []byte("aad")); i != 0 || err != nil {
+		t.Errorf("Verify(otherPub) returned %d, %v", i, err)
+	}
+	if _, err := parsed.Verify(pubKey, nil); err != ErrInvalidSignature {
+		t.Errorf("Verify without external data returned %v", err)
+	}
+	if parsed.Protected.ContentType != "application/cbor" {
+		t.Errorf("body header was not decoded")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	data := readHex(t, "sign1.hex")
+	if _, err := ParseSign(data); err != ErrInvalidMessage {
+		t.Errorf("ParseSign of a COSE_Sign1 returned %v", err)
+	}
+	if _, err := ParseSign1(data[:len(data)-1]); err != ErrInvalidMessage {
+		t.Errorf("truncated message returned %v", err)
+	}
+	// untagged messages are accepted
+	if _, err := ParseSign1(data[1:]); err != nil {
+		t.Errorf("untagged message returned %v", err)
+	}
+
+	critical, _ := cbor.Marshal(cbor.Map{{Key: headerAlgorithm, Value: AlgFNDSA512}, {Key: headerCritical, Value: []any{int64(99)}}})
+	message, _ := cbor.Marshal([]any{critical, cbor.Map{}, []byte("x"), []byte("sig")})
+	if _, err := ParseSign1(message); err != ErrUnsupportedCritical {
+		t.Errorf("critical header returned %v, want %v", err, ErrUnsupportedCritical)
+	}
+}
@@ -0,0 +1,214 @@
+package falcon
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"time"
+)
+
+/*
+X.509 v2 certificate revocation lists (RFC 5280, section 5) signed with a
+Falcon CA key.
+*/
+
+const pemTypeCRL = "X509 CRL"
+
+var oidExtensionCRLNumber = asn1.ObjectIdentifier{2, 5, 29, 20}
+
+var (
+	// ErrInvalidCRL is returned when a revocation list cannot be parsed
+	ErrInvalidCRL = errors.New("malformed certificate revocation list")
+)
+
+// RevocationList is an X.509 v2 certificate revocation list.
+type RevocationList struct {
+	Raw                  []byte
+	RawTBSRevocationList []byte
+	RawIssuer            []byte
+
+	Issuer             pkix.Name
+	SignatureAlgorithm asn1.ObjectIdentifier
+	Signature          []byte
+
+	RevokedCertificates []pkix.RevokedCertificate
+	// Number is the CRL number; it must increase with every new list of an issuer.
+	Number     *big.Int
+	ThisUpdate time.Time
+	NextUpdate time.Time
+
+	AuthorityKeyId []byte
+
+	// Extensions are the list extensions. ExtraExtensions are added as is to
+	// a new list; they are not filled by ParseRevocationList.
+	Extensions      []pkix.Extension
+	ExtraExtensions []pkix.Extension
+}
+
+type tbsCertList struct {
+	Raw                 asn1.RawContent
+	Version             int `asn1:"optional,default:0"`
+	Signature           algorithmIdentifier
+	Issuer              asn1.RawValue
+	ThisUpdate          time.Time
+	NextUpdate          time.Time                 `asn1:"optional"`
+	RevokedCertificates []pkix.RevokedCertificate `asn1:"optional"`
+	Extensions          []pkix.Extension          `asn1:"tag:0,optional,explicit"`
+}
+
+type certList struct {
+	TBSCertList        tbsCertList
+	SignatureAlgorithm algorithmIdentifier
+	SignatureValue     asn1.BitString
+}
+
+// CreateRevocationList creates a revocation list described by template,
+// signed with privKey, the key of issuer. The template fields
+// RevokedCertificates, Number, ThisUpdate, NextUpdate and ExtraExtensions are
+// used. issuer must be allowed to sign CRLs. It returns the DER list.
+// random defaults to crypto/rand.Reader.
+func CreateRevocationList(random io.Reader, template *RevocationList, issuer *Certificate, privKey *PrivateKey) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	oid, err := OIDFromDegree(privKey.n)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return nil, ErrIncompatibleUsage
+	}
+	signerKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if issuer.PublicKey == nil || issuer.PublicKey.n != privKey.n || !equalInt16(issuer.PublicKey.h, signerKey.h) {
+		return nil, ErrKeyMismatch
+	}
+	if template.Number == nil || template.Number.Sign() < 0 || len(template.Number.Bytes()) > 20 {
+		return nil, ErrInvalidSerialNumber
+	}
+	if !template.NextUpdate.After(template.ThisUpdate) {
+		return nil, ErrInvalidValidity
+	}
+	rawIssuer, err := marshalName(issuer.RawSubject, issuer.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var extensions []pkix.Extension
+	if len(issuer.SubjectKeyId) > 0 && !hasExtension(oidExtensionAuthorityKeyId, template.ExtraExtensions) {
+		value, err := asn1.Marshal(struct {
+			KeyIdentifier []byte `asn1:"optional,tag:0"`
+		}{issuer.SubjectKeyId})
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionAuthorityKeyId, Value: value})
+	}
+	if !hasExtension(oidExtensionCRLNumber, template.ExtraExtensions) {
+		value, err := asn1.Marshal(template.Number)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionCRLNumber, Value: value})
+	}
+	extensions = append(extensions, template.ExtraExtensions...)
+
+	// An empty list is omitted, as RFC 5280, section 5.1.2.6 requires:
+	// encoding/asn1 only omits nil slices
+	var revoked []pkix.RevokedCertificate
+	for _, rc := range template.RevokedCertificates {
+		rc.RevocationTime = rc.RevocationTime.UTC().Truncate(time.Second)
+		revoked = append(revoked, rc)
+	}
+
+	tbs, err := asn1.Marshal(tbsCertList{
+		Version:             1,
+		Signature:           algorithmIdentifier{oid},
+		Issuer:              asn1.RawValue{FullBytes: rawIssuer},
+		ThisUpdate:          template.ThisUpdate.UTC().Truncate(time.Second),
+		NextUpdate:          template.NextUpdate.UTC().Truncate(time.Second),
+		RevokedCertificates: revoked,
+		Extensions:          extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signTBS(random, tbs, privKey)
+}
+
+// ParseRevocationList parses a single DER revocation list. The signature is
+// not checked, see CheckSignatureFrom.
+func ParseRevocationList(der []byte) (*RevocationList, error) {
+	var list certList
+	if rest, err := asn1.Unmarshal(der, &list); err != nil || len(rest) != 0 {
+		return nil, ErrInvalidCRL
+	}
+	tbs := list.TBSCertList
+	if tbs.Version != 1 || !tbs.Signature.Algorithm.Equal(list.SignatureAlgorithm.Algorithm) ||
+		list.SignatureValue.BitLength%8 != 0 {
+		return nil, ErrInvalidCRL
+	}
+	rl := &RevocationList{
+		Raw:                  der,
+		RawTBSRevocationList: tbs.Raw,
+		RawIssuer:            tbs.Issuer.FullBytes,
+		SignatureAlgorithm:   list.SignatureAlgorithm.Algorithm,
+		Signature:            list.SignatureValue.Bytes,
+		RevokedCertificates:  tbs.RevokedCertificates,
+		ThisUpdate:           tbs.ThisUpdate,
+		NextUpdate:           tbs.NextUpdate,
+		Extensions:           tbs.Extensions,
+	}
+	if err := parseName(rl.RawIssuer, &rl.Issuer); err != nil {
+		return nil, ErrInvalidCRL
+	}
+	for _, ext := range tbs.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionCRLNumber):
+			rl.Number = new(big.Int)
+			if rest, err := asn1.Unmarshal(ext.Value, &rl.Number); err != nil || len(rest) != 0 {
+				return nil, ErrInvalidCRL
+			}
+		case ext.Id.Equal(oidExtensionAuthorityKeyId):
+			var akid struct {
+				KeyIdentifier []byte `asn1:"optional,tag:0"`
+			}
+			if _, err := asn1.Unmarshal(ext.Value, &akid); err != nil {
+				return nil, ErrInvalidCRL
+			}
+			rl.AuthorityKeyId = akid.KeyIdentifier
+		}
+	}
+	return rl, nil
+}
+
+// CheckSignatureFrom verifies that the list is signed by issuer, and that
+// issuer may sign revocation lists.
+func (rl *RevocationList) CheckSignatureFrom(issuer *Certificate) error {
+	if issuer.KeyUsage != 0 && issuer.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return ErrIncompatibleUsage
+	}
+	return issuer.CheckSignature(rl.SignatureAlgorithm, rl.RawTBSRevocationList, rl.Signature)
+}
+
+// IsRevoked reports whether the certificate with the given serial number is in the list.
+func (rl *RevocationList) IsRevoked(serial *big.Int) bool {
+	for _, rc := range rl.RevokedCertificates {
+		if rc.SerialNumber.Cmp(serial) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalRevocationListPEM encodes a DER revocation list as an "X509 CRL" PEM block.
+func MarshalRevocationListPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCRL, Bytes: der})
+}
@@ -0,0 +1,220 @@
+package falcon
+
+import (
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/url"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+/*
+PKCS#10 certificate signing requests (RFC 2986) signed with a Falcon key.
+Subject alternative names and other extensions are requested with the
+extensionRequest attribute (RFC 2985).
+*/
+
+const pemTypeCertificateRequest = "CERTIFICATE REQUEST"
+
+var oidExtensionRequest = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+
+// CertificateRequest is a PKCS#10 certificate signing request.
+type CertificateRequest struct {
+	Raw                      []byte
+	RawTBSCertificateRequest []byte
+	RawSubjectPublicKeyInfo  []byte
+	RawSubject               []byte
+
+	Version            int
+	SignatureAlgorithm asn1.ObjectIdentifier
+	Signature          []byte
+	Subject            pkix.Name
+
+	PublicKeyAlgorithm asn1.ObjectIdentifier
+	PublicKey          *PublicKey
+
+	// Extensions are the requested extensions. ExtraExtensions are added as
+	// is to a new request; they are not filled by ParseCertificateRequest.
+	Extensions      []pkix.Extension
+	ExtraExtensions []pkix.Extension
+
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+}
+
+type tbsCertificateRequest struct {
+	Version    int
+	Subject    asn1.RawValue
+	PublicKey  asn1.RawValue
+	Attributes []asn1.RawValue `asn1:"tag:0"`
+}
+
+type extensionRequestAttribute struct {
+	Type   asn1.ObjectIdentifier
+	Values [][]pkix.Extension `asn1:"set"`
+}
+
+// CreateCertificateRequest creates a certificate signing request for the
+// public key of privKey, signed with privKey. The template fields Subject
+// (or RawSubject), DNSNames, EmailAddresses, IPAddresses, URIs and
+// ExtraExtensions are used. random defaults to crypto/rand.Reader.
+func CreateCertificateRequest(random io.Reader, template *CertificateRequest, privKey *PrivateKey) ([]byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	spki, err := MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	rawSubject, err := marshalName(template.RawSubject, template.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var extensions []pkix.Extension
+	if hasSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs) &&
+		!hasExtension(oidExtensionSubjectAltName, template.ExtraExtensions) {
+		value, err := marshalSANs(template.DNSNames, template.EmailAddresses, template.IPAddresses, template.URIs)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, pkix.Extension{Id: oidExtensionSubjectAltName, Critical: isEmptyName(rawSubject), Value: value})
+	}
+	extensions = append(extensions, template.ExtraExtensions...)
+
+	attributes := []asn1.RawValue{}
+	if len(extensions) > 0 {
+		attribute, err := asn1.Marshal(extensionRequestAttribute{oidExtensionRequest, [][]pkix.Extension{extensions}})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, asn1.RawValue{FullBytes: attribute})
+	}
+
+	tbs, err := asn1.Marshal(tbsCertificateRequest{
+		Subject:    asn1.RawValue{FullBytes: rawSubject},
+		PublicKey:  asn1.RawValue{FullBytes: spki},
+		Attributes: attributes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signTBS(random, tbs, privKey)
+}
+
+// ParseCertificateRequest parses a single DER certificate signing request.
+// The signature is not checked, see CheckSignature.
+func ParseCertificateRequest(der []byte) (*CertificateRequest, error) {
+	csr := &CertificateRequest{Raw: der}
+	input := cryptobyte.String(der)
+	var request, tbs cryptobyte.String
+	var sigBits asn1.BitString
+	if !input.ReadASN1(&request, cryptobyte_asn1.SEQUENCE) || !input.Empty() ||
+		!request.ReadASN1Element(&tbs, cryptobyte_asn1.SEQUENCE) ||
+		!readAlgorithmOID(&request, &csr.SignatureAlgorithm) ||
+		!request.ReadASN1BitString(&sigBits) || !request.Empty() || sigBits.BitLength%8 != 0 {
+		return nil, ErrInvalidCertificate
+	}
+	csr.RawTBSCertificateRequest = tbs
+	csr.Signature = sigBits.Bytes
+
+	var version int64
+	var subject, spki, spkiAlg, attributes cryptobyte.String
+	if !tbs.ReadASN1(&tbs, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Int64WithTag(&version, cryptobyte_asn1.INTEGER) || version != 0 ||
+		!tbs.ReadASN1Element(&subject, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1Element(&spki, cryptobyte_asn1.SEQUENCE) ||
+		!tbs.ReadASN1(&attributes, cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) || !tbs.Empty() {
+		return nil, ErrInvalidCertificate
+	}
+	csr.Version = int(version)
+	csr.RawSubject, csr.RawSubjectPublicKeyInfo = subject, spki
+	if err := parseName(subject, &csr.Subject); err != nil {
+		return nil, err
+	}
+	if !spki.ReadASN1(&spki, cryptobyte_asn1.SEQUENCE) || !spki.ReadASN1(&spkiAlg, cryptobyte_asn1.SEQUENCE) ||
+		!spkiAlg.ReadASN1ObjectIdentifier(&csr.PublicKeyAlgorithm) {
+		return nil, ErrInvalidCertificate
+	}
+	if _, err := DegreeFromOID(csr.PublicKeyAlgorithm); err == nil {
+		pubKey, err := ParsePKIXPublicKey(csr.RawSubjectPublicKeyInfo)
+		if err != nil {
+			return nil, err
+		}
+		csr.PublicKey = pubKey
+	}
+
+	for !attributes.Empty() {
+		var attribute, values cryptobyte.String
+		var attrType asn1.ObjectIdentifier
+		if !attributes.ReadASN1(&attribute, cryptobyte_asn1.SEQUENCE) ||
+			!attribute.ReadASN1ObjectIdentifier(&attrType) ||
+			!attribute.ReadASN1(&values, cryptobyte_asn1.SET) || !attribute.Empty() {
+			return nil, ErrInvalidCertificate
+		}
+		if !attrType.Equal(oidExtensionRequest) {
+			continue
+		}
+		var extensions cryptobyte.String
+		if !values.ReadASN1(&extensions, cryptobyte_asn1.SEQUENCE) || !values.Empty() {
+			return nil, ErrInvalidCertificate
+		}
+		for !extensions.Empty() {
+			var ext pkix.Extension
+			var extension cryptobyte.String
+			if !extensions.ReadASN1(&extension, cryptobyte_asn1.SEQUENCE) ||
+				!extension.ReadASN1ObjectIdentifier(&ext.Id) ||
+				!readOptionalBoolean(&extension, &ext.Critical) ||
+				!extension.ReadASN1Bytes(&ext.Value, cryptobyte_asn1.OCTET_STRING) || !extension.Empty() {
+				return nil, ErrInvalidCertificate
+			}
+			csr.Extensions = append(csr.Extensions, ext)
+			if ext.Id.Equal(oidExtensionSubjectAltName) {
+				if err := parseSANs(ext.Value, &csr.DNSNames, &csr.EmailAddresses, &csr.IPAddresses, &csr.URIs); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return csr, nil
+}
+
+// CheckSignature verifies that the request is signed by the key it holds.
+func (csr *CertificateRequest) CheckSignature() error {
+	n, err := DegreeFromOID(csr.SignatureAlgorithm)
+	if err != nil {
+		return err
+	}
+	if csr.PublicKey == nil || csr.PublicKey.n != n {
+		return ErrUnsupportedAlgorithm
+	}
+	if !csr.PublicKey.Verify(csr.RawTBSCertificateRequest, csr.Signature) {
+		return ErrCertificateSignature
+	}
+	return nil
+}
+
+// MarshalCertificateRequestPEM encodes a DER request as a "CERTIFICATE REQUEST" PEM block.
+func MarshalCertificateRequestPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeCertificateRequest, Bytes: der})
+}
+
+// ParseCertificateRequestPEM parses the first "CERTIFICATE REQUEST" PEM block of data.
+func ParseCertificateRequestPEM(data []byte) (*CertificateRequest, error) {
+	der, err := decodePEM(data, pemTypeCertificateRequest)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCertificateRequest(der)
+}
@@ -0,0 +1,312 @@
+package falcon
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/scrypt"
+)
+
+/*
+Passphrase-encrypted private keys. The file is a header followed by the
+AEAD encryption of the private key encoding of MarshalPrivateKey, with the
+header as additional data:
+
+  - magic "FNKE" and version 1
+  - the private key header 0x50 + LOGN, announcing the degree
+  - the KDF: 1 (scrypt) with uint8 log2(N), uint32 r and uint32 p, or
+    2 (Argon2id) with uint32 time, uint32 memory in KiB and uint8 threads
+  - the uint8-prefixed KDF salt
+  - the AEAD: 1 (XChaCha20-Poly1305) or 2 (AES-256-GCM)
+  - the uint8-prefixed nonce
+
+All integers are big endian. A wrong passphrase and any change to the file
+both make the decryption fail with ErrIncorrectPassphrase.
+*/
+
+// KDF identifies the key derivation function of an encrypted private key.
+type KDF uint8
+
+const (
+	KDFScrypt   KDF = 1
+	KDFArgon2id KDF = 2
+)
+
+// KeyCipher identifies the AEAD of an encrypted private key.
+type KeyCipher uint8
+
+const (
+	CipherXChaCha20Poly1305 KeyCipher = 1
+	CipherAES256GCM         KeyCipher = 2
+)
+
+const (
+	encryptedKeyMagic   = "FNKE"
+	encryptedKeyVersion = 1
+	encryptedKeySaltLen = 16
+	pemTypeEncryptedKey = "ENCRYPTED FALCON PRIVATE KEY"
+)
+
+// Bounds on the KDF parameters accepted when decrypting, so that a crafted
+// file cannot make the KDF exhaust memory. scrypt allocates 128·N·r bytes
+// and runs p·r times its mixing function, so these products are bounded
+// rather than the parameters alone.
+const (
+	maxScryptLogN   = 22
+	maxScryptMemory = 1 << 30 // 128·N·r bytes, 1 GiB
+	maxScryptPR     = 1 << 10
+	maxArgon2Time   = 64
+	maxArgon2Memory = 1 << 20 // 1 GiB
+)
+
+var (
+	// ErrIncorrectPassphrase is returned when an encrypted private key does
+	// not decrypt, because the passphrase is wrong or the file was modified
+	ErrIncorrectPassphrase = errors.New("incorrect passphrase or modified encrypted private key")
+	// ErrInvalidKDFParameters is returned when the KDF parameters are unknown or out of bounds
+	ErrInvalidKDFParameters = errors.New("invalid key derivation parameters")
+)
+
+// EncryptionOptions select how a private key is encrypted. The zero value
+// selects scrypt with N = 2^15, r = 8, p = 1 and XChaCha20-Poly1305; zero
+// parameters select the defaults of their KDF.
+type EncryptionOptions struct {
+	KDF    KDF
+	Cipher KeyCipher
+
+	// scrypt cost parameters, N = 2^ScryptLogN.
+	ScryptLogN uint8
+	ScryptR    uint32
+	ScryptP    uint32
+
+	// Argon2id parameters; Argon2Memory is in KiB. The defaults are
+	// t = 3, m = 64 MiB and 4 threads.
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// withDefaults returns opts with the zero fields set to their defaults.
+func (opts EncryptionOptions) withDefaults() EncryptionOptions {
+	if opts.KDF == 0 {
+		opts.KDF = KDFScrypt
+	}
+	if opts.Cipher == 0 {
+		opts.Cipher = CipherXChaCha20Poly1305
+	}
+	switch opts.KDF {
+	case KDFScrypt:
+		if opts.ScryptLogN == 0 {
+			opts.ScryptLogN = 15
+		}
+		if opts.ScryptR == 0 {
+			opts.ScryptR = 8
+		}
+		if opts.ScryptP == 0 {
+			opts.ScryptP = 1
+		}
+	case KDFArgon2id:
+		if opts.Argon2Time == 0 {
+			opts.Argon2Time = 3
+		}
+		if opts.Argon2Memory == 0 {
+			opts.Argon2Memory = 64 * 1024
+		}
+		if opts.Argon2Threads == 0 {
+			opts.Argon2Threads = 4
+		}
+	}
+	return opts
+}
+
+// check reports whether the parameters are known and within bounds.
+func (opts *EncryptionOptions) check() error {
+	switch opts.KDF {
+	case KDFScrypt:
+		if opts.ScryptLogN < 1 || opts.ScryptLogN > maxScryptLogN || opts.ScryptR < 1 || opts.ScryptP < 1 {
+			return ErrInvalidKDFParameters
+		}
+		// Computed on 64 bits, where neither product overflows
+		r, p := uint64(opts.ScryptR), uint64(opts.ScryptP)
+		if 128*r<<opts.ScryptLogN > maxScryptMemory || p*r > maxScryptPR {
+			return ErrInvalidKDFParameters
+		}
+	case KDFArgon2id:
+		if opts.Argon2Time < 1 || opts.Argon2Time > maxArgon2Time || opts.Argon2Threads < 1 ||
+			opts.Argon2Memory < 8*uint32(opts.Argon2Threads) || opts.Argon2Memory > maxArgon2Memory {
+			return ErrInvalidKDFParameters
+		}
+	default:
+		return ErrInvalidKDFParameters
+	}
+	if opts.Cipher != CipherXChaCha20Poly1305 && opts.Cipher != CipherAES256GCM {
+		return ErrInvalidKDFParameters
+	}
+	return nil
+}
+
+// deriveKey derives the 256-bit encryption key from the passphrase.
+func (opts *EncryptionOptions) deriveKey(passphrase, salt []byte) ([]byte, error) {
+	if opts.KDF == KDFArgon2id {
+		return argon2.IDKey(passphrase, salt, opts.Argon2Time, opts.Argon2Memory, opts.Argon2Threads, 32), nil
+	}
+	return scrypt.Key(passphrase, salt, 1<<opts.ScryptLogN, int(opts.ScryptR), int(opts.ScryptP), 32)
+}
+
+// aead returns the AEAD of the cipher with the given key.
+func (opts *EncryptionOptions) aead(key []byte) (cipher.AEAD, error) {
+	if opts.Cipher == CipherAES256GCM {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	}
+	return chacha20poly1305.NewX(key)
+}
+
+// MarshalEncryptedPrivateKey encrypts the private key encoding of privKey
+// under a key derived from passphrase. A nil opts selects the defaults of
+// EncryptionOptions.
+func MarshalEncryptedPrivateKey(privKey *PrivateKey, passphrase []byte, opts *EncryptionOptions) ([]byte, error) {
+	var o EncryptionOptions
+	if opts != nil {
+		o = *opts
+	}
+	o = o.withDefaults()
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	plaintext, err := MarshalPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, encryptedKeySaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := o.deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := o.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	var b cryptobyte.Builder
+	b.AddBytes([]byte(encryptedKeyMagic))
+	b.AddUint8(encryptedKeyVersion)
+	b.AddUint8(plaintext[0])
+	b.AddUint8(uint8(o.KDF))
+	if o.KDF == KDFScrypt {
+		b.AddUint8(o.ScryptLogN)
+		b.AddUint32(o.ScryptR)
+		b.AddUint32(o.ScryptP)
+	} else {
+		b.AddUint32(o.Argon2Time)
+		b.AddUint32(o.Argon2Memory)
+		b.AddUint8(o.Argon2Threads)
+	}
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(salt) })
+	b.AddUint8(uint8(o.Cipher))
+	b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddBytes(nonce) })
+	header, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// ParseEncryptedPrivateKey decrypts a private key encrypted by
+// MarshalEncryptedPrivateKey. It returns ErrIncorrectPassphrase if the
+// passphrase is wrong or the file was modified.
+func ParseEncryptedPrivateKey(data, passphrase []byte) (*PrivateKey, error) {
+	s := cryptobyte.String(data)
+	var magic []byte
+	var version, keyHeader, kdf, aeadID uint8
+	var salt, nonce cryptobyte.String
+	var o EncryptionOptions
+	if !s.ReadBytes(&magic, len(encryptedKeyMagic)) || string(magic) != encryptedKeyMagic ||
+		!s.ReadUint8(&version) || version != encryptedKeyVersion ||
+		!s.ReadUint8(&keyHeader) || !s.ReadUint8(&kdf) {
+		return nil, ErrInvalidKeyEncoding
+	}
+	n, err := degreeFromHeader(keyHeader, headerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	o.KDF = KDF(kdf)
+	switch o.KDF {
+	case KDFScrypt:
+		if !s.ReadUint8(&o.ScryptLogN) || !s.ReadUint32(&o.ScryptR) || !s.ReadUint32(&o.ScryptP) {
+			return nil, ErrInvalidKeyEncoding
+		}
+	case KDFArgon2id:
+		if !s.ReadUint32(&o.Argon2Time) || !s.ReadUint32(&o.Argon2Memory) || !s.ReadUint8(&o.Argon2Threads) {
+			return nil, ErrInvalidKeyEncoding
+		}
+	default:
+		return nil, ErrInvalidKDFParameters
+	}
+	if !s.ReadUint8LengthPrefixed(&salt) || len(salt) == 0 ||
+		!s.ReadUint8(&aeadID) || !s.ReadUint8LengthPrefixed(&nonce) {
+		return nil, ErrInvalidKeyEncoding
+	}
+	o.Cipher = KeyCipher(aeadID)
+	if err := o.check(); err != nil {
+		return nil, err
+	}
+	header := data[:len(data)-len(s)]
+
+	key, err := o.deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := o.aead(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrInvalidKeyEncoding
+	}
+	plaintext, err := aead.Open(nil, nonce, s, header)
+	if err != nil {
+		return nil, ErrIncorrectPassphrase
+	}
+	privKey, err := ParsePrivateKey(plaintext)
+	if err != nil || privKey.n != n {
+		return nil, ErrInvalidKeyEncoding
+	}
+	return privKey, nil
+}
+
+// MarshalEncryptedPrivateKeyPEM encodes an encrypted private key as an
+// "ENCRYPTED FALCON PRIVATE KEY" PEM block.
+func MarshalEncryptedPrivateKeyPEM(privKey *PrivateKey, passphrase []byte, opts *EncryptionOptions) ([]byte, error) {
+	data, err := MarshalEncryptedPrivateKey(privKey, passphrase, opts)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeEncryptedKey, Bytes: data}), nil
+}
+
+// ParseEncryptedPrivateKeyPEM decrypts the first "ENCRYPTED FALCON PRIVATE
+// KEY" PEM block of data.
+func ParseEncryptedPrivateKeyPEM(data, passphrase []byte) (*PrivateKey, error) {
+	der, err := decodePEM(data, pemTypeEncryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParseEncryptedPrivateKey(der, passphrase)
+}
@@ -0,0 +1,118 @@
+package falcon
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// fastEncryption keeps the KDFs cheap in tests.
+var fastEncryption = []*EncryptionOptions{
+	{KDF: KDFScrypt, ScryptLogN: 4},
+	{KDF: KDFScrypt, ScryptLogN: 4, Cipher: CipherAES256GCM},
+	{KDF: KDFArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 1},
+	{KDF: KDFArgon2id, Argon2Time: 1, Argon2Memory: 64, Argon2Threads: 2, Cipher: CipherAES256GCM},
+}
+
+func TestEncryptedPrivateKey(t *testing.T) {
+	privKey, err := ParsePrivateKeyPEM(mustReadFile(t, "testdata/falcon512_priv.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := []byte("correct horse battery staple")
+	for _, opts := range fastEncryption {
+		data, err := MarshalEncryptedPrivateKey(privKey, passphrase, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := ParseEncryptedPrivateKey(data, passphrase)
+		if err != nil || !decrypted.Equal(privKey) {
+			t.Fatalf("%+v: ParseEncryptedPrivateKey returned %v", opts, err)
+		}
+		if _, err := ParseEncryptedPrivateKey(data, []byte("Correct horse battery staple")); err != ErrIncorrectPassphrase {
+			t.Errorf("%+v: wrong passphrase returned %v, want %v", opts, err, ErrIncorrectPassphrase)
+		}
+		// Changing the degree, any KDF parameter or any encrypted byte is detected
+		for i := 5; i < len(data); i++ {
+			tampered := append([]byte(nil), data...)
+			tampered[i] ^= 0x01
+			if _, err := ParseEncryptedPrivateKey(tampered, passphrase); err == nil {
+				t.Fatalf("%+v: modified byte %d was not detected", opts, i)
+			}
+		}
+		if _, err := ParseEncryptedPrivateKey(data[:len(data)-1], passphrase); err != ErrIncorrectPassphrase {
+			t.Errorf("%+v: truncated file returned %v", opts, err)
+		}
+	}
+}
+
+func TestEncryptedPrivateKeyFile(t *testing.T) {
+	// Encrypted with scrypt (N = 2^10) and XChaCha20-Poly1305
+	data := mustReadFile(t, "testdata/falcon512_priv_encrypted.pem")
+	privKey, err := ParseEncryptedPrivateKeyPEM(data, []byte("correct horse battery staple"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParsePrivateKeyPEM(mustReadFile(t, "testdata/falcon512_priv.pem"))
+	if err != nil || !privKey.Equal(want) {
+		t.Errorf("decrypted key does not match: %v", err)
+	}
+	if _, err := ParseEncryptedPrivateKeyPEM(data, nil); err != ErrIncorrectPassphrase {
+		t.Errorf("empty passphrase returned %v, want %v", err, ErrIncorrectPassphrase)
+	}
+	if _, err := ParseEncryptedPrivateKeyPEM(mustReadFile(t, "testdata/falcon512_priv.pem"), nil); err != ErrInvalidPEM {
+		t.Errorf("unencrypted key returned %v, want %v", err, ErrInvalidPEM)
+	}
+}
+
+func TestEncryptionParameters(t *testing.T) {
+	privKey, err := ParsePrivateKeyPEM(mustReadFile(t, "testdata/falcon512_priv.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []*EncryptionOptions{
+		{KDF: 3},
+		{Cipher: 3},
+		{KDF: KDFScrypt, ScryptLogN: maxScryptLogN + 1},
+		// 128·2^20·16 bytes is 2 GiB
+		{KDF: KDFScrypt, ScryptLogN: 20, ScryptR: 16},
+		{KDF: KDFScrypt, ScryptLogN: 10, ScryptR: 64, ScryptP: 64},
+		{KDF: KDFArgon2id, Argon2Memory: maxArgon2Memory + 1},
+		{KDF: KDFArgon2id, Argon2Memory: 8, Argon2Threads: 2},
+	} {
+		if _, err := MarshalEncryptedPrivateKey(privKey, nil, opts); err != ErrInvalidKDFParameters {
+			t.Errorf("%+v: MarshalEncryptedPrivateKey returned %v, want %v", opts, err, ErrInvalidKDFParameters)
+		}
+	}
+
+	// Parameters are checked before running the KDF
+	data, err := MarshalEncryptedPrivateKey(privKey, nil, fastEncryption[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[7] = maxScryptLogN + 8
+	if _, err := ParseEncryptedPrivateKey(data, nil); err != ErrInvalidKDFParameters {
+		t.Errorf("scrypt with N = 2^30 returned %v, want %v", err, ErrInvalidKDFParameters)
+	}
+	// N = 2^22 and r = 1024 would allocate 512 GiB
+	data[7] = maxScryptLogN
+	binary.BigEndian.PutUint32(data[8:], 1024)
+	if _, err := ParseEncryptedPrivateKey(data, nil); err != ErrInvalidKDFParameters {
+		t.Errorf("scrypt with 128·N·r = 512 GiB returned %v, want %v", err, ErrInvalidKDFParameters)
+	}
+	data[7] = 1
+	binary.BigEndian.PutUint32(data[8:], 1)
+	binary.BigEndian.PutUint32(data[12:], 1<<30)
+	if _, err := ParseEncryptedPrivateKey(data, nil); err != ErrInvalidKDFParameters {
+		t.Errorf("scrypt with p = 2^30 returned %v, want %v", err, ErrInvalidKDFParameters)
+	}
+
+	argon, err := MarshalEncryptedPrivateKey(privKey, nil, fastEncryption[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Argon2id with m = 4 TiB
+	binary.BigEndian.PutUint32(argon[11:], 1<<32-1)
+	if _, err := ParseEncryptedPrivateKey(argon, nil); err != ErrInvalidKDFParameters {
+		t.Errorf("Argon2id with m = 4 TiB returned %v, want %v", err, ErrInvalidKDFParameters)
+	}
+}
@@ -0,0 +1,64 @@
+package falcon
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+)
+
+var _ crypto.Signer = (*ExpandedKey)(nil)
+
+// ExpandedKey is a private key expanded once into the FFT basis and the
+// normalized LDL tree of the fast Fourier sampler. Signing with a
+// PrivateKey repeats the expansion for every signature; an ExpandedKey
+// amortizes it over many signatures, and is safe for concurrent use.
+type ExpandedKey struct {
+	falcon *Falcon
+	pubKey *PublicKey
+}
+
+// Expand expands privKey for repeated signing.
+func (privKey *PrivateKey) Expand() (*ExpandedKey, error) {
+	falcon, err := newFalcon(privKey)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &ExpandedKey{falcon: falcon, pubKey: pubKey}, nil
+}
+
+// PublicKey returns the public key of the expanded key.
+func (key *ExpandedKey) PublicKey() *PublicKey {
+	return key.pubKey
+}
+
+// Public returns the public key of the expanded key, as a *PublicKey.
+func (key *ExpandedKey) Public() crypto.PublicKey {
+	return key.pubKey
+}
+
+// SignFormat is PrivateKey.SignFormat with the expanded key.
+func (key *ExpandedKey) SignFormat(message []byte, format SignatureFormat) ([]byte, error) {
+	return key.falcon.sign(message, format, rand.Reader)
+}
+
+// Sign is PrivateKey.Sign with the expanded key.
+func (key *ExpandedKey) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return signDigest(key.falcon.sign, random, digest, opts)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of the binary encoding of the
+// public key, in the unpadded base64 form of OpenSSH ("SHA256:...").
+func (pubKey *PublicKey) Fingerprint() (string, error) {
+	raw, err := MarshalPublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
@@ -1,8 +1,10 @@
 package falcon
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 
 	"github.com/Indra4091/falconGo/src/internal"
@@ -16,7 +18,7 @@ import (
 var (
 	// ErrInvalidDegree is returned when the degree is not a power of 2
 	ErrInvalidDegree = errors.New("n is not valid dimension/degree of the cyclotomic ring")
-	// ErrInvalidPolysLenght is returned when the lenght of the polynomials is not equal to each other
+	// ErrInvalidPolysLength is returned when the polynomials do not all have n coefficients
 	ErrInvalidPolysLength = errors.New("lenght of polynomials is not equal")
 )
 
@@ -33,8 +35,7 @@ func GetParamSet(n uint16) PublicParameters {
 }
 
 func isValidPolysLength(n uint16, f, g, F, G []int16) bool {
-	sum := uint16(len(f) + len(g) + len(F) + len(G))
-	return sum%(4*n) == 0
+	return len(f) == int(n) && len(g) == int(n) && len(F) == int(n) && len(G) == int(n)
 }
 
 func fft3D(polynomials [][][]float64) [][][]complex128 {
@@ -51,7 +52,7 @@ func fft3D(polynomials [][][]float64) [][][]complex128 {
 // From f, g, F, G, compute the basis B0 of a NTRU lattice
 // as well as its Gram matrix and their fft's.
 // return B0FFT, TFFT
-func basisAndMatrix(f, g, F, G []int16) ([][][]complex128, internal.FFTtree) {
+func basisAndMatrix(f, g, F, G []int16) ([][][]complex128, []any) {
 	B0 := [][][]float64{
 		{util.Int16ToFloat64(g), fft.Neg(util.Int16ToFloat64(f))},
 		{util.Int16ToFloat64(G), fft.Neg(util.Int16ToFloat64(F))},
@@ -59,9 +60,8 @@ func basisAndMatrix(f, g, F, G []int16) ([][][]complex128, internal.FFTtree) {
 	G0 := internal.Gram(B0)
 	B0FFT := fft3D(B0)
 	G0FFT := fft3D(G0)
-	TFFT := new(internal.FFTtree)
-	TFFT.FfldlFFT(G0FFT)
-	return B0FFT, *TFFT
+	TFFT := internal.FfldlTree(G0FFT)
+	return B0FFT, TFFT
 }
 
 // printTree prints a LDL tree in a human-readable format.
@@ -89,19 +89,21 @@ func printTree(tree []any, prefix string) string {
 	}
 }
 
+// PrintTree expands the private key and returns its normalized LDL tree in
+// the format of printTree.
+func (privKey *PrivateKey) PrintTree() (string, error) {
+	falcon, err := newFalcon(privKey)
+	if err != nil {
+		return "", err
+	}
+	return printTree(falcon.TFFT, ""), nil
+}
+
 // Normalize leaves of a LDLD tree (from ||b_i||**2 to sigma/||b_i||)
 // args: a LDL tree (T), standar deviation (sigma)
 // format: coefficient or fft
-func normalizeTree(tree [][]complex128, sigma float64) {
-	// python definition: normalize_tree(tree[1], sigma)
-	fmt.Printf("\nnormalizeTree: sigma = %f, trees = %v", sigma, tree)
-	if len(tree) == 3 {
-		normalizeTree([][]complex128{tree[1], nil}, sigma)
-		normalizeTree([][]complex128{tree[2], nil}, sigma)
-	} else {
-		tree[0][0] = complex(sigma/math.Sqrt(real(tree[0][0])), 0)
-		tree[0][1] = 0
-	}
+func normalizeTree(tree []any, sigma float64) {
+	internal.NormalizeTree(tree, sigma)
 }
 
 type PublicKey struct {
@@ -113,20 +115,42 @@ func NewPublicKey() *PublicKey {
 	return new(PublicKey)
 }
 
+// GetPublicKey returns the public key h = g/f mod q of the private key. If f
+// is not invertible, which PrivateKey.Validate rules out, the returned key
+// has no coefficients and fails PublicKey.Validate.
+//
+// Deprecated: use DerivePublicKey, which reports a non-invertible f.
 func (privKey *PrivateKey) GetPublicKey() *PublicKey {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return &PublicKey{n: privKey.n}
+	}
+	return pubKey
+}
+
+// DerivePublicKey returns the public key h = g/f mod q of the private key,
+// or ErrNotInvertible if f is not invertible mod q.
+func (privKey *PrivateKey) DerivePublicKey() (*PublicKey, error) {
+	// a polynomial such that h*f = g mod (Phi,q)
+	h, err := ntt.DivZq(privKey.g, privKey.f)
+	if err == ntt.ErrDivByZero {
+		return nil, ErrNotInvertible
+	}
+	if err != nil {
+		return nil, ErrInvalidPolysLength
+	}
 	pubKey := NewPublicKey()
 	pubKey.n = privKey.n
-	// a polynomial such that h*f = g mod (Phi,q)
-	pubKey.h, _ = ntt.DivZq(privKey.g, privKey.f)
+	pubKey.h = h
 
-	return pubKey
+	return pubKey, nil
 }
 
 type Falcon struct {
 	//ParamSets
 	PrivateKey
 	B0FFT [][][]complex128
-	TFFT  internal.FFTtree
+	TFFT  []any
 	h     []int16
 }
 
@@ -156,6 +180,23 @@ func GeneratePrivateKey(n uint16) (*PrivateKey, error) {
 	return privKey, nil
 }
 
+// GeneratePrivateKeyFrom generates a new private key drawing its randomness
+// from randomBytes, so that the same stream always yields the same key.
+func GeneratePrivateKeyFrom(n uint16, randomBytes io.Reader) (*PrivateKey, error) {
+	if !isValidDegree(n) {
+		return nil, ErrInvalidDegree
+	}
+	f, g, F, G, err := internal.NtruGenFrom(n, randomBytes)
+	if err != nil {
+		return nil, err
+	}
+	privKey := NewPrivateKey()
+	privKey.n = n
+	privKey.f, privKey.g, privKey.F, privKey.G = f, g, F, G
+
+	return privKey, nil
+}
+
 // GetPrivateKey returns a private key from the given polynomials.
 func GetPrivateKey(n uint16, f, g, F, G []int16) (*PrivateKey, error) {
 	if !isValidDegree(n) {
@@ -180,44 +221,138 @@ func NewKeyPair(n uint16) (privKey *PrivateKey, pubKey *PublicKey, err error) {
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// Compute NTRU polynomials f, g, F, G verifying fG - gF = q mod Phi
-	falcon := new(Falcon)
-	falcon.PrivateKey = *privKey
-	falcon.B0FFT, falcon.TFFT = basisAndMatrix(
-		falcon.PrivateKey.f,
-		falcon.PrivateKey.g,
-		falcon.PrivateKey.F,
-		falcon.PrivateKey.G,
-	)
-	normalizeTree(falcon.TFFT.AllChild(), ParamSets[n].sigma)
-	falcon.h, err = ntt.DivZq(falcon.PrivateKey.g, falcon.PrivateKey.f)
+	pubKey, err = privKey.DerivePublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
 	return privKey, pubKey, nil
 }
 
+// NewKeyPairFromPrivateKey returns the key pair of the polynomials f, g, F
+// and G, which must form a valid private key (see PrivateKey.Validate).
 func NewKeyPairFromPrivateKey(n uint16, polys [4][]int16) (privKey *PrivateKey, pubKey *PublicKey, err error) {
+	privKey, err = GetPrivateKey(n, polys[0], polys[1], polys[2], polys[3])
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := privKey.Validate(); err != nil {
+		return nil, nil, err
+	}
+	pubKey, err = privKey.DerivePublicKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return privKey, pubKey, nil
+}
+
+// newFalcon expands the private key into the FFT basis B0 and the
+// normalized LDL tree used by the fast Fourier sampler.
+func newFalcon(privKey *PrivateKey) (*Falcon, error) {
+	if !isValidDegree(privKey.n) {
+		return nil, ErrInvalidDegree
+	}
+	h, err := ntt.DivZq(privKey.g, privKey.f)
+	if err != nil {
+		return nil, err
+	}
 	falcon := new(Falcon)
-	if !isValidDegree(n) {
-		return nil, nil, ErrInvalidDegree
+	falcon.PrivateKey = *privKey
+	falcon.B0FFT, falcon.TFFT = basisAndMatrix(privKey.f, privKey.g, privKey.F, privKey.G)
+	normalizeTree(falcon.TFFT, ParamSets[privKey.n].sigma)
+	falcon.h = h
+	return falcon, nil
+}
+
+// samplePreImage computes a short vector s such that s[0] + s[1] * h = point.
+func (falcon *Falcon) samplePreImage(point []int16, randomBytes io.Reader) ([][]int16, error) {
+	n := int(falcon.n)
+	a, b := falcon.B0FFT[0][0], falcon.B0FFT[0][1]
+	c, d := falcon.B0FFT[1][0], falcon.B0FFT[1][1]
+
+	// Compute the target vector t = (point, 0) * B0^-1
+	pointFFT := fft.FFT(util.Int16ToFloat64(point))
+	t0FFT := make([]complex128, n)
+	t1FFT := make([]complex128, n)
+	for i := 0; i < n; i++ {
+		t0FFT[i] = pointFFT[i] * d[i] / util.Q
+		t1FFT[i] = -pointFFT[i] * b[i] / util.Q
 	}
-	if !isValidPolysLength(n, polys[0], polys[1], polys[2], polys[3]) {
-		return nil, nil, ErrInvalidPolysLength
+
+	// Sample z close to t and compute v = z * B0
+	zFFT, err := internal.FfSamplingFFT([][]complex128{t0FFT, t1FFT}, falcon.TFFT, ParamSets[falcon.n].sigmin, randomBytes)
+	if err != nil {
+		return nil, err
 	}
+	v0 := fft.IFFT(fft.AddFFT(fft.MulFFT(zFFT[0], a), fft.MulFFT(zFFT[1], c)))
+	v1 := fft.IFFT(fft.AddFFT(fft.MulFFT(zFFT[0], b), fft.MulFFT(zFFT[1], d)))
 
-	falcon.PrivateKey.f = polys[0]
-	falcon.PrivateKey.g = polys[1]
-	falcon.PrivateKey.F = polys[2]
-	falcon.PrivateKey.G = polys[3]
+	// s = (point, 0) - v is short
+	s0 := make([]int16, n)
+	s1 := make([]int16, n)
+	for i := 0; i < n; i++ {
+		s0[i] = point[i] - int16(math.Round(v0[i]))
+		s1[i] = -int16(math.Round(v1[i]))
+	}
+	return [][]int16{s0, s1}, nil
+}
 
-	falcon.B0FFT, falcon.TFFT = basisAndMatrix(
-		falcon.PrivateKey.f,
-		falcon.PrivateKey.g,
-		falcon.PrivateKey.F,
-		falcon.PrivateKey.G,
-	)
-	normalizeTree(falcon.TFFT.AllChild(), ParamSets[n].sigma)
-	falcon.h, err = ntt.DivZq(falcon.PrivateKey.g, falcon.PrivateKey.f)
-	return privKey, pubKey, nil
+// sign computes a signature of message in the given format, where message
+// is the exact input hashed to a point after the salt.
+func (falcon *Falcon) sign(message []byte, format SignatureFormat, randomBytes io.Reader) ([]byte, error) {
+	param := ParamSets[falcon.n]
+	if format > FormatCT {
+		return nil, ErrInvalidFormat
+	}
+	salt := make([]byte, SaltLen)
+	if _, err := io.ReadFull(randomBytes, salt); err != nil {
+		return nil, err
+	}
+	var hashed []int16
+	if format.usesCTHash() {
+		hashed = hashToPointCT(message, salt, falcon.n)
+	} else {
+		hashed = hashToPointN(message, salt, falcon.n)
+	}
+	for {
+		s, err := falcon.samplePreImage(hashed, randomBytes)
+		if err != nil {
+			return nil, err
+		}
+		var normSign uint32
+		for _, v := range s[0] {
+			normSign += uint32(int32(v) * int32(v))
+		}
+		for _, v := range s[1] {
+			normSign += uint32(int32(v) * int32(v))
+		}
+		if normSign > param.sigbound {
+			continue
+		}
+		encS, err := format.encode(s[1], falcon.n)
+		if err != nil {
+			continue
+		}
+		signature := make([]byte, 0, HeadLen+SaltLen+len(encS))
+		signature = append(signature, format.header(falcon.n))
+		signature = append(signature, salt...)
+		return append(signature, encS...), nil
+	}
+}
+
+// SignFormat signs message with the round-3 Falcon construction, hashing
+// salt‖message to a point, and encodes the signature in the given format.
+// With FormatPadded, the signature is header‖salt‖compressed s1, padded to
+// the signature bytelength of the key's degree.
+func (privKey *PrivateKey) SignFormat(message []byte, format SignatureFormat) ([]byte, error) {
+	return privKey.signWithRand(message, format, rand.Reader)
+}
+
+func (privKey *PrivateKey) signWithRand(message []byte, format SignatureFormat, randomBytes io.Reader) ([]byte, error) {
+	falcon, err := newFalcon(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return falcon.sign(message, format, randomBytes)
 }
 
 // Hash a message to a point in Z[x] mod(Phi, q).
@@ -259,8 +394,11 @@ func (privKey *PrivateKey) hashToPoint(message []byte, salt []byte) []float64 {
 //type converted from []float64 to []int16
 
 func hashToPoint(message []byte, salt []byte) []int16 {
+	return hashToPointN(message, salt, 512)
+}
 
-	n := 512
+// hashToPointN is hashToPoint for a ring of degree n.
+func hashToPointN(message []byte, salt []byte, n uint16) []int16 {
 	// Create a SHAKE256 object and hash the salt and message
 	shake := sha3.NewShake256()
 	shake.Write(salt)
@@ -271,7 +409,6 @@ func hashToPoint(message []byte, salt []byte) []int16 {
 
 	for i < int(n) {
 		//take two bytes, transform into int16
-		//couldn't find shake.Read() definition?
 		var buf [2]byte
 		shake.Read(buf[:])
 		// Map the bytes to coefficients
@@ -286,6 +423,63 @@ func hashToPoint(message []byte, salt []byte) []int16 {
 	return hashed
 }
 
+// overtab is, for each LOGN, the number of extra 16-bit values drawn by
+// hashToPointCT so that n of them are accepted with overwhelming probability.
+var overtab = [11]int{0, 65, 67, 71, 77, 86, 100, 122, 154, 205, 287}
+
+// hashToPointCT computes the same point as hashToPointN, without
+// message-dependent branches or memory accesses (hash_to_point_ct in the
+// reference implementation). A fixed number of values is drawn from SHAKE256,
+// reduced mod q with masks, and the rejected ones are squeezed out by a
+// logarithmic sequence of conditional swaps.
+func hashToPointCT(message []byte, salt []byte, n uint16) []int16 {
+	over := overtab[LOGN[n]]
+	m := int(n) + over
+	shake := sha3.NewShake256()
+	shake.Write(salt)
+	shake.Write(message)
+
+	// Rejected values are set to 0xFFFF
+	tt := make([]uint16, m)
+	var buf [2]byte
+	for u := 0; u < m; u++ {
+		shake.Read(buf[:])
+		w := uint32(buf[0])<<8 | uint32(buf[1])
+		wr := w - (24578 & (((w - 24578) >> 31) - 1))
+		wr = wr - (24578 & (((wr - 24578) >> 31) - 1))
+		wr = wr - (12289 & (((wr - 12289) >> 31) - 1))
+		wr |= ((w - 61445) >> 31) - 1
+		tt[u] = uint16(wr)
+	}
+
+	// Each pass moves valid values down by p slots when the p bit of their
+	// jump to the final position is set; the destination is always free.
+	for p := 1; p <= over; p <<= 1 {
+		var v uint32
+		for u := 0; u < m; u++ {
+			sv := uint32(tt[u])
+			// the value at u should ultimately go to v
+			j := uint32(u) - v
+			// mk is all ones if the value is valid
+			mk := (sv >> 15) - 1
+			v -= mk
+			if u < p {
+				continue
+			}
+			dv := uint32(tt[u-p])
+			mk &= -(((j & uint32(p)) + 0x1FF) >> 9)
+			tt[u] = uint16(sv ^ (mk & (sv ^ dv)))
+			tt[u-p] = uint16(dv ^ (mk & (sv ^ dv)))
+		}
+	}
+
+	hashed := make([]int16, n)
+	for i := range hashed {
+		hashed[i] = int16(tt[i])
+	}
+	return hashed
+}
+
 /*const (
 	maxRate = 136
 )
@@ -441,151 +635,30 @@ func (pubKey *PublicKey) hashToPoint(message []byte, salt []byte) []int16 {
 }*/
 ///////////////////////////////////////////////////////////////////////////////
 
+// Verify verifies a signature of message under the Falcon-512 public key
+// polynomial pubkey. The hash-to-point variant is selected from the
+// signature header.
 func Verify(pubkey []int16, message []byte, signature []byte) bool {
-
-	//fmt.Println("\nmsg: ", message)
-	//fmt.Println("\nsignature as list: ", signature)
-	n := 512
-
-	salt := signature[HeadLen : HeadLen+SaltLen]
-	encS := signature[HeadLen+SaltLen:]
-
-	PubParam := GetParamSet(uint16(n))
-
-	var normSign uint32
-	normSign = 0
-	//var ng uint32
-	//ng = 0
-
-	var s1 []int16
-	// ss1 is dummy-array
-	ss1, err := internal.Decompress(encS, int(PubParam.sigbytelen-HeadLen-SaltLen), int(n))
-
-	/*fmt.Println("\nsigPart s1: ", ss1)*/
-
-	if err != nil {
-		fmt.Println("invalid encoding")
-		return false
-	}
-
-	for i := 0; i < len(ss1); i++ {
-		s1 = append(s1, int16(ss1[i]))
-	}
-
-	// compute s0 and normalize its coefficients in (-q/2, q/2]
-	hashed := hashToPoint(message, salt)
-	s0 := ntt.SubZq(hashed, ntt.MulZq(s1, pubkey))
-	//fmt.Println("\nQ: ", util.Q)
-
-	for i := 0; i < len(s0); i++ {
-		s0[i] = int16((s0[i]+(util.Q>>1))%util.Q - (util.Q >> 1))
-	}
-
-	for _, v := range s0 {
-		normSign += uint32(v) * uint32(v)
-	}
-	//fmt.Println("\ns0 sum: ", normSign)
-	for _, v := range s1 {
-		normSign += uint32(v) * uint32(v)
-	}
-
-	fmt.Println("\nsignature bound: ", PubParam.sigbound)
-	fmt.Println("normSign: ", normSign)
-
-	if normSign > PubParam.sigbound {
-		return false
-	}
-	return true
+	pubKey := &PublicKey{n: 512, h: pubkey}
+	return pubKey.verify(message, signature)
 }
 
-//////////////////////////////////////////////////////////////////////////////
-
-func VerifyBytes(inputBytes []byte) bool {
-
-	fmt.Println("input bytyes: ", inputBytes)
-
-	var h []int16
-	var message []byte
-	var signature []byte
-
-	j := 0
-	for i := 0; i < 1024; i++ {
-		var temp int16 = 0
-		for k := 0; k < 32; k++ {
-			temp += int16(inputBytes[i*32+k])
-		}
-		temp = temp << 8
-
-		i += 1
-		for k := 0; k < 32; k++ {
-			temp += int16(inputBytes[i*32+k])
-		}
-
-		h = append(h, temp)
-	}
-
-	fmt.Println("h: ", h)
-
-	j = 1024
-	for i := 0; i < 32; i++ {
-		var temp uint8 = 0
-		for k := 0; k < 32; k++ {
-			temp += uint8(inputBytes[i*32+j*32+k])
-		}
-		message = append(message, temp)
-	}
-
-	j = 1024 + 32
-	for i := 0; i < 666; i++ {
-		var temp uint8 = 0
-		for k := 0; k < 32; k++ {
-			temp += uint8(inputBytes[i*32+j*32+k])
-		}
-		signature = append(signature, temp)
-	}
-
-	n := 512
-	r := signature[HeadLen : HeadLen+SaltLen]
-	s := signature[HeadLen+SaltLen:]
-
-	PubParam := GetParamSet(uint16(n))
-
-	var normSign uint32
-	normSign = 0
-
-	var s2 []int16
-	// s2_temp is dummy-array
-	s2_temp, err := internal.Decompress(s, int(PubParam.sigbytelen-HeadLen-SaltLen), int(n))
-
-	if err != nil {
-		fmt.Println("invalid encoding")
-		return false
-	}
-
-	for i := 0; i < len(s2_temp); i++ {
-		s2 = append(s2, int16(s2_temp[i]))
-	}
-
-	// compute s1 and normalize its coefficients in (-q/2, q/2]
-	c := hashToPoint(message, r)
-	s1 := ntt.SubZq(c, ntt.MulZq(s2, h))
-
-	for i := 0; i < len(s1); i++ {
-		s1[i] = int16((s1[i]+(util.Q>>1))%util.Q - (util.Q >> 1))
-	}
-
-	for _, v := range s1 {
-		normSign += uint32(v) * uint32(v)
-	}
-	for _, v := range s2 {
-		normSign += uint32(v) * uint32(v)
-	}
+// Verify reports whether signature is a valid signature of message under
+// pubKey, in any of the signature formats.
+func (pubKey *PublicKey) Verify(message []byte, signature []byte) bool {
+	return pubKey.verify(message, signature)
+}
 
-	fmt.Println("\nsignature bound: ", PubParam.sigbound)
-	fmt.Println("normSign: ", normSign)
+// VerifyStrict verifies a signature of message like Verify, but accepts
+// only the canonical encoding of the signature in format: see
+// Options.Strict. It returns nil if and only if the signature is valid.
+func (pubKey *PublicKey) VerifyStrict(message, signature []byte, format SignatureFormat) error {
+	return pubKey.verifyStrict(message, signature, format)
+}
 
-	if normSign > PubParam.sigbound {
-		return false
-	}
-	return true
+// verifyPoint reports whether ||hashed - s1*h||^2 + ||s1||^2 is within the
+// signature bound of degree n.
+func verifyPoint(n uint16, h, hashed, s1 []int16) bool {
+	w, err := newWitness(n, h, hashed, s1)
+	return err == nil && w.Valid
 }
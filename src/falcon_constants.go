@@ -46,6 +46,17 @@ type PublicParameters struct {
 	sigbytelen uint16
 }
 
+// SignatureLen returns the bytelength of padded signatures, which is also
+// the maximum bytelength of compressed signatures.
+func (param PublicParameters) SignatureLen() int {
+	return int(param.sigbytelen)
+}
+
+// SignatureBound returns the upper bound on the squared norm of signatures.
+func (param PublicParameters) SignatureBound() uint32 {
+	return param.sigbound
+}
+
 var ParamSets = map[uint16]PublicParameters{
 	// FalconParam(2, 2)
 	2: {
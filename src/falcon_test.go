@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -120,6 +121,23 @@ func TestHashToPointPub(t *testing.T) {
 	log.Printf("public hashed value: %v", hashed)
 }
 
+func TestHashToPointCT(t *testing.T) {
+	for n := range ParamSets {
+		for i := 0; i < 50; i++ {
+			var salt [SaltLen]byte
+			message := make([]byte, i)
+			util.RandomBytes(salt[:])
+			util.RandomBytes(message)
+
+			want := hashToPointN(message, salt[:], n)
+			got := hashToPointCT(message, salt[:], n)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("n = %d: hashToPointCT(%x, %x) = %v, want %v", n, message, salt, got, want)
+			}
+		}
+	}
+}
+
 func TestBasisAndMatrix(t *testing.T) {
 	n := 16
 	priv, err := GeneratePrivateKey(uint16(n))
@@ -280,13 +298,3 @@ func TestSignVerify(t *testing.T) {
 		index++
 	}
 }
-
-func TestSignVerifyBytes(t *testing.T) {
-	var input = []byte{44, 232, 34, 46, 24, 223, 33, 65, 37, 226, 10, 241, 43, 176, 30, 40, 11, 228, 25, 151, 47, 137, 25, 95, 10, 48, 41, 105, 15, 125, 10, 48, 27, 19, 20, 146, 19, 151, 43, 182, 46, 128, 43, 233, 10, 175, 26, 250, 30, 151, 25, 200, 17, 148, 36, 143, 16, 149, 21, 60, 34, 70, 10, 29, 17, 209, 35, 23, 2, 184, 32, 140, 17, 198, 7, 204, 11, 39, 29, 151, 9, 125, 10, 224, 3, 101, 47, 251, 27, 236, 44, 63, 31, 64, 9, 102, 36, 206, 27, 91, 37, 221, 41, 162, 5, 6, 29, 193, 0, 240, 5, 185, 18, 213, 37, 255, 26, 237, 42, 160, 16, 224, 42, 195, 45, 55, 19, 156, 4, 222, 35, 143, 47, 196, 3, 214, 6, 116, 21, 66, 19, 202, 7, 192, 23, 81, 42, 206, 45, 80, 26, 172, 42, 33, 35, 114, 45, 42, 32, 235, 28, 216, 39, 241, 12, 74, 38, 156, 0, 227, 41, 32, 27, 2, 27, 100, 44, 154, 2, 106, 9, 40, 9, 138, 47, 86, 6, 54, 18, 51, 0, 9, 23, 166, 11, 158, 4, 38, 30, 209, 29, 4, 45, 32, 42, 203, 15, 113, 45, 130, 21, 112, 13, 32, 25, 19, 7, 226, 30, 175, 46, 161, 40, 33, 45, 240, 41, 123, 11, 130, 38, 99, 5, 111, 20, 168, 39, 97, 30, 199, 1, 180, 11, 5, 42, 224, 18, 111, 14, 221, 36, 103, 37, 158, 11, 105, 19, 55, 42, 254, 33, 28, 3, 54, 13, 34, 25, 88, 13, 211, 11, 150, 35, 106, 43, 191, 13, 253, 26, 110, 38, 146, 21, 56, 32, 38, 7, 112, 42, 134, 37, 64, 2, 138, 11, 201, 31, 77, 12, 201, 46, 223, 39, 59, 36, 72, 33, 213, 11, 185, 37, 237, 7, 152, 29, 31, 13, 108, 20, 54, 3, 119, 19, 183, 30, 30, 41, 50, 20, 229, 45, 66, 42, 118, 23, 167, 2, 75, 21, 80, 11, 97, 30, 172, 17, 217, 0, 89, 43, 234, 26, 0, 30, 92, 21, 98, 45, 36, 4, 50, 19, 153, 32, 61, 23, 215, 46, 72, 18, 108, 23, 238, 17, 53, 22, 19, 26, 189, 1, 113, 45, 220, 29, 179, 30, 66, 29, 160, 3, 147, 25, 50, 37, 70, 40, 254, 1, 0, 35, 164, 16, 10, 33, 250, 29, 194, 5, 251, 45, 23, 37, 41, 6, 175, 4, 96, 25, 1, 44, 55, 3, 179, 30, 134, 18, 41, 28, 174, 41, 25, 5, 99, 27, 202, 16, 240, 23, 165, 24, 77, 7, 122, 14, 168, 32, 3, 42, 194, 20, 88, 4, 235, 44, 106, 25, 16, 20, 11, 21, 5, 33, 234, 29, 172, 32, 89, 9, 180, 44, 75, 46, 12, 27, 146, 21, 177, 8, 225, 25, 49, 2, 96, 46, 71, 16, 100, 11, 85, 47, 13, 25, 203, 12, 184, 38, 206, 31, 50, 31, 178, 44, 249, 3, 139, 33, 189, 25, 223, 18, 60, 15, 198, 43, 229, 21, 161, 16, 187, 24, 244, 23, 246, 34, 226, 8, 80, 8, 80, 11, 147, 27, 92, 35, 13, 30, 82, 0, 171, 41, 95, 28, 28, 2, 233, 10, 26, 10, 73, 40, 181, 34, 187, 13, 72, 16, 128, 7, 215, 18, 115, 40, 122, 8, 56, 35, 16, 2, 210, 44, 177, 10, 92, 24, 181, 24, 97, 11, 172, 45, 228, 30, 175, 35, 80, 27, 176, 36, 191, 38, 42, 3, 99, 8, 29, 17, 10, 47, 78, 4, 58, 7, 58, 18, 254, 16, 234, 42, 233, 21, 3, 40, 243, 29, 253, 10, 59, 9, 51, 12, 180, 8, 55, 24, 254, 44, 224, 14, 198, 12, 173, 37, 84, 23, 19, 18, 130, 32, 142, 33, 139, 23, 251, 20, 223, 25, 95, 33, 50, 27, 192, 40, 95, 33, 84, 39, 175, 32, 116, 36, 48, 41, 120, 47, 237, 17, 2, 5, 150, 18, 230, 5, 228, 41, 63, 10, 91, 27, 44, 44, 233, 34, 48, 4, 107, 10, 231, 15, 171, 16, 157, 13, 110, 37, 26, 22, 82, 40, 205, 39, 86, 21, 104, 19, 155, 25, 73, 30, 45, 4, 237, 11, 250, 0, 198, 30, 135, 8, 198, 13, 168, 31, 128, 2, 227, 22, 234, 21, 193, 44, 192, 8, 196, 2, 156, 32, 203, 21, 69, 10, 212, 17, 56, 36, 77, 43, 6, 2, 249, 14, 134, 45, 51, 13, 76, 1, 112, 13, 251, 40, 81, 26, 80, 46, 99, 39, 203, 2, 17, 1, 24, 9, 64, 10, 8, 19, 68, 24, 61, 28, 92, 30, 112, 28, 37, 46, 143, 5, 101, 46, 187, 13, 174, 44, 99, 29, 33, 38, 222, 19, 28, 40, 133, 23, 69, 39, 23, 2, 199, 45, 29, 10, 11, 33, 81, 34, 230, 22, 116, 16, 13, 24, 65, 20, 182, 21, 190, 7, 178, 14, 241, 29, 121, 1, 95, 44, 255, 29, 121, 9, 199, 13, 239, 5, 169, 10, 253, 16, 87, 29, 193, 47, 22, 26, 28, 33, 52, 5, 117, 8, 180, 17, 51, 39, 85, 30, 121, 17, 65, 35, 244, 5, 84, 12, 12, 14, 124, 32, 90, 37, 113, 20, 218, 39, 109, 16, 138, 23, 117, 32, 17, 5, 245, 15, 1, 20, 84, 10, 53, 8, 219, 12, 36, 34, 244, 31, 217, 29, 107, 28, 156, 35, 227, 10, 10, 2, 172, 17, 70, 19, 196, 34, 64, 45, 99, 12, 142, 2, 195, 28, 7, 21, 3, 0, 169, 18, 166, 25, 108, 25, 110, 11, 205, 44, 250, 0, 179, 17, 157, 15, 91, 13, 125, 30, 92, 19, 128, 15, 203, 46, 253, 31, 103, 38, 38, 34, 48, 22, 98, 20, 178, 8, 7, 32, 178, 38, 26, 11, 56, 2, 219, 36, 72, 11, 154, 1, 59, 20, 161, 41, 136, 19, 56, 2, 97, 19, 253, 19, 117, 11, 224, 37, 205, 5, 250, 2, 183, 39, 192, 20, 140, 13, 8, 8, 72, 25, 52, 26, 116, 15, 71, 22, 8, 19, 4, 114, 108, 97, 97, 103, 113, 114, 111, 101, 114, 120, 100, 109, 104, 113, 110, 120, 115, 111, 98, 114, 121, 112, 100, 110, 113, 119, 117, 117, 114, 110, 112, 57, 1, 26, 2, 141, 57, 181, 194, 187, 190, 219, 37, 147, 126, 14, 4, 160, 143, 163, 209, 164, 53, 182, 49, 216, 106, 236, 181, 9, 221, 134, 56, 139, 6, 151, 31, 128, 77, 15, 173, 96, 26, 64, 213, 138, 60, 170, 113, 30, 142, 214, 209, 125, 220, 193, 108, 143, 48, 253, 183, 125, 65, 207, 70, 218, 186, 19, 28, 223, 82, 241, 228, 46, 171, 45, 133, 62, 216, 140, 237, 122, 20, 82, 13, 182, 85, 32, 99, 148, 151, 165, 75, 120, 99, 180, 218, 46, 147, 221, 153, 175, 240, 121, 48, 52, 209, 141, 226, 115, 75, 59, 105, 174, 76, 145, 236, 27, 35, 83, 108, 207, 154, 53, 207, 70, 39, 144, 51, 172, 241, 247, 150, 182, 144, 179, 184, 136, 57, 24, 131, 176, 46, 211, 141, 31, 163, 60, 8, 45, 111, 43, 59, 163, 247, 180, 185, 67, 159, 40, 200, 58, 198, 233, 74, 103, 145, 86, 159, 191, 167, 100, 78, 188, 178, 12, 211, 182, 246, 86, 81, 181, 144, 201, 72, 223, 6, 19, 129, 119, 249, 194, 195, 133, 199, 121, 236, 21, 130, 119, 133, 143, 37, 234, 6, 183, 235, 233, 220, 248, 90, 109, 89, 166, 191, 109, 41, 155, 6, 153, 137, 143, 103, 136, 34, 72, 216, 117, 14, 178, 124, 197, 194, 224, 180, 100, 29, 1, 74, 55, 169, 195, 107, 145, 173, 76, 92, 54, 123, 124, 137, 178, 29, 9, 15, 144, 83, 229, 246, 39, 59, 163, 7, 101, 112, 72, 215, 99, 64, 202, 243, 22, 73, 202, 4, 159, 180, 240, 215, 67, 214, 206, 230, 28, 29, 4, 129, 63, 211, 207, 226, 229, 122, 1, 19, 125, 17, 225, 41, 210, 59, 77, 254, 206, 177, 190, 29, 250, 115, 210, 190, 124, 52, 31, 143, 238, 107, 62, 230, 190, 101, 254, 185, 57, 135, 166, 215, 170, 124, 101, 11, 134, 217, 153, 13, 71, 191, 185, 96, 121, 149, 143, 218, 47, 87, 45, 52, 229, 41, 203, 65, 160, 19, 85, 49, 149, 79, 190, 236, 219, 138, 119, 221, 134, 102, 89, 250, 40, 241, 186, 177, 12, 109, 136, 174, 240, 95, 241, 5, 135, 44, 101, 59, 140, 145, 41, 18, 105, 68, 58, 1, 39, 185, 235, 205, 95, 143, 20, 151, 54, 147, 148, 105, 6, 24, 169, 123, 33, 31, 95, 202, 219, 40, 203, 101, 19, 156, 102, 206, 9, 1, 217, 36, 219, 59, 49, 90, 143, 79, 163, 6, 20, 168, 28, 95, 93, 74, 159, 134, 144, 60, 210, 236, 106, 225, 211, 107, 250, 202, 100, 31, 169, 8, 101, 230, 211, 37, 126, 40, 226, 99, 150, 41, 94, 19, 235, 1, 95, 61, 167, 85, 246, 172, 219, 122, 248, 82, 149, 52, 176, 103, 203, 68, 160, 167, 42, 154, 158, 167, 189, 31, 240, 254, 188, 14, 178, 69, 201, 237, 90, 92, 149, 35, 210, 248, 119, 183, 232, 225, 33, 222, 181, 92, 127, 74, 162, 69, 32, 72, 210, 18, 209, 126, 166, 145, 21, 175, 101, 160, 89, 110, 203, 20, 122, 232, 253, 161, 30, 8, 111, 35, 50, 249, 188, 253, 12, 71, 215, 85, 56, 196, 110, 122, 81, 246, 136, 99, 255, 51, 198, 43, 145, 97, 115, 46, 119, 250, 199, 193, 144, 245, 83, 151, 13, 130, 169, 136, 97, 89, 78, 115, 155, 164, 39, 215, 15, 7, 254, 74, 64, 201, 159, 30, 242, 217, 119, 98, 217, 45, 6, 81, 190, 172, 161, 106, 138, 34, 127, 80, 154, 218, 109, 131, 233, 13, 93, 250, 104, 94, 231, 219, 249, 14, 109, 178, 88, 88, 102, 178, 22, 226, 208, 246, 245, 51, 218, 147, 16, 255, 125, 19, 184, 179, 37, 200, 170, 20, 105, 217, 4, 137, 77, 137, 155, 168, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
-	output := VerifyBytes(input)
-	if output == true {
-		log.Printf("BYTE VERIFICATION SUCCESSFUL")
-	}
-
-	return
-}
@@ -0,0 +1,306 @@
+package falconssh
+
+/*
+allowed_signers files of ssh-keygen (see ALLOWED SIGNERS in ssh-keygen(1)).
+
+Each line holds "principals [options] key-type base64-key [comment]", where
+the principals are a comma-separated list of patterns and the options are
+cert-authority, namespaces="list", valid-after="time" and valid-before="time".
+*/
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrUnknownSigner is returned when no allowed signer matches a signature
+	ErrUnknownSigner = errors.New("falconssh: no matching allowed signer")
+)
+
+// AllowedSigner is a line of an allowed_signers file.
+type AllowedSigner struct {
+	// Principals is a comma-separated list of principal patterns.
+	Principals string
+	// CertAuthority marks the key of a certificate authority.
+	CertAuthority bool
+	// Namespaces is a comma-separated list of namespace patterns, empty
+	// when every namespace is allowed.
+	Namespaces string
+	// ValidAfter and ValidBefore bound the validity of the key when not zero.
+	ValidAfter  time.Time
+	ValidBefore time.Time
+	// Key is the public key in the SSH wire format.
+	Key []byte
+}
+
+// ParseAllowedSigners decodes an allowed_signers file. Empty lines and
+// comments starting with '#' are skipped.
+func ParseAllowedSigners(data []byte) ([]*AllowedSigner, error) {
+	var signers []*AllowedSigner
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		signer, err := parseAllowedSigner(line)
+		if err != nil {
+			return nil, fmt.Errorf("allowed signers line %d: %w", n, err)
+		}
+		signers = append(signers, signer)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return signers, nil
+}
+
+func parseAllowedSigner(line string) (*AllowedSigner, error) {
+	fields := splitQuoted(line)
+	if len(fields) < 3 {
+		return nil, ErrInvalidKey
+	}
+	signer := &AllowedSigner{Principals: unquote(fields[0])}
+	rest := fields[1:]
+	key, err := decodeKeyFields(rest)
+	if err != nil {
+		if err := signer.parseOptions(rest[0]); err != nil {
+			return nil, err
+		}
+		if key, err = decodeKeyFields(rest[1:]); err != nil {
+			return nil, err
+		}
+	}
+	signer.Key = key
+	return signer, nil
+}
+
+// decodeKeyFields decodes the "key-type base64-key" fields at the start of fields.
+func decodeKeyFields(fields []string) ([]byte, error) {
+	if len(fields) < 2 {
+		return nil, ErrInvalidKey
+	}
+	key, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	var w wireKey
+	if err := ssh.Unmarshal(key, &w); err != nil || w.Type != fields[0] {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+func (a *AllowedSigner) parseOptions(options string) error {
+	for _, option := range splitOptions(options) {
+		name, value, hasValue := strings.Cut(option, "=")
+		name, value = strings.ToLower(name), unquote(value)
+		var err error
+		switch name {
+		case "cert-authority":
+			a.CertAuthority = true
+		case "namespaces":
+			a.Namespaces = value
+		case "valid-after":
+			a.ValidAfter, err = parseSignerTime(value)
+		case "valid-before":
+			a.ValidBefore, err = parseSignerTime(value)
+		default:
+			return fmt.Errorf("unknown option %q", name)
+		}
+		if err != nil {
+			return err
+		}
+		if hasValue == (name == "cert-authority") {
+			return fmt.Errorf("invalid option %q", option)
+		}
+	}
+	return nil
+}
+
+// parseSignerTime decodes a YYYYMMDD[HHMM[SS]][Z] time, in UTC with the Z
+// suffix and in the local time zone otherwise.
+func parseSignerTime(value string) (time.Time, error) {
+	loc := time.Local
+	if strings.HasSuffix(value, "Z") || strings.HasSuffix(value, "z") {
+		loc = time.UTC
+		value = value[:len(value)-1]
+	}
+	var layout string
+	switch len(value) {
+	case 8:
+		layout = "20060102"
+	case 12:
+		layout = "200601021504"
+	case 14:
+		layout = "20060102150405"
+	default:
+		return time.Time{}, fmt.Errorf("invalid time %q", value)
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// ValidAt reports whether the key may be used at t.
+func (a *AllowedSigner) ValidAt(t time.Time) bool {
+	if !a.ValidAfter.IsZero() && t.Before(a.ValidAfter) {
+		return false
+	}
+	if !a.ValidBefore.IsZero() && t.After(a.ValidBefore) {
+		return false
+	}
+	return true
+}
+
+// MatchesPrincipal reports whether principal matches the principal patterns.
+func (a *AllowedSigner) MatchesPrincipal(principal string) bool {
+	return matchPatternList(principal, a.Principals)
+}
+
+// AllowsNamespace reports whether signatures for namespace are allowed.
+func (a *AllowedSigner) AllowsNamespace(namespace string) bool {
+	return a.Namespaces == "" || matchPatternList(namespace, a.Namespaces)
+}
+
+// matchesKey reports whether the line is the plain key of the signature and
+// is valid at t. Signatures by certificates are not supported, so
+// cert-authority lines never match.
+func (a *AllowedSigner) matchesKey(sig *SSHSig, t time.Time) bool {
+	return !a.CertAuthority && bytes.Equal(a.Key, sig.PublicKey.Marshal()) && a.ValidAt(t)
+}
+
+// FindPrincipals returns the principals of the allowed signers holding the
+// key of the signature at t, as "ssh-keygen -Y find-principals".
+func FindPrincipals(signers []*AllowedSigner, sig *SSHSig, t time.Time) []string {
+	var principals []string
+	for _, signer := range signers {
+		if signer.matchesKey(sig, t) {
+			principals = append(principals, signer.Principals)
+		}
+	}
+	return principals
+}
+
+// VerifyAllowed checks the signature of the message read from r for the
+// namespace and that an allowed signer of principal holds its key at t, as
+// "ssh-keygen -Y verify".
+func VerifyAllowed(signers []*AllowedSigner, sig *SSHSig, r io.Reader, principal, namespace string, t time.Time) error {
+	allowed := false
+	for _, signer := range signers {
+		if signer.matchesKey(sig, t) && signer.MatchesPrincipal(principal) && signer.AllowsNamespace(namespace) {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrUnknownSigner
+	}
+	return sig.Verify(r, namespace)
+}
+
+// matchPatternList matches s against a comma-separated list of patterns,
+// where a pattern starting with '!' rejects the matching strings.
+func matchPatternList(s, list string) bool {
+	matched := false
+	for _, pattern := range strings.Split(list, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		if negated {
+			pattern = pattern[1:]
+		}
+		if matchPattern(s, pattern) {
+			if negated {
+				return false
+			}
+			matched = true
+		}
+	}
+	return matched
+}
+
+// matchPattern matches s against a pattern where '*' matches any sequence
+// of characters and '?' any single character.
+func matchPattern(s, pattern string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if matchPattern(s[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+		}
+		s, pattern = s[1:], pattern[1:]
+	}
+	return s == ""
+}
+
+// splitQuoted splits a line on white space outside of double quotes.
+func splitQuoted(line string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			field.WriteRune(c)
+		case (c == ' ' || c == '\t') && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(c)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// splitOptions splits options on commas outside of double quotes.
+func splitOptions(options string) []string {
+	var out []string
+	quoted := false
+	start := 0
+	for i, c := range options {
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			out = append(out, options[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, options[start:])
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+// Package falconssh implements the OpenSSH formats of Falcon keys and
+// signatures, with the ssh-falcon512 and ssh-falcon1024 key types of
+// OQS-OpenSSH, as golang.org/x/crypto/ssh Signer and PublicKey values.
+//
+// Wire formats (RFC 4253, section 6.6):
+//
+//	public key:  string key type, string public key encoding (falcon.MarshalPublicKey)
+//	signature:   string key type, string compressed Falcon signature
+//
+// golang.org/x/crypto/ssh only negotiates the public key algorithms it knows:
+// its handshake parses host keys with ssh.ParsePublicKey and its server only
+// accepts user keys of its supportedPubKeyAuthAlgos list, neither of which
+// can be extended. The keys of this package therefore work with the APIs
+// that take Signer and PublicKey values (certificates, authorized keys,
+// fingerprints, agents), while a connection authenticated with Falcon keys
+// needs an SSH implementation that knows these key types.
+package falconssh
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// KeyAlgoFalcon512 is the key type and signature format of Falcon-512 keys.
+	KeyAlgoFalcon512 = "ssh-falcon512"
+	// KeyAlgoFalcon1024 is the key type and signature format of Falcon-1024 keys.
+	KeyAlgoFalcon1024 = "ssh-falcon1024"
+)
+
+var (
+	// ErrUnsupportedKeyType is returned when a key type is not a Falcon one
+	ErrUnsupportedKeyType = errors.New("falconssh: unsupported key type")
+	// ErrInvalidKey is returned when a key cannot be decoded
+	ErrInvalidKey = errors.New("falconssh: invalid key")
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("falconssh: invalid signature")
+)
+
+// KeyType returns the SSH key type of Falcon keys of degree n.
+func KeyType(n uint16) (string, error) {
+	switch n {
+	case 512:
+		return KeyAlgoFalcon512, nil
+	case 1024:
+		return KeyAlgoFalcon1024, nil
+	}
+	return "", ErrUnsupportedKeyType
+}
+
+// degree returns the degree of the keys of an SSH key type.
+func degree(keyType string) (uint16, error) {
+	switch keyType {
+	case KeyAlgoFalcon512:
+		return 512, nil
+	case KeyAlgoFalcon1024:
+		return 1024, nil
+	}
+	return 0, ErrUnsupportedKeyType
+}
+
+// PublicKey is a Falcon public key implementing ssh.PublicKey.
+type PublicKey struct {
+	key     *falcon.PublicKey
+	keyType string
+	encoded []byte
+}
+
+// wireKey is the wire format of public keys and signatures.
+type wireKey struct {
+	Type string
+	Data []byte
+	Rest []byte `ssh:"rest"`
+}
+
+// NewPublicKey returns the SSH public key of a Falcon-512 or Falcon-1024 key.
+func NewPublicKey(pubKey *falcon.PublicKey) (*PublicKey, error) {
+	keyType, err := KeyType(pubKey.Degree())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{key: pubKey, keyType: keyType, encoded: encoded}, nil
+}
+
+// ParsePublicKey decodes a public key in the SSH wire format.
+func ParsePublicKey(in []byte) (*PublicKey, error) {
+	var w wireKey
+	if err := ssh.Unmarshal(in, &w); err != nil || len(w.Rest) > 0 {
+		return nil, ErrInvalidKey
+	}
+	n, err := degree(w.Type)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := falcon.ParsePublicKey(w.Data)
+	if err != nil || pubKey.Degree() != n {
+		return nil, ErrInvalidKey
+	}
+	return &PublicKey{key: pubKey, keyType: w.Type, encoded: w.Data}, nil
+}
+
+// FalconPublicKey returns the Falcon public key.
+func (pub *PublicKey) FalconPublicKey() *falcon.PublicKey {
+	return pub.key
+}
+
+// Type returns the SSH key type.
+func (pub *PublicKey) Type() string {
+	return pub.keyType
+}
+
+// Marshal returns the SSH wire format of the key.
+func (pub *PublicKey) Marshal() []byte {
+	return ssh.Marshal(wireKey{Type: pub.keyType, Data: pub.encoded})
+}
+
+// Verify checks a signature of data in the format of the key type.
+func (pub *PublicKey) Verify(data []byte, sig *ssh.Signature) error {
+	if sig.Format != pub.keyType || len(sig.Rest) > 0 {
+		return ErrInvalidSignature
+	}
+	if !pub.key.Verify(data, sig.Blob) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Signer is a Falcon private key implementing ssh.AlgorithmSigner.
+type Signer struct {
+	key *falcon.PrivateKey
+	pub *PublicKey
+}
+
+// NewSigner returns the SSH signer of a Falcon-512 or Falcon-1024 private key.
+func NewSigner(privKey *falcon.PrivateKey) (*Signer, error) {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := NewPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{key: privKey, pub: pub}, nil
+}
+
+// PublicKey returns the public key of the signer.
+func (s *Signer) PublicKey() ssh.PublicKey {
+	return s.pub
+}
+
+// Sign signs data with a compressed Falcon signature. Falcon draws its
+// randomness from crypto/rand, so rand is not used.
+func (s *Signer) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+// SignWithAlgorithm is Sign; algorithm must be empty or the key type.
+func (s *Signer) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	if algorithm != "" && algorithm != s.pub.keyType {
+		return nil, ErrUnsupportedKeyType
+	}
+	blob, err := s.key.SignFormat(data, falcon.FormatCompressed)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.Signature{Format: s.pub.keyType, Blob: blob}, nil
+}
+
+// ParseAuthorizedKey parses a Falcon public key from an authorized_keys line
+// "key-type base64-key [comment]". Options before the key type are not supported.
+func ParseAuthorizedKey(line []byte) (*PublicKey, string, error) {
+	fields := strings.Fields(string(bytes.TrimSpace(line)))
+	if len(fields) < 2 {
+		return nil, "", ErrInvalidKey
+	}
+	if _, err := degree(fields[0]); err != nil {
+		return nil, "", err
+	}
+	wire, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, "", ErrInvalidKey
+	}
+	pub, err := ParsePublicKey(wire)
+	if err != nil {
+		return nil, "", err
+	}
+	if pub.keyType != fields[0] {
+		return nil, "", ErrInvalidKey
+	}
+	return pub, strings.Join(fields[2:], " "), nil
+}
@@ -0,0 +1,296 @@
+package falconssh
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/ssh"
+)
+
+func mustRead(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func loadSigner(t *testing.T) *Signer {
+	t.Helper()
+	signer, err := ParsePrivateKey(mustRead(t, "id_falcon512"))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey returned %v", err)
+	}
+	return signer
+}
+
+func TestAuthorizedKey(t *testing.T) {
+	line := mustRead(t, "id_falcon512.pub")
+	pub, comment, err := ParseAuthorizedKey(line)
+	if err != nil {
+		t.Fatalf("ParseAuthorizedKey returned %v", err)
+	}
+	if pub.Type() != KeyAlgoFalcon512 || comment != "falcon512@example.com" {
+		t.Errorf("ParseAuthorizedKey = %q, %q", pub.Type(), comment)
+	}
+	fields := strings.Fields(string(line))
+	if got := base64.StdEncoding.EncodeToString(pub.Marshal()); got != fields[1] {
+		t.Error("Marshal does not round-trip the authorized_keys entry")
+	}
+	want := ssh.MarshalAuthorizedKey(pub)
+	if !bytes.HasPrefix(line, want[:len(want)-1]) {
+		t.Error("ssh.MarshalAuthorizedKey does not match the authorized_keys entry")
+	}
+
+	// The key type is repeated inside the wire format
+	sum := sha256.Sum256(pub.Marshal())
+	if got := ssh.FingerprintSHA256(pub); got != "SHA256:"+base64.RawStdEncoding.EncodeToString(sum[:]) {
+		t.Errorf("FingerprintSHA256 = %s", got)
+	}
+	if !bytes.HasPrefix(pub.Marshal(), []byte("\x00\x00\x00\x0dssh-falcon512\x00\x00\x03\x81\x09")) {
+		t.Error("unexpected wire format prefix")
+	}
+
+	signer := loadSigner(t)
+	if !bytes.Equal(signer.PublicKey().Marshal(), pub.Marshal()) {
+		t.Error("the private key file does not match the public key file")
+	}
+
+	for _, bad := range []string{
+		"",
+		"ssh-falcon512",
+		"ssh-ed25519 " + fields[1],
+		"ssh-falcon1024 " + fields[1],
+		"ssh-falcon512 !!!",
+		"ssh-falcon512 " + base64.StdEncoding.EncodeToString(pub.Marshal()[:100]),
+	} {
+		if _, _, err := ParseAuthorizedKey([]byte(bad)); err == nil {
+			t.Errorf("ParseAuthorizedKey(%.30q) succeeded", bad)
+		}
+	}
+}
+
+func TestPrivateKeyFile(t *testing.T) {
+	data := mustRead(t, "id_falcon512")
+	privKey, comment, err := ParseRawPrivateKey(data)
+	if err != nil {
+		t.Fatalf("ParseRawPrivateKey returned %v", err)
+	}
+	if comment != "falcon512@example.com" {
+		t.Errorf("comment = %q", comment)
+	}
+	want, err := falcon.ParsePrivateKeyPEM(mustRead(t, "../../testdata/falcon512_priv.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEnc, _ := falcon.MarshalPrivateKey(want)
+	gotEnc, _ := falcon.MarshalPrivateKey(privKey)
+	if !bytes.Equal(gotEnc, wantEnc) {
+		t.Error("ParseRawPrivateKey returned a different key")
+	}
+
+	for _, n := range []uint16{512, 1024} {
+		privKey, _, err := falcon.NewKeyPair(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := MarshalPrivateKey(privKey, "")
+		if err != nil {
+			t.Fatalf("MarshalPrivateKey(%d) returned %v", n, err)
+		}
+		signer, err := ParsePrivateKey(out)
+		if err != nil {
+			t.Fatalf("ParsePrivateKey(%d) returned %v", n, err)
+		}
+		keyType, _ := KeyType(n)
+		if signer.PublicKey().Type() != keyType {
+			t.Errorf("Type() = %s, want %s", signer.PublicKey().Type(), keyType)
+		}
+	}
+
+	if _, err := ParsePrivateKey(mustRead(t, "id_falcon512.pub")); err != ErrInvalidPEM {
+		t.Errorf("ParsePrivateKey(public key) returned %v, want %v", err, ErrInvalidPEM)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	var signer ssh.AlgorithmSigner = loadSigner(t)
+	data := []byte("data to sign")
+	sig, err := signer.Sign(rand.Reader, data)
+	if err != nil {
+		t.Fatalf("Sign returned %v", err)
+	}
+	if sig.Format != KeyAlgoFalcon512 {
+		t.Errorf("Format = %s", sig.Format)
+	}
+	pub := signer.PublicKey()
+	if err := pub.Verify(data, sig); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+	if err := pub.Verify([]byte("other data"), sig); err != ErrInvalidSignature {
+		t.Errorf("Verify(other data) returned %v", err)
+	}
+	if err := pub.Verify(data, &ssh.Signature{Format: KeyAlgoFalcon1024, Blob: sig.Blob}); err != ErrInvalidSignature {
+		t.Errorf("Verify(wrong format) returned %v", err)
+	}
+
+	// Signatures survive the wire format and the parsed public key
+	var wire ssh.Signature
+	if err := ssh.Unmarshal(ssh.Marshal(sig), &wire); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePublicKey(pub.Marshal())
+	if err != nil {
+		t.Fatalf("ParsePublicKey returned %v", err)
+	}
+	if err := parsed.Verify(data, &wire); err != nil {
+		t.Errorf("Verify after round-trip returned %v", err)
+	}
+
+	if _, err := signer.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoED25519); err != ErrUnsupportedKeyType {
+		t.Errorf("SignWithAlgorithm(ssh-ed25519) returned %v", err)
+	}
+}
+
+// The signed data of a publickey user authentication request (RFC 4252,
+// section 7) verifies with the authorized key.
+func TestUserAuthSignedData(t *testing.T) {
+	signer := loadSigner(t)
+	authorized, _, err := ParseAuthorizedKey(mustRead(t, "id_falcon512.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := ssh.Marshal(struct {
+		Session   []byte
+		Type      byte
+		User      string
+		Service   string
+		Method    string
+		Sign      bool
+		Algorithm string
+		PubKey    []byte
+	}{[]byte("session identifier"), 50, "alice", "ssh-connection", "publickey", true, KeyAlgoFalcon512, signer.PublicKey().Marshal()})
+	sig, err := signer.Sign(rand.Reader, signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := authorized.Verify(signed, sig); err != nil {
+		t.Errorf("Verify returned %v", err)
+	}
+}
+
+// An x/crypto/ssh server does not accept Falcon user keys, as the package
+// documentation states: the handshake fails before the public key callback.
+func TestServerRejectsFalconUserKey(t *testing.T) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	called := make(chan bool, 1)
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			called <- true
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	// Both sides write their version line first, which blocks on net.Pipe
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no loopback listener: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		serverConn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer serverConn.Close()
+		if conn, _, _, err := ssh.NewServerConn(serverConn, config); err == nil {
+			conn.Close()
+		}
+	}()
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientConn.Close()
+	conn, _, _, err := ssh.NewClientConn(clientConn, "pipe", &ssh.ClientConfig{
+		User:            "alice",
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(loadSigner(t))},
+		HostKeyCallback: ssh.FixedHostKey(hostSigner.PublicKey()),
+	})
+	if err == nil {
+		conn.Close()
+		t.Fatal("the server authenticated a Falcon user key")
+	}
+	if !strings.Contains(err.Error(), "unable to authenticate") {
+		t.Errorf("handshake failed with %v, want an authentication failure", err)
+	}
+	select {
+	case <-called:
+		t.Error("the server passed a Falcon user key to its public key callback")
+	default:
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	ca := loadSigner(t)
+	// x/crypto only knows the certificate types of its own key types
+	userPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := ssh.NewPublicKey(userPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	cert := &ssh.Certificate{
+		Key:             user,
+		Serial:          1,
+		CertType:        ssh.UserCert,
+		KeyId:           "alice",
+		ValidPrincipals: []string{"alice"},
+		ValidAfter:      uint64(now.Add(-time.Hour).Unix()),
+		ValidBefore:     uint64(now.Add(time.Hour).Unix()),
+	}
+	if err := cert.SignCert(rand.Reader, ca); err != nil {
+		t.Fatalf("SignCert returned %v", err)
+	}
+	if cert.Signature.Format != KeyAlgoFalcon512 {
+		t.Errorf("signature format = %s", cert.Signature.Format)
+	}
+
+	checker := &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return bytes.Equal(auth.Marshal(), ca.PublicKey().Marshal())
+		},
+	}
+	if err := checker.CheckCert("alice", cert); err != nil {
+		t.Errorf("CheckCert returned %v", err)
+	}
+	if err := checker.CheckCert("bob", cert); err == nil {
+		t.Error("CheckCert accepted a certificate for another principal")
+	}
+	cert.Serial = 2
+	if err := checker.CheckCert("alice", cert); err == nil {
+		t.Error("CheckCert accepted a modified certificate")
+	}
+}
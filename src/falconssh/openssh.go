@@ -0,0 +1,162 @@
+package falconssh
+
+/*
+openssh-key-v1 private key files (PROTOCOL.key of OpenSSH).
+
+The private section of a Falcon key holds, after the key type, the public key
+encoding and the private key encoding of falcon.MarshalPrivateKey, as written
+by OQS-OpenSSH. Only unencrypted files (cipher and KDF "none") are supported.
+*/
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	pemTypeOpenSSH = "OPENSSH PRIVATE KEY"
+	opensshMagic   = "openssh-key-v1\x00"
+	cipherNone     = "none"
+	blockSize      = 8
+)
+
+var (
+	// ErrEncryptedKey is returned when a private key file is encrypted
+	ErrEncryptedKey = errors.New("falconssh: encrypted private keys are not supported")
+	// ErrInvalidPEM is returned when no OpenSSH private key PEM block is found
+	ErrInvalidPEM = errors.New("falconssh: no OPENSSH PRIVATE KEY block found")
+)
+
+type opensshKey struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+type opensshPrivateSection struct {
+	Check1  uint32
+	Check2  uint32
+	Keytype string
+	Pub     []byte
+	Priv    []byte
+	Comment string
+	Pad     []byte `ssh:"rest"`
+}
+
+// MarshalPrivateKey returns the unencrypted openssh-key-v1 PEM encoding of a
+// Falcon-512 or Falcon-1024 private key.
+func MarshalPrivateKey(privKey *falcon.PrivateKey, comment string) ([]byte, error) {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := NewPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := falcon.MarshalPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	checkInt := binary.BigEndian.Uint32(check[:])
+	section := opensshPrivateSection{
+		Check1:  checkInt,
+		Check2:  checkInt,
+		Keytype: pub.keyType,
+		Pub:     pub.encoded,
+		Priv:    encoded,
+		Comment: comment,
+	}
+	// The section is padded with 1, 2, 3, ... to a multiple of the block size
+	n := len(ssh.Marshal(section))
+	for i := 0; (n+i)%blockSize != 0; i++ {
+		section.Pad = append(section.Pad, byte(i+1))
+	}
+	key := opensshKey{
+		CipherName:   cipherNone,
+		KdfName:      cipherNone,
+		NumKeys:      1,
+		PubKey:       pub.Marshal(),
+		PrivKeyBlock: ssh.Marshal(section),
+	}
+	data := append([]byte(opensshMagic), ssh.Marshal(key)...)
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypeOpenSSH, Bytes: data}), nil
+}
+
+// ParseRawPrivateKey decodes an unencrypted openssh-key-v1 PEM file holding
+// a Falcon private key, and returns the key and its comment.
+func ParseRawPrivateKey(pemBytes []byte) (*falcon.PrivateKey, string, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil || block.Type != pemTypeOpenSSH {
+		return nil, "", ErrInvalidPEM
+	}
+	data := block.Bytes
+	if len(data) < len(opensshMagic) || string(data[:len(opensshMagic)]) != opensshMagic {
+		return nil, "", ErrInvalidKey
+	}
+	var key opensshKey
+	if err := ssh.Unmarshal(data[len(opensshMagic):], &key); err != nil {
+		return nil, "", ErrInvalidKey
+	}
+	if key.CipherName != cipherNone || key.KdfName != cipherNone {
+		return nil, "", ErrEncryptedKey
+	}
+	if key.NumKeys != 1 || len(key.PrivKeyBlock)%blockSize != 0 {
+		return nil, "", ErrInvalidKey
+	}
+	pub, err := ParsePublicKey(key.PubKey)
+	if err != nil {
+		return nil, "", err
+	}
+	var section opensshPrivateSection
+	if err := ssh.Unmarshal(key.PrivKeyBlock, &section); err != nil {
+		return nil, "", ErrInvalidKey
+	}
+	if section.Check1 != section.Check2 || section.Keytype != pub.keyType {
+		return nil, "", ErrInvalidKey
+	}
+	for i, b := range section.Pad {
+		if int(b) != i+1 || i >= blockSize {
+			return nil, "", ErrInvalidKey
+		}
+	}
+	if string(section.Pub) != string(pub.encoded) {
+		return nil, "", ErrInvalidKey
+	}
+	privKey, err := falcon.ParsePrivateKey(section.Priv)
+	if err != nil {
+		return nil, "", ErrInvalidKey
+	}
+	// The private key must match the public key of the file
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, "", ErrInvalidKey
+	}
+	derived, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil || string(derived) != string(pub.encoded) {
+		return nil, "", ErrInvalidKey
+	}
+	return privKey, section.Comment, nil
+}
+
+// ParsePrivateKey decodes an unencrypted openssh-key-v1 PEM file holding a
+// Falcon private key and returns its signer.
+func ParsePrivateKey(pemBytes []byte) (*Signer, error) {
+	privKey, _, err := ParseRawPrivateKey(pemBytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(privKey)
+}
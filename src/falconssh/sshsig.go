@@ -0,0 +1,242 @@
+package falconssh
+
+/*
+SSHSIG detached signatures (PROTOCOL.sshsig of OpenSSH), as made by
+"ssh-keygen -Y sign" and used by git with gpg.format=ssh.
+
+The signature blob is
+
+	byte[6]  "SSHSIG"
+	uint32   version (1)
+	string   public key
+	string   namespace
+	string   reserved
+	string   hash algorithm
+	string   signature
+
+where the signature is over "SSHSIG", the namespace, the reserved field, the
+hash algorithm and the hash of the message.
+*/
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshsigMagic   = "SSHSIG"
+	sshsigVersion = 1
+
+	sshsigArmorBegin = "-----BEGIN SSH SIGNATURE-----"
+	sshsigArmorEnd   = "-----END SSH SIGNATURE-----"
+	sshsigLineLen    = 70
+
+	// HashSHA256 is the SHA-256 message hash of SSHSIG signatures.
+	HashSHA256 = "sha256"
+	// HashSHA512 is the SHA-512 message hash of SSHSIG signatures, the default.
+	HashSHA512 = "sha512"
+)
+
+var (
+	// ErrInvalidSSHSig is returned when an SSHSIG signature cannot be decoded
+	ErrInvalidSSHSig = errors.New("falconssh: invalid SSHSIG signature")
+	// ErrUnsupportedHash is returned for message hashes other than sha256 and sha512
+	ErrUnsupportedHash = errors.New("falconssh: unsupported SSHSIG hash algorithm")
+	// ErrNamespaceMismatch is returned when a signature was made for another namespace
+	ErrNamespaceMismatch = errors.New("falconssh: SSHSIG namespace mismatch")
+)
+
+// SSHSig is an SSHSIG detached signature.
+type SSHSig struct {
+	PublicKey     ssh.PublicKey
+	Namespace     string
+	HashAlgorithm string
+	Signature     *ssh.Signature
+}
+
+type sshsigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+type sshsigSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+func newHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case HashSHA256:
+		return sha256.New(), nil
+	case HashSHA512:
+		return sha512.New(), nil
+	}
+	return nil, ErrUnsupportedHash
+}
+
+// signedData returns the data signed for a message read from r.
+func signedData(r io.Reader, namespace, hashAlgorithm string) ([]byte, error) {
+	h, err := newHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	data := ssh.Marshal(sshsigSignedData{
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Hash:          h.Sum(nil),
+	})
+	return append([]byte(sshsigMagic), data...), nil
+}
+
+// SignSSHSig signs the message read from r for the namespace with the given
+// hash algorithm, sha512 if empty. The signer may be any ssh.Signer.
+func SignSSHSig(signer ssh.Signer, r io.Reader, namespace, hashAlgorithm string) (*SSHSig, error) {
+	if namespace == "" {
+		return nil, ErrNamespaceMismatch
+	}
+	if hashAlgorithm == "" {
+		hashAlgorithm = HashSHA512
+	}
+	data, err := signedData(r, namespace, hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	var sig *ssh.Signature
+	if as, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// RSA signatures use SHA-512, as in ssh-keygen
+		sig, err = as.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SSHSig{
+		PublicKey:     signer.PublicKey(),
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Signature:     sig,
+	}, nil
+}
+
+// Verify checks the signature of the message read from r for the namespace.
+func (s *SSHSig) Verify(r io.Reader, namespace string) error {
+	if s.Namespace != namespace {
+		return ErrNamespaceMismatch
+	}
+	data, err := signedData(r, s.Namespace, s.HashAlgorithm)
+	if err != nil {
+		return err
+	}
+	if err := s.PublicKey.Verify(data, s.Signature); err != nil {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Marshal returns the binary encoding of the signature.
+func (s *SSHSig) Marshal() []byte {
+	blob := ssh.Marshal(sshsigBlob{
+		Version:       sshsigVersion,
+		PublicKey:     s.PublicKey.Marshal(),
+		Namespace:     s.Namespace,
+		HashAlgorithm: s.HashAlgorithm,
+		Signature:     ssh.Marshal(s.Signature),
+	})
+	return append([]byte(sshsigMagic), blob...)
+}
+
+// MarshalArmored returns the armored encoding of the signature, as written
+// by ssh-keygen.
+func (s *SSHSig) MarshalArmored() []byte {
+	encoded := base64.StdEncoding.EncodeToString(s.Marshal())
+	var b bytes.Buffer
+	b.WriteString(sshsigArmorBegin + "\n")
+	for len(encoded) > sshsigLineLen {
+		b.WriteString(encoded[:sshsigLineLen] + "\n")
+		encoded = encoded[sshsigLineLen:]
+	}
+	b.WriteString(encoded + "\n")
+	b.WriteString(sshsigArmorEnd + "\n")
+	return b.Bytes()
+}
+
+// ParseSSHSig decodes a binary SSHSIG signature.
+func ParseSSHSig(data []byte) (*SSHSig, error) {
+	if !bytes.HasPrefix(data, []byte(sshsigMagic)) {
+		return nil, ErrInvalidSSHSig
+	}
+	var blob sshsigBlob
+	if err := ssh.Unmarshal(data[len(sshsigMagic):], &blob); err != nil {
+		return nil, ErrInvalidSSHSig
+	}
+	if blob.Version != sshsigVersion {
+		return nil, ErrInvalidSSHSig
+	}
+	if _, err := newHash(blob.HashAlgorithm); err != nil {
+		return nil, err
+	}
+	pub, err := parseAnyPublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sig := new(ssh.Signature)
+	if err := ssh.Unmarshal(blob.Signature, sig); err != nil {
+		return nil, ErrInvalidSSHSig
+	}
+	return &SSHSig{
+		PublicKey:     pub,
+		Namespace:     blob.Namespace,
+		HashAlgorithm: blob.HashAlgorithm,
+		Signature:     sig,
+	}, nil
+}
+
+// ParseArmoredSSHSig decodes an armored SSHSIG signature.
+func ParseArmoredSSHSig(data []byte) (*SSHSig, error) {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, sshsigArmorBegin) || !strings.HasSuffix(text, sshsigArmorEnd) {
+		return nil, ErrInvalidSSHSig
+	}
+	text = text[len(sshsigArmorBegin) : len(text)-len(sshsigArmorEnd)]
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+	if err != nil {
+		return nil, ErrInvalidSSHSig
+	}
+	return ParseSSHSig(decoded)
+}
+
+// parseAnyPublicKey decodes a Falcon public key or a key type known to
+// x/crypto/ssh from its wire format.
+func parseAnyPublicKey(in []byte) (ssh.PublicKey, error) {
+	var w wireKey
+	if err := ssh.Unmarshal(in, &w); err != nil {
+		return nil, ErrInvalidKey
+	}
+	if _, err := degree(w.Type); err == nil {
+		pub, err := ParsePublicKey(in)
+		if err != nil {
+			return nil, err
+		}
+		return pub, nil
+	}
+	return ssh.ParsePublicKey(in)
+}
@@ -0,0 +1,176 @@
+package falconssh
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHSig(t *testing.T) {
+	signer := loadSigner(t)
+	message := mustRead(t, "message.txt")
+	for _, hashAlgorithm := range []string{"", HashSHA256, HashSHA512} {
+		sig, err := SignSSHSig(signer, bytes.NewReader(message), "git", hashAlgorithm)
+		if err != nil {
+			t.Fatalf("SignSSHSig(%q) returned %v", hashAlgorithm, err)
+		}
+		armored := sig.MarshalArmored()
+		for _, line := range strings.Split(string(armored), "\n") {
+			if len(line) > sshsigLineLen {
+				t.Errorf("armored line of %d characters", len(line))
+			}
+		}
+		parsed, err := ParseArmoredSSHSig(armored)
+		if err != nil {
+			t.Fatalf("ParseArmoredSSHSig returned %v", err)
+		}
+		if err := parsed.Verify(bytes.NewReader(message), "git"); err != nil {
+			t.Errorf("Verify returned %v", err)
+		}
+		if err := parsed.Verify(bytes.NewReader(message), "file"); err != ErrNamespaceMismatch {
+			t.Errorf("Verify(file) returned %v, want %v", err, ErrNamespaceMismatch)
+		}
+		if err := parsed.Verify(strings.NewReader("other message"), "git"); err != ErrInvalidSignature {
+			t.Errorf("Verify(other message) returned %v, want %v", err, ErrInvalidSignature)
+		}
+	}
+
+	if _, err := SignSSHSig(signer, bytes.NewReader(message), "git", "md5"); err != ErrUnsupportedHash {
+		t.Errorf("SignSSHSig(md5) returned %v, want %v", err, ErrUnsupportedHash)
+	}
+	if _, err := SignSSHSig(signer, bytes.NewReader(message), "", ""); err != ErrNamespaceMismatch {
+		t.Errorf("SignSSHSig without namespace returned %v, want %v", err, ErrNamespaceMismatch)
+	}
+}
+
+// The signatures of testdata were made by this package for the Falcon key and
+// by ssh-keygen -Y sign for the Ed25519 key.
+func TestSSHSigTestdata(t *testing.T) {
+	message := mustRead(t, "message.txt")
+	tests := []struct {
+		file, namespace, keyType string
+	}{
+		{"message_falcon512.sig", "git", KeyAlgoFalcon512},
+		{"message_ed25519.sig", "file", ssh.KeyAlgoED25519},
+	}
+	for _, tt := range tests {
+		sig, err := ParseArmoredSSHSig(mustRead(t, tt.file))
+		if err != nil {
+			t.Fatalf("ParseArmoredSSHSig(%s) returned %v", tt.file, err)
+		}
+		if sig.PublicKey.Type() != tt.keyType || sig.Namespace != tt.namespace || sig.HashAlgorithm != HashSHA512 {
+			t.Errorf("%s: unexpected signature %s %s %s", tt.file, sig.PublicKey.Type(), sig.Namespace, sig.HashAlgorithm)
+		}
+		if err := sig.Verify(bytes.NewReader(message), tt.namespace); err != nil {
+			t.Errorf("%s: Verify returned %v", tt.file, err)
+		}
+		blob, err := ParseSSHSig(sig.Marshal())
+		if err != nil || !bytes.Equal(blob.Marshal(), sig.Marshal()) {
+			t.Errorf("%s: Marshal does not round-trip", tt.file)
+		}
+	}
+
+	armored := mustRead(t, "message_falcon512.sig")
+	for _, bad := range [][]byte{
+		nil,
+		armored[:40],
+		bytes.Replace(armored, []byte("SIGNATURE-----\n"), []byte("SIGNATURE-----\n!"), 1),
+		bytes.Replace(armored, []byte("U1NIU0lH"), []byte("U1NIU0lI"), 1),
+	} {
+		if _, err := ParseArmoredSSHSig(bad); err == nil {
+			t.Errorf("ParseArmoredSSHSig(%.40q) succeeded", bad)
+		}
+	}
+}
+
+func TestAllowedSigners(t *testing.T) {
+	signers, err := ParseAllowedSigners(mustRead(t, "allowed_signers"))
+	if err != nil {
+		t.Fatalf("ParseAllowedSigners returned %v", err)
+	}
+	if len(signers) != 2 {
+		t.Fatalf("ParseAllowedSigners returned %d signers, want 2", len(signers))
+	}
+	if signers[0].Namespaces != "git,file" || signers[1].Principals != "ed25519@example.com" {
+		t.Errorf("unexpected signers %+v", signers)
+	}
+	if !signers[1].ValidBefore.Equal(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ValidBefore = %v", signers[1].ValidBefore)
+	}
+
+	falconSig, err := ParseArmoredSSHSig(mustRead(t, "message_falcon512.sig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	edSig, err := ParseArmoredSSHSig(mustRead(t, "message_ed25519.sig"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	if got := FindPrincipals(signers, falconSig, now); len(got) != 1 || got[0] != "falcon512@example.com,*@falcon.example" {
+		t.Errorf("FindPrincipals = %q", got)
+	}
+	if got := FindPrincipals(signers, edSig, now.AddDate(5, 0, 0)); len(got) != 0 {
+		t.Errorf("FindPrincipals after valid-before = %q", got)
+	}
+
+	message := mustRead(t, "message.txt")
+	tests := []struct {
+		sig       *SSHSig
+		principal string
+		namespace string
+		err       error
+	}{
+		{falconSig, "falcon512@example.com", "git", nil},
+		{falconSig, "dev@falcon.example", "git", nil},
+		{falconSig, "dev@example.com", "git", ErrUnknownSigner},
+		{falconSig, "falcon512@example.com", "mail", ErrUnknownSigner},
+		{edSig, "ed25519@example.com", "file", nil},
+		{edSig, "falcon512@example.com", "file", ErrUnknownSigner},
+	}
+	for _, tt := range tests {
+		err := VerifyAllowed(signers, tt.sig, bytes.NewReader(message), tt.principal, tt.namespace, now)
+		if err != tt.err {
+			t.Errorf("VerifyAllowed(%s, %s) returned %v, want %v", tt.principal, tt.namespace, err, tt.err)
+		}
+	}
+
+	for _, bad := range []string{
+		"alice",
+		"alice ssh-ed25519",
+		"alice ssh-ed25519 AAAA",
+		"alice unknown-option ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOaIdUQ",
+		`alice valid-after="2026" ` + strings.Join(strings.Fields(string(mustRead(t, "id_falcon512.pub")))[:2], " "),
+	} {
+		if _, err := ParseAllowedSigners([]byte(bad)); err == nil {
+			t.Errorf("ParseAllowedSigners(%.40q) succeeded", bad)
+		}
+	}
+}
+
+func TestMatchPatternList(t *testing.T) {
+	tests := []struct {
+		s, list string
+		want    bool
+	}{
+		{"alice", "alice", true},
+		{"alice", "bob,alice", true},
+		{"alice", "bob", false},
+		{"alice@example.com", "*@example.com", true},
+		{"alice@example.org", "*@example.com", false},
+		{"alice", "al?ce", true},
+		{"alice", "*,!alice", false},
+		{"bob", "*,!alice", true},
+		{"", "*", true},
+		{"abc", "a*b*c", true},
+		{"abd", "a*b*c", false},
+	}
+	for _, tt := range tests {
+		if got := matchPatternList(tt.s, tt.list); got != tt.want {
+			t.Errorf("matchPatternList(%q, %q) = %v, want %v", tt.s, tt.list, got, tt.want)
+		}
+	}
+}
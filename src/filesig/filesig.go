@@ -0,0 +1,385 @@
+// Package filesig implements detached file signatures in the layout of
+// minisign and signify, with Falcon keys.
+//
+// A public key file is
+//
+//	untrusted comment: <comment>
+//	base64(<algorithm> || <key ID> || <public key>)
+//
+// and a signature file is
+//
+//	untrusted comment: <comment>
+//	base64(<algorithm> || <key ID> || <signature>)
+//	trusted comment: <comment>
+//	base64(<global signature>)
+//
+// The algorithm is "F5" for Falcon-512 and "F1" for Falcon-1024, the key ID
+// is 8 random bytes chosen when the key is generated, and the public key is
+// the encoding of falcon.MarshalPublicKey. As with the prehashed signatures
+// of minisign, the file is streamed through SHAKE256 with a 64-byte output
+// and the digest is signed with pure FN-DSA; the global signature covers
+// the signature followed by the trusted comment, so that the trusted
+// comment cannot be changed. Signatures are in the padded format.
+//
+// The secret key file holds the algorithm, "Sc" for a passphrase-encrypted
+// key (falcon.MarshalEncryptedPrivateKey) or two zero bytes for an
+// unencrypted one (falcon.MarshalPrivateKey), the key ID and the key.
+package filesig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// AlgFalcon512 identifies Falcon-512 keys and signatures.
+	AlgFalcon512 = "F5"
+	// AlgFalcon1024 identifies Falcon-1024 keys and signatures.
+	AlgFalcon1024 = "F1"
+
+	kdfNone   = "\x00\x00"
+	kdfScrypt = "Sc"
+
+	// KeyIDLen is the bytelength of key IDs.
+	KeyIDLen = 8
+	// digestLen is the bytelength of the SHAKE256 digest of files.
+	digestLen = 64
+
+	untrustedPrefix = "untrusted comment: "
+	trustedPrefix   = "trusted comment: "
+)
+
+var (
+	// ErrInvalidEncoding is returned when a key or signature file cannot be decoded
+	ErrInvalidEncoding = errors.New("filesig: invalid encoding")
+	// ErrUnsupportedAlgorithm is returned when the algorithm is not a Falcon one
+	ErrUnsupportedAlgorithm = errors.New("filesig: unsupported algorithm")
+	// ErrKeyIDMismatch is returned when a signature was made with another key
+	ErrKeyIDMismatch = errors.New("filesig: signature key ID does not match the public key")
+	// ErrInvalidSignature is returned when the signature of the file does not verify
+	ErrInvalidSignature = errors.New("filesig: invalid signature")
+	// ErrInvalidGlobalSignature is returned when the trusted comment was modified
+	ErrInvalidGlobalSignature = errors.New("filesig: invalid global signature")
+	// ErrInvalidComment is returned when a comment contains a line break
+	ErrInvalidComment = errors.New("filesig: comments cannot contain line breaks")
+)
+
+// KeyID identifies a key pair.
+type KeyID [KeyIDLen]byte
+
+// String returns the key ID as minisign prints it: the little-endian
+// integer in uppercase hexadecimal.
+func (id KeyID) String() string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
+
+// algorithm returns the algorithm of keys of degree n.
+func algorithm(n uint16) (string, error) {
+	switch n {
+	case 512:
+		return AlgFalcon512, nil
+	case 1024:
+		return AlgFalcon1024, nil
+	}
+	return "", ErrUnsupportedAlgorithm
+}
+
+// PublicKey is a public key with its key ID.
+type PublicKey struct {
+	KeyID KeyID
+	Key   *falcon.PublicKey
+}
+
+// PrivateKey is a private key with its key ID.
+type PrivateKey struct {
+	KeyID KeyID
+	Key   *falcon.PrivateKey
+}
+
+// GenerateKey generates a key pair of degree n with a random key ID.
+func GenerateKey(n uint16) (*PublicKey, *PrivateKey, error) {
+	if _, err := algorithm(n); err != nil {
+		return nil, nil, err
+	}
+	privKey, pubKey, err := falcon.NewKeyPair(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	var id KeyID
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, nil, err
+	}
+	return &PublicKey{KeyID: id, Key: pubKey}, &PrivateKey{KeyID: id, Key: privKey}, nil
+}
+
+// Public returns the public key of the key pair.
+func (priv *PrivateKey) Public() (*PublicKey, error) {
+	pubKey, err := priv.Key.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{KeyID: priv.KeyID, Key: pubKey}, nil
+}
+
+// Marshal encodes the public key file. An empty comment is replaced by
+// "falcon public key <key ID>".
+func (pub *PublicKey) Marshal(comment string) ([]byte, error) {
+	alg, err := algorithm(pub.Key.Degree())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := falcon.MarshalPublicKey(pub.Key)
+	if err != nil {
+		return nil, err
+	}
+	if comment == "" {
+		comment = "falcon public key " + pub.KeyID.String()
+	}
+	return marshalLines(comment, concat([]byte(alg), pub.KeyID[:], raw))
+}
+
+// ParsePublicKey decodes a public key file, or its base64 line alone.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	line := strings.TrimSpace(string(data))
+	if strings.HasPrefix(line, untrustedPrefix) {
+		_, rest, _ := strings.Cut(line, "\n")
+		line = strings.TrimSpace(rest)
+	}
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) < 2+KeyIDLen {
+		return nil, ErrInvalidEncoding
+	}
+	key, err := falcon.ParsePublicKey(raw[2+KeyIDLen:])
+	if err != nil {
+		return nil, ErrInvalidEncoding
+	}
+	if alg, _ := algorithm(key.Degree()); alg != string(raw[:2]) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	pub := &PublicKey{Key: key}
+	copy(pub.KeyID[:], raw[2:])
+	return pub, nil
+}
+
+// Marshal encodes the secret key file, encrypted with passphrase with the
+// default options of falcon.MarshalEncryptedPrivateKey. A nil passphrase
+// leaves the key unencrypted.
+func (priv *PrivateKey) Marshal(passphrase []byte, comment string) ([]byte, error) {
+	alg, err := algorithm(priv.Key.Degree())
+	if err != nil {
+		return nil, err
+	}
+	kdf := kdfNone
+	var raw []byte
+	if passphrase != nil {
+		kdf = kdfScrypt
+		raw, err = falcon.MarshalEncryptedPrivateKey(priv.Key, passphrase, nil)
+	} else {
+		raw, err = falcon.MarshalPrivateKey(priv.Key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if comment == "" {
+		comment = "falcon secret key " + priv.KeyID.String()
+	}
+	return marshalLines(comment, concat([]byte(alg), []byte(kdf), priv.KeyID[:], raw))
+}
+
+// ParsePrivateKey decodes a secret key file, decrypting it with passphrase
+// if it is encrypted.
+func ParsePrivateKey(data, passphrase []byte) (*PrivateKey, error) {
+	lines, err := splitLines(data, 2)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) < 4+KeyIDLen {
+		return nil, ErrInvalidEncoding
+	}
+	var key *falcon.PrivateKey
+	switch string(raw[2:4]) {
+	case kdfNone:
+		key, err = falcon.ParsePrivateKey(raw[4+KeyIDLen:])
+	case kdfScrypt:
+		key, err = falcon.ParseEncryptedPrivateKey(raw[4+KeyIDLen:], passphrase)
+	default:
+		return nil, ErrInvalidEncoding
+	}
+	if err != nil {
+		return nil, err
+	}
+	if alg, _ := algorithm(key.Degree()); alg != string(raw[:2]) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	priv := &PrivateKey{Key: key}
+	copy(priv.KeyID[:], raw[4:])
+	return priv, nil
+}
+
+// Signature is a detached file signature.
+type Signature struct {
+	Algorithm        string
+	KeyID            KeyID
+	Signature        []byte
+	UntrustedComment string
+	TrustedComment   string
+	GlobalSignature  []byte
+}
+
+// SignFile signs the content of r. An empty trusted comment is replaced by
+// the signing time as "timestamp:<unix time>", and an empty untrusted
+// comment by "signature from falcon secret key".
+func SignFile(priv *PrivateKey, r io.Reader, trustedComment, untrustedComment string) (*Signature, error) {
+	alg, err := algorithm(priv.Key.Degree())
+	if err != nil {
+		return nil, err
+	}
+	if trustedComment == "" {
+		trustedComment = fmt.Sprintf("timestamp:%d", time.Now().Unix())
+	}
+	if untrustedComment == "" {
+		untrustedComment = "signature from falcon secret key"
+	}
+	if strings.ContainsAny(trustedComment+untrustedComment, "\r\n") {
+		return nil, ErrInvalidComment
+	}
+	digest, err := hashFile(r)
+	if err != nil {
+		return nil, err
+	}
+	opts := &falcon.Options{Format: falcon.FormatPadded}
+	sig, err := priv.Key.SignFNDSA(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+	global, err := priv.Key.SignFNDSA(concat(sig, []byte(trustedComment)), opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{
+		Algorithm:        alg,
+		KeyID:            priv.KeyID,
+		Signature:        sig,
+		UntrustedComment: untrustedComment,
+		TrustedComment:   trustedComment,
+		GlobalSignature:  global,
+	}, nil
+}
+
+// VerifyFile verifies the signature of the content of r. The key ID of the
+// signature must be the one of pub, and the trusted comment is only to be
+// trusted once VerifyFile returns nil.
+func VerifyFile(pub *PublicKey, r io.Reader, sig *Signature) error {
+	alg, err := algorithm(pub.Key.Degree())
+	if err != nil {
+		return err
+	}
+	if sig.Algorithm != alg {
+		return ErrUnsupportedAlgorithm
+	}
+	if sig.KeyID != pub.KeyID {
+		return ErrKeyIDMismatch
+	}
+	digest, err := hashFile(r)
+	if err != nil {
+		return err
+	}
+	if pub.Key.VerifyFNDSA(digest, sig.Signature, nil) != nil {
+		return ErrInvalidSignature
+	}
+	if pub.Key.VerifyFNDSA(concat(sig.Signature, []byte(sig.TrustedComment)), sig.GlobalSignature, nil) != nil {
+		return ErrInvalidGlobalSignature
+	}
+	return nil
+}
+
+// Marshal encodes the signature file.
+func (sig *Signature) Marshal() ([]byte, error) {
+	if strings.ContainsAny(sig.TrustedComment+sig.UntrustedComment, "\r\n") {
+		return nil, ErrInvalidComment
+	}
+	var b bytes.Buffer
+	b.WriteString(untrustedPrefix + sig.UntrustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(concat([]byte(sig.Algorithm), sig.KeyID[:], sig.Signature)) + "\n")
+	b.WriteString(trustedPrefix + sig.TrustedComment + "\n")
+	b.WriteString(base64.StdEncoding.EncodeToString(sig.GlobalSignature) + "\n")
+	return b.Bytes(), nil
+}
+
+// ParseSignature decodes a signature file.
+func ParseSignature(data []byte) (*Signature, error) {
+	lines, err := splitLines(data, 4)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(lines[2], trustedPrefix) {
+		return nil, ErrInvalidEncoding
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) < 2+KeyIDLen {
+		return nil, ErrInvalidEncoding
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return nil, ErrInvalidEncoding
+	}
+	sig := &Signature{
+		Algorithm:        string(raw[:2]),
+		Signature:        raw[2+KeyIDLen:],
+		UntrustedComment: strings.TrimPrefix(lines[0], untrustedPrefix),
+		TrustedComment:   strings.TrimPrefix(lines[2], trustedPrefix),
+		GlobalSignature:  global,
+	}
+	copy(sig.KeyID[:], raw[2:])
+	if sig.Algorithm != AlgFalcon512 && sig.Algorithm != AlgFalcon1024 {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	return sig, nil
+}
+
+// hashFile returns the SHAKE256 digest of the content of r.
+func hashFile(r io.Reader) ([]byte, error) {
+	h := sha3.NewShake256()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	digest := make([]byte, digestLen)
+	h.Read(digest)
+	return digest, nil
+}
+
+// marshalLines encodes a key file: the untrusted comment, then the key.
+func marshalLines(comment string, raw []byte) ([]byte, error) {
+	if strings.ContainsAny(comment, "\r\n") {
+		return nil, ErrInvalidComment
+	}
+	return []byte(untrustedPrefix + comment + "\n" + base64.StdEncoding.EncodeToString(raw) + "\n"), nil
+}
+
+// splitLines returns the n lines of a file starting with an untrusted comment.
+func splitLines(data []byte, n int) ([]string, error) {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"), "\n")
+	if len(lines) != n || !strings.HasPrefix(lines[0], untrustedPrefix) {
+		return nil, ErrInvalidEncoding
+	}
+	return lines, nil
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
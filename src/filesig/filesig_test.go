@@ -0,0 +1,153 @@
+package filesig
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestVerifyTestdata(t *testing.T) {
+	pub, err := ParsePublicKey(mustRead(t, "testdata/release.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pub.KeyID.String() != "712D0C931F6A4B5E" {
+		t.Errorf("key ID %s", pub.KeyID)
+	}
+	data := mustRead(t, "testdata/release.txt.minisig")
+	sig, err := ParseSignature(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.TrustedComment != "timestamp:1791072000\tfile:release.txt" || len(sig.Signature) != 666 {
+		t.Errorf("signature %+v", sig)
+	}
+	if err := VerifyFile(pub, bytes.NewReader(mustRead(t, "testdata/release.txt")), sig); err != nil {
+		t.Fatal(err)
+	}
+	if encoded, err := sig.Marshal(); err != nil || !bytes.Equal(encoded, data) {
+		t.Errorf("Marshal does not reproduce the signature file: %v", err)
+	}
+
+	if err := VerifyFile(pub, strings.NewReader("falcon release 1.1\n"), sig); err != ErrInvalidSignature {
+		t.Errorf("VerifyFile of another file returned %v, want %v", err, ErrInvalidSignature)
+	}
+	tampered := *sig
+	tampered.TrustedComment = "timestamp:1791072000\tfile:release-1.1.txt"
+	if err := VerifyFile(pub, bytes.NewReader(mustRead(t, "testdata/release.txt")), &tampered); err != ErrInvalidGlobalSignature {
+		t.Errorf("VerifyFile with a modified trusted comment returned %v, want %v", err, ErrInvalidGlobalSignature)
+	}
+	// The untrusted comment is not signed
+	tampered = *sig
+	tampered.UntrustedComment = "anything"
+	if err := VerifyFile(pub, bytes.NewReader(mustRead(t, "testdata/release.txt")), &tampered); err != nil {
+		t.Errorf("VerifyFile with a modified untrusted comment returned %v", err)
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	pub, priv, err := GenerateKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := strings.Repeat("tarball contents ", 10000)
+	sig, err := SignFile(priv, strings.NewReader(file), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sig.TrustedComment, "timestamp:") {
+		t.Errorf("default trusted comment %q", sig.TrustedComment)
+	}
+	encoded, err := sig.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ParseSignature(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyFile(pub, strings.NewReader(file), decoded); err != nil {
+		t.Error(err)
+	}
+
+	// Key IDs must match even if the key is the same
+	other := *pub
+	other.KeyID[0] ^= 1
+	if err := VerifyFile(&other, strings.NewReader(file), decoded); err != ErrKeyIDMismatch {
+		t.Errorf("VerifyFile with another key ID returned %v, want %v", err, ErrKeyIDMismatch)
+	}
+	testdataKey, err := ParsePublicKey(mustRead(t, "testdata/release.pub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testdataKey.KeyID = pub.KeyID
+	if err := VerifyFile(testdataKey, strings.NewReader(file), decoded); err != ErrInvalidSignature {
+		t.Errorf("VerifyFile with another key returned %v, want %v", err, ErrInvalidSignature)
+	}
+
+	if _, err := SignFile(priv, strings.NewReader(file), "line\nbreak", ""); err != ErrInvalidComment {
+		t.Errorf("SignFile with a line break returned %v, want %v", err, ErrInvalidComment)
+	}
+}
+
+func TestKeyFiles(t *testing.T) {
+	pub, priv, err := GenerateKey(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encodedPub, err := pub.Marshal("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(encodedPub), "untrusted comment: falcon public key "+pub.KeyID.String()+"\n") {
+		t.Errorf("public key file %q", encodedPub)
+	}
+	decodedPub, err := ParsePublicKey(encodedPub)
+	if err != nil || decodedPub.KeyID != pub.KeyID || !decodedPub.Key.Equal(pub.Key) {
+		t.Errorf("ParsePublicKey returned %v", err)
+	}
+	// The base64 line alone is accepted, as on the minisign command line
+	if _, err := ParsePublicKey([]byte(strings.Split(string(encodedPub), "\n")[1])); err != nil {
+		t.Errorf("ParsePublicKey of the base64 line returned %v", err)
+	}
+
+	for _, passphrase := range [][]byte{nil, []byte("release passphrase")} {
+		encoded, err := priv.Marshal(passphrase, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := ParsePrivateKey(encoded, passphrase)
+		if err != nil || decoded.KeyID != priv.KeyID || !decoded.Key.Equal(priv.Key) {
+			t.Errorf("ParsePrivateKey returned %v", err)
+		}
+		if passphrase != nil {
+			if _, err := ParsePrivateKey(encoded, []byte("wrong")); err == nil {
+				t.Error("ParsePrivateKey with a wrong passphrase succeeded")
+			}
+		}
+	}
+
+	for _, data := range []string{
+		"",
+		"untrusted comment: x\n",
+		"untrusted comment: x\nnot base64\n",
+		"untrusted comment: x\nRjU=\n",
+	} {
+		if _, err := ParsePublicKey([]byte(data)); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeded", data)
+		}
+		if _, err := ParseSignature([]byte(data)); err == nil {
+			t.Errorf("ParseSignature(%q) succeeded", data)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package falcon
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/sha3"
+)
+
+/*
+FN-DSA (FIPS 206) domain separation.
+
+The message is not hashed to a point directly: it is first turned into a
+message representative M' that binds the signing mode and a context string,
+in the same way as ML-DSA (FIPS 204):
+
+  - pure FN-DSA:  M' = 0x00 ‖ len(ctx) ‖ ctx ‖ M
+  - HashFN-DSA:   M' = 0x01 ‖ len(ctx) ‖ ctx ‖ OID(PH) ‖ PH(M)
+
+M' is then signed with the round-3 construction hashToPoint(salt ‖ M').
+*/
+
+// MaxContextLen is the maximum bytelength of an FN-DSA context string.
+const MaxContextLen = 255
+
+var (
+	// ErrContextTooLong is returned when the context string is longer than MaxContextLen
+	ErrContextTooLong = errors.New("context string is longer than 255 bytes")
+	// ErrUnsupportedPreHash is returned when the pre-hash function is not supported
+	ErrUnsupportedPreHash = errors.New("unsupported pre-hash function")
+	// ErrInvalidDigestLength is returned when a pre-hashed digest has the wrong length
+	ErrInvalidDigestLength = errors.New("digest length does not match the pre-hash function")
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// PreHash identifies the hash function applied to the message by HashFN-DSA.
+type PreHash uint8
+
+const (
+	// PreHashNone selects pure FN-DSA.
+	PreHashNone PreHash = iota
+	PreHashSHA256
+	PreHashSHA384
+	PreHashSHA512
+	PreHashSHA3_224
+	PreHashSHA3_256
+	PreHashSHA3_384
+	PreHashSHA3_512
+	// PreHashSHAKE128 outputs 256 bits.
+	PreHashSHAKE128
+	// PreHashSHAKE256 outputs 512 bits.
+	PreHashSHAKE256
+)
+
+type preHashInfo struct {
+	name string
+	// oid is the DER encoding of the hash function OID (2.16.840.1.101.3.4.2.x)
+	oid  []byte
+	size int
+	new  func() hash.Hash
+}
+
+func nistHashOID(x byte) []byte {
+	return []byte{0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, x}
+}
+
+// shakeHash adapts a SHAKE XOF to hash.Hash with a fixed output length.
+type shakeHash struct {
+	sha3.ShakeHash
+	size int
+	rate int
+}
+
+func (s shakeHash) Sum(b []byte) []byte {
+	out := make([]byte, s.size)
+	s.Clone().Read(out)
+	return append(b, out...)
+}
+
+func (s shakeHash) Size() int { return s.size }
+
+func (s shakeHash) BlockSize() int { return s.rate }
+
+var preHashes = map[PreHash]preHashInfo{
+	PreHashSHA256:   {"SHA-256", nistHashOID(0x01), 32, sha256.New},
+	PreHashSHA384:   {"SHA-384", nistHashOID(0x02), 48, sha512.New384},
+	PreHashSHA512:   {"SHA-512", nistHashOID(0x03), 64, sha512.New},
+	PreHashSHA3_224: {"SHA3-224", nistHashOID(0x07), 28, sha3.New224},
+	PreHashSHA3_256: {"SHA3-256", nistHashOID(0x08), 32, sha3.New256},
+	PreHashSHA3_384: {"SHA3-384", nistHashOID(0x09), 48, sha3.New384},
+	PreHashSHA3_512: {"SHA3-512", nistHashOID(0x0a), 64, sha3.New512},
+	PreHashSHAKE128: {"SHAKE128", nistHashOID(0x0b), 32, func() hash.Hash { return shakeHash{sha3.NewShake128(), 32, 168} }},
+	PreHashSHAKE256: {"SHAKE256", nistHashOID(0x0c), 64, func() hash.Hash { return shakeHash{sha3.NewShake256(), 64, 136} }},
+}
+
+// String returns the name of the pre-hash function.
+func (ph PreHash) String() string {
+	if ph == PreHashNone {
+		return "none"
+	}
+	if info, ok := preHashes[ph]; ok {
+		return info.name
+	}
+	return "unknown"
+}
+
+// Size returns the digest bytelength of the pre-hash function, or 0 for pure FN-DSA.
+func (ph PreHash) Size() int {
+	return preHashes[ph].size
+}
+
+// Options selects the FN-DSA signing mode.
+type Options struct {
+	// PreHash selects HashFN-DSA with the given hash function.
+	// PreHashNone (the zero value) selects pure FN-DSA.
+	PreHash PreHash
+	// Context is an optional domain separation string of at most 255 bytes.
+	Context []byte
+	// Format is the encoding of the signature; the CT format also hashes
+	// the message representative to a point in constant time.
+	Format SignatureFormat
+	// Strict makes verification accept only the canonical encoding of a
+	// signature in Format, so that a valid signature has a single encoding.
+	// It is needed when signatures identify what they sign, for instance in
+	// transaction IDs.
+	Strict bool
+}
+
+// digest hashes message with the pre-hash function of opts.
+func (opts *Options) digest(message []byte) ([]byte, error) {
+	info, ok := preHashes[opts.PreHash]
+	if !ok {
+		return nil, ErrUnsupportedPreHash
+	}
+	h := info.new()
+	h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// messageRepresentative computes M' from a message (pure FN-DSA) or from the
+// digest of a message (HashFN-DSA).
+func messageRepresentative(msgOrDigest []byte, opts *Options) ([]byte, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if len(opts.Context) > MaxContextLen {
+		return nil, ErrContextTooLong
+	}
+	var domain byte
+	var oid []byte
+	if opts.PreHash != PreHashNone {
+		info, ok := preHashes[opts.PreHash]
+		if !ok {
+			return nil, ErrUnsupportedPreHash
+		}
+		if len(msgOrDigest) != info.size {
+			return nil, ErrInvalidDigestLength
+		}
+		domain = 1
+		oid = info.oid
+	}
+	mp := make([]byte, 0, 2+len(opts.Context)+len(oid)+len(msgOrDigest))
+	mp = append(mp, domain, byte(len(opts.Context)))
+	mp = append(mp, opts.Context...)
+	mp = append(mp, oid...)
+	return append(mp, msgOrDigest...), nil
+}
+
+// prepareMessage computes M' from a message, hashing it first in HashFN-DSA mode.
+func prepareMessage(message []byte, opts *Options) ([]byte, error) {
+	if opts != nil && opts.PreHash != PreHashNone {
+		digest, err := opts.digest(message)
+		if err != nil {
+			return nil, err
+		}
+		message = digest
+	}
+	return messageRepresentative(message, opts)
+}
+
+// SignFNDSA signs message with FN-DSA. A nil opts selects pure FN-DSA with an
+// empty context. In HashFN-DSA mode, message is hashed with opts.PreHash.
+func (privKey *PrivateKey) SignFNDSA(message []byte, opts *Options) ([]byte, error) {
+	return privKey.signFNDSAWithRand(message, opts, rand.Reader)
+}
+
+func (privKey *PrivateKey) signFNDSAWithRand(message []byte, opts *Options, randomBytes io.Reader) ([]byte, error) {
+	mp, err := prepareMessage(message, opts)
+	if err != nil {
+		return nil, err
+	}
+	var format SignatureFormat
+	if opts != nil {
+		format = opts.Format
+	}
+	return privKey.signWithRand(mp, format, randomBytes)
+}
+
+// VerifyFNDSA verifies an FN-DSA signature of message produced with the same
+// opts. Unless opts.Strict is set, the signature format is read from the
+// header and opts.Format is ignored. It returns nil if and only if the
+// signature is valid.
+func (pubKey *PublicKey) VerifyFNDSA(message, signature []byte, opts *Options) error {
+	mp, err := prepareMessage(message, opts)
+	if err != nil {
+		return err
+	}
+	if opts != nil && opts.Strict {
+		return pubKey.verifyStrict(mp, signature, opts.Format)
+	}
+	if !pubKey.verify(mp, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verify checks a round-3 signature of message, hashing salt‖message to a
+// point in constant time for CT signatures.
+func (pubKey *PublicKey) verify(message, signature []byte) bool {
+	if !isValidDegree(pubKey.n) || len(pubKey.h) != int(pubKey.n) {
+		return false
+	}
+	format, salt, s1, err := decodeSignature(signature, pubKey.n)
+	if err != nil {
+		return false
+	}
+	return pubKey.verifyDecoded(message, format, salt, s1)
+}
+
+// verifyStrict checks a round-3 signature of message which must be the
+// canonical encoding in format.
+func (pubKey *PublicKey) verifyStrict(message, signature []byte, format SignatureFormat) error {
+	if !isValidDegree(pubKey.n) || len(pubKey.h) != int(pubKey.n) {
+		return ErrInvalidDegree
+	}
+	salt, s1, err := decodeSignatureStrict(signature, pubKey.n, format)
+	if err != nil {
+		return err
+	}
+	if !pubKey.verifyDecoded(message, format, salt, s1) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// verifyDecoded checks the salt and s1 of a signature of message.
+func (pubKey *PublicKey) verifyDecoded(message []byte, format SignatureFormat, salt []byte, s1 []int16) bool {
+	var hashed []int16
+	if format.usesCTHash() {
+		hashed = hashToPointCT(message, salt, pubKey.n)
+	} else {
+		hashed = hashToPointN(message, salt, pubKey.n)
+	}
+	return verifyPoint(pubKey.n, pubKey.h, hashed, s1)
+}
@@ -0,0 +1,189 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Indra4091/falconGo/src/internal"
+	"github.com/Indra4091/falconGo/src/util"
+	"golang.org/x/crypto/sha3"
+)
+
+// deterministicReader returns a reproducible source of randomness for signing.
+func deterministicReader(seed string) sha3.ShakeHash {
+	shake := sha3.NewShake256()
+	shake.Write([]byte(seed))
+	return shake
+}
+
+func TestMessageRepresentative(t *testing.T) {
+	oid := "0609608648016503040201"
+	testCases := []struct {
+		opts *Options
+		want string
+	}{
+		{nil, "0000" + hex.EncodeToString([]byte("abc"))},
+		{&Options{Context: []byte("ctx")}, "0003637478" + hex.EncodeToString([]byte("abc"))},
+		{&Options{PreHash: PreHashSHA256}, "0100" + oid[:20] + "01" + "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{&Options{PreHash: PreHashSHA384}, "0100" + oid[:20] + "02" + "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7"},
+		{&Options{PreHash: PreHashSHA512}, "0100" + oid[:20] + "03" + "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{&Options{PreHash: PreHashSHA3_224}, "0100" + oid[:20] + "07" + "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+		{&Options{PreHash: PreHashSHA3_256}, "0100" + oid[:20] + "08" + "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{&Options{PreHash: PreHashSHA3_384}, "0100" + oid[:20] + "09" + "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+		{&Options{PreHash: PreHashSHA3_512}, "0100" + oid[:20] + "0a" + "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		{&Options{PreHash: PreHashSHAKE128}, "0100" + oid[:20] + "0b" + "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+		{&Options{PreHash: PreHashSHAKE256, Context: []byte{0xff}}, "0101ff" + oid[:20] + "0c" + "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4"},
+	}
+	for _, tc := range testCases {
+		got, err := prepareMessage([]byte("abc"), tc.opts)
+		if err != nil {
+			t.Errorf("prepareMessage(%v) returned %v", tc.opts, err)
+			continue
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("prepareMessage(%v) = %x, want %s", tc.opts, got, tc.want)
+		}
+	}
+}
+
+func TestMessageRepresentativeErrors(t *testing.T) {
+	if _, err := prepareMessage([]byte("abc"), &Options{Context: make([]byte, 256)}); err != ErrContextTooLong {
+		t.Errorf("256-byte context: got %v, want %v", err, ErrContextTooLong)
+	}
+	if _, err := prepareMessage([]byte("abc"), &Options{Context: make([]byte, 255)}); err != nil {
+		t.Errorf("255-byte context: got %v", err)
+	}
+	if _, err := prepareMessage([]byte("abc"), &Options{PreHash: 200}); err != ErrUnsupportedPreHash {
+		t.Errorf("unknown pre-hash: got %v, want %v", err, ErrUnsupportedPreHash)
+	}
+	if _, err := messageRepresentative([]byte("abc"), &Options{PreHash: PreHashSHA256}); err != ErrInvalidDigestLength {
+		t.Errorf("short digest: got %v, want %v", err, ErrInvalidDigestLength)
+	}
+}
+
+func TestSignVerifyFNDSA(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	message := []byte("FN-DSA test message")
+	modes := []*Options{
+		nil,
+		{Context: []byte("context")},
+		{PreHash: PreHashSHA256},
+		{PreHash: PreHashSHA384, Context: []byte("context")},
+		{PreHash: PreHashSHA512},
+		{PreHash: PreHashSHA3_256},
+		{PreHash: PreHashSHA3_512},
+		{PreHash: PreHashSHAKE128},
+		{PreHash: PreHashSHAKE256, Context: []byte("context")},
+	}
+	for i, opts := range modes {
+		sig, err := firstPrivKey512.signFNDSAWithRand(message, opts, deterministicReader("fndsa"))
+		if err != nil {
+			t.Fatalf("mode %d: SignFNDSA returned %v", i, err)
+		}
+		if len(sig) != int(ParamSets[512].sigbytelen) || sig[0] != 0x39 {
+			t.Errorf("mode %d: unexpected signature length %d or header %#x", i, len(sig), sig[0])
+		}
+		if err := pub.VerifyFNDSA(message, sig, opts); err != nil {
+			t.Errorf("mode %d: VerifyFNDSA returned %v", i, err)
+		}
+		if err := pub.VerifyFNDSA([]byte("another message"), sig, opts); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("mode %d: wrong message verified: %v", i, err)
+		}
+		// A signature does not verify under any other mode.
+		for j, other := range modes {
+			if j != i && pub.VerifyFNDSA(message, sig, other) == nil {
+				t.Errorf("signature of mode %d verified under mode %d", i, j)
+			}
+		}
+	}
+}
+
+func TestFNDSACrossLegacy(t *testing.T) {
+	// An FN-DSA signature is a round-3 signature of the message representative.
+	pub := firstPrivKey512.GetPublicKey()
+	opts := &Options{PreHash: PreHashSHA512, Context: []byte("cross")}
+	message := []byte("cross vector")
+
+	sig, err := firstPrivKey512.signFNDSAWithRand(message, opts, deterministicReader("cross"))
+	if err != nil {
+		t.Fatalf("SignFNDSA returned %v", err)
+	}
+	mp, _ := prepareMessage(message, opts)
+	if !Verify(pub.h, mp, sig) {
+		t.Errorf("FN-DSA signature does not verify as a legacy signature of M'")
+	}
+	if Verify(pub.h, message, sig) {
+		t.Errorf("FN-DSA signature verifies as a legacy signature of the raw message")
+	}
+
+	// The same randomness yields the same signature through either API.
+	legacy, err := firstPrivKey512.signWithRand(mp, FormatPadded, deterministicReader("cross"))
+	if err != nil {
+		t.Fatalf("Sign returned %v", err)
+	}
+	if !bytes.Equal(sig, legacy) {
+		t.Errorf("FN-DSA and legacy signatures of M' differ")
+	}
+}
+
+func TestSignVerifyFNDSADegrees(t *testing.T) {
+	for _, n := range []uint16{16, 64, 256} {
+		priv, pub, err := NewKeyPair(n)
+		if err != nil {
+			t.Fatalf("NewKeyPair(%d) returned %v", n, err)
+		}
+		opts := &Options{Context: []byte("degree")}
+		sig, err := priv.SignFNDSA([]byte("message"), opts)
+		if err != nil {
+			t.Fatalf("n = %d: SignFNDSA returned %v", n, err)
+		}
+		if err := pub.VerifyFNDSA([]byte("message"), sig, opts); err != nil {
+			t.Errorf("n = %d: VerifyFNDSA returned %v", n, err)
+		}
+	}
+}
+
+// A zero s2 leaves s1 = c, whose squared norm for n = 1024 averages
+// 1024*6144*6145/3, just above 3*2^32. Summed on 32 bits it wrapped below
+// the bound for about one salt in 13, so such signatures verified for any
+// message and key.
+func TestVerifyNormOverflow(t *testing.T) {
+	h := make([]int16, 1024)
+	for i := range h {
+		h[i] = int16(i)
+	}
+	pub := &PublicKey{n: 1024, h: h}
+	message := []byte("forged message")
+	encS, err := internal.Compress(make([]int16, 1024), int(ParamSets[1024].sigbytelen-HeadLen-SaltLen))
+	if err != nil {
+		t.Fatal(err)
+	}
+	salts := deterministicReader("overflow")
+	for i := 0; i < 1000; i++ {
+		salt := make([]byte, SaltLen)
+		salts.Read(salt)
+		var wrapped uint32
+		for _, v := range hashToPointN(message, salt, 1024) {
+			c := int32((v+(util.Q>>1))%util.Q - (util.Q >> 1))
+			wrapped += uint32(c * c)
+		}
+		if wrapped > ParamSets[1024].sigbound {
+			continue
+		}
+		sig := append(append([]byte{0x3a}, salt...), encS...)
+		if pub.verify(message, sig) || Verify(h, message, sig) {
+			t.Fatal("a signature with s2 = 0 verifies")
+		}
+		w, err := pub.ExplainVerify(message, sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.Valid || w.Norm <= 3<<32 {
+			t.Errorf("witness of the forgery: valid %v, norm %d", w.Valid, w.Norm)
+		}
+		return
+	}
+	t.Fatal("no salt found whose norm wraps below the bound")
+}
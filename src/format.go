@@ -0,0 +1,165 @@
+package falcon
+
+import (
+	"errors"
+
+	"github.com/Indra4091/falconGo/src/internal"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+// SignatureFormat selects how s1 is encoded in a signature.
+// The formats follow the Falcon reference implementation:
+//   - FormatPadded: compressed s1, zero-padded to the signature bytelength (header 0x30 + LOGN)
+//   - FormatCompressed: compressed s1 without padding, variable length (header 0x30 + LOGN)
+//   - FormatCT: fixed-width s1 (header 0x50 + LOGN); hashing to a point runs in
+//     constant time, for messages that must stay secret
+type SignatureFormat uint8
+
+const (
+	FormatPadded SignatureFormat = iota
+	FormatCompressed
+	FormatCT
+)
+
+const (
+	headerCompressed byte = 0x30
+	headerCT         byte = 0x50
+)
+
+var (
+	// ErrInvalidFormat is returned when the signature format is unknown
+	ErrInvalidFormat = errors.New("unknown signature format")
+	// ErrInvalidHeader is returned when the signature header does not match the degree
+	ErrInvalidHeader = errors.New("invalid signature header")
+	// ErrNonCanonicalSignature is returned by strict verification when a
+	// signature is not the canonical encoding of its salt and s1 in the expected format
+	ErrNonCanonicalSignature = errors.New("non-canonical signature encoding")
+)
+
+// ctSigBits is the bitlength of each coefficient of s1 in the CT format, indexed by LOGN.
+var ctSigBits = [11]int{0, 10, 11, 11, 12, 12, 12, 12, 12, 12, 12}
+
+// String returns the name of the signature format.
+func (format SignatureFormat) String() string {
+	switch format {
+	case FormatPadded:
+		return "padded"
+	case FormatCompressed:
+		return "compressed"
+	case FormatCT:
+		return "ct"
+	}
+	return "unknown"
+}
+
+// usesCTHash reports whether hashing to a point must run in constant time.
+func (format SignatureFormat) usesCTHash() bool {
+	return format == FormatCT
+}
+
+// header returns the first byte of a signature of degree n in this format.
+func (format SignatureFormat) header(n uint16) byte {
+	if format == FormatCT {
+		return headerCT + LOGN[n]
+	}
+	return headerCompressed + LOGN[n]
+}
+
+// encode encodes s1 with the format, returning ErrEncodingTooLong when s1 does not fit.
+func (format SignatureFormat) encode(s1 []int16, n uint16) ([]byte, error) {
+	param := ParamSets[n]
+	switch format {
+	case FormatPadded:
+		return internal.Compress(s1, int(param.sigbytelen-HeadLen-SaltLen))
+	case FormatCompressed:
+		encS, err := internal.Compress(s1, int(param.sigbytelen-HeadLen-SaltLen))
+		if err != nil {
+			return nil, err
+		}
+		// The encoding of the last coefficient ends with a 1 bit,
+		// so only padding is removed.
+		for len(encS) > 0 && encS[len(encS)-1] == 0 {
+			encS = encS[:len(encS)-1]
+		}
+		return encS, nil
+	case FormatCT:
+		return internal.TrimI16Encode(s1, ctSigBits[LOGN[n]])
+	}
+	return nil, ErrInvalidFormat
+}
+
+// signatureFormat returns the format of a signature of degree n from its header.
+// Compressed and padded signatures share a header and are both reported as
+// FormatCompressed, whose decoding accepts both.
+func signatureFormat(signature []byte, n uint16) (SignatureFormat, error) {
+	if len(signature) < HeadLen+SaltLen {
+		return 0, internal.ErrInvalidEncoding
+	}
+	switch signature[0] {
+	case FormatCompressed.header(n):
+		return FormatCompressed, nil
+	case FormatCT.header(n):
+		return FormatCT, nil
+	}
+	return 0, ErrInvalidHeader
+}
+
+// decodeSignature splits a signature of degree n into its salt and s1.
+func decodeSignature(signature []byte, n uint16) (SignatureFormat, []byte, []int16, error) {
+	format, err := signatureFormat(signature, n)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	salt := signature[HeadLen : HeadLen+SaltLen]
+	encS := signature[HeadLen+SaltLen:]
+	var s1 []int
+	if format == FormatCT {
+		s1, err = internal.TrimI16Decode(encS, int(n), ctSigBits[LOGN[n]])
+	} else {
+		s1, err = internal.Decompress(encS, int(ParamSets[n].sigbytelen-HeadLen-SaltLen), int(n))
+	}
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return format, salt, util.IntToInt16(s1), nil
+}
+
+// decodeSignatureStrict splits a signature of degree n into its salt and
+// s1, accepting only the canonical encoding in format: the header must be
+// the one of format and n, padded and CT signatures must have their exact
+// length, compressed signatures must not end with a zero byte, s1 must be
+// encoded canonically and all padding bits must be zero. Each (salt, s1)
+// then has a single accepted encoding per format.
+func decodeSignatureStrict(signature []byte, n uint16, format SignatureFormat) ([]byte, []int16, error) {
+	if format > FormatCT {
+		return nil, nil, ErrInvalidFormat
+	}
+	sigLen := int(ParamSets[n].sigbytelen)
+	if len(signature) <= HeadLen+SaltLen || signature[0] != format.header(n) {
+		return nil, nil, ErrNonCanonicalSignature
+	}
+	salt := signature[HeadLen : HeadLen+SaltLen]
+	encS := signature[HeadLen+SaltLen:]
+	var s1 []int
+	var err error
+	switch format {
+	case FormatPadded:
+		if len(signature) != sigLen {
+			return nil, nil, ErrNonCanonicalSignature
+		}
+		s1, err = internal.DecompressStrict(encS, int(n))
+	case FormatCompressed:
+		// The encoding of the last coefficient ends with a 1 bit, so a
+		// trailing zero byte can only be padding
+		if len(signature) > sigLen || encS[len(encS)-1] == 0 {
+			return nil, nil, ErrNonCanonicalSignature
+		}
+		s1, err = internal.DecompressStrict(encS, int(n))
+	case FormatCT:
+		s1, err = internal.TrimI16Decode(encS, int(n), ctSigBits[LOGN[n]])
+	}
+	if err != nil {
+		return nil, nil, ErrNonCanonicalSignature
+	}
+	return salt, util.IntToInt16(s1), nil
+}
@@ -0,0 +1,160 @@
+package falcon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestSignFormats(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	message := []byte("message")
+	testCases := []struct {
+		format SignatureFormat
+		header byte
+		length int
+	}{
+		{FormatPadded, 0x39, 666},
+		{FormatCompressed, 0x39, 0},
+		{FormatCT, 0x59, 809},
+	}
+	for _, tc := range testCases {
+		sig, err := firstPrivKey512.signWithRand(message, tc.format, deterministicReader(tc.format.String()))
+		if err != nil {
+			t.Fatalf("%v: Sign returned %v", tc.format, err)
+		}
+		if sig[0] != tc.header {
+			t.Errorf("%v: header = %#x, want %#x", tc.format, sig[0], tc.header)
+		}
+		if tc.length != 0 && len(sig) != tc.length {
+			t.Errorf("%v: length = %d, want %d", tc.format, len(sig), tc.length)
+		}
+		if tc.format == FormatCompressed && (len(sig) > 666 || sig[len(sig)-1] == 0) {
+			t.Errorf("%v: signature of length %d is padded", tc.format, len(sig))
+		}
+		if !Verify(pub.h, message, sig) {
+			t.Errorf("%v: signature does not verify", tc.format)
+		}
+		if Verify(pub.h, []byte("other"), sig) {
+			t.Errorf("%v: signature verifies for another message", tc.format)
+		}
+	}
+}
+
+func TestSignFormatCTDegrees(t *testing.T) {
+	for _, n := range []uint16{2, 8, 64} {
+		priv, pub, err := NewKeyPair(n)
+		if err != nil {
+			t.Fatalf("NewKeyPair(%d) returned %v", n, err)
+		}
+		sig, err := priv.SignFormat([]byte("secret"), FormatCT)
+		if err != nil {
+			t.Fatalf("n = %d: SignFormat returned %v", n, err)
+		}
+		if want := HeadLen + SaltLen + (int(n)*ctSigBits[LOGN[n]]+7)/8; len(sig) != want {
+			t.Errorf("n = %d: CT signature length = %d, want %d", n, len(sig), want)
+		}
+		if !pub.verify([]byte("secret"), sig) {
+			t.Errorf("n = %d: CT signature does not verify", n)
+		}
+	}
+}
+
+func TestInvalidHeader(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	sig, err := firstPrivKey512.SignFormat([]byte("message"), FormatPadded)
+	if err != nil {
+		t.Fatalf("SignFormat returned %v", err)
+	}
+	// A header announcing another degree or format is rejected.
+	for _, header := range []byte{0x3a, 0x59, 0x00} {
+		sig[0] = header
+		if Verify(pub.h, []byte("message"), sig) {
+			t.Errorf("signature with header %#x verifies", header)
+		}
+	}
+}
+
+// malleabilityCorpus is testdata/malleability.json: canonical signatures of
+// a message and byte strings derived from them, several of which verify
+// with the lenient decoding.
+type malleabilityCorpus struct {
+	PublicKey  string `json:"public_key"`
+	Message    string `json:"message"`
+	Signatures []struct {
+		Name      string `json:"name"`
+		Format    string `json:"format"`
+		Signature string `json:"signature"`
+		Canonical bool   `json:"canonical"`
+		Lenient   bool   `json:"lenient"`
+	} `json:"signatures"`
+}
+
+func TestStrictVerify(t *testing.T) {
+	var corpus malleabilityCorpus
+	if err := json.Unmarshal(mustReadFile(t, "testdata/malleability.json"), &corpus); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePublicKeyPEM(mustReadFile(t, "testdata/"+corpus.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte(corpus.Message)
+	formats := map[string]SignatureFormat{}
+	for _, format := range []SignatureFormat{FormatPadded, FormatCompressed, FormatCT} {
+		formats[format.String()] = format
+	}
+
+	for _, tc := range corpus.Signatures {
+		sig, err := hex.DecodeString(tc.Signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+		format, ok := formats[tc.Format]
+		if !ok {
+			t.Fatalf("%s: unknown format %q", tc.Name, tc.Format)
+		}
+		if lenient := pub.VerifyFNDSA(message, sig, nil) == nil; lenient != tc.Lenient {
+			t.Errorf("%s: lenient verification returned %v, want %v", tc.Name, lenient, tc.Lenient)
+		}
+		err = pub.VerifyFNDSA(message, sig, &Options{Format: format, Strict: true})
+		if tc.Canonical && err != nil {
+			t.Errorf("%s: strict verification returned %v", tc.Name, err)
+		}
+		if !tc.Canonical && err != ErrNonCanonicalSignature {
+			t.Errorf("%s: strict verification returned %v, want %v", tc.Name, err, ErrNonCanonicalSignature)
+		}
+		// A canonical signature is only accepted in its own format
+		for _, other := range formats {
+			if tc.Canonical && other != format && pub.VerifyFNDSA(message, sig, &Options{Format: other, Strict: true}) == nil {
+				t.Errorf("%s: strict verification accepted it as %s", tc.Name, other)
+			}
+		}
+	}
+
+	// A canonical signature of another message is invalid, not malformed
+	sig, _ := hex.DecodeString(corpus.Signatures[0].Signature)
+	if err := pub.VerifyFNDSA([]byte("other"), sig, &Options{Strict: true}); err != ErrInvalidSignature {
+		t.Errorf("strict verification of another message returned %v, want %v", err, ErrInvalidSignature)
+	}
+	if err := pub.VerifyFNDSA(message, sig, &Options{Format: FormatCT + 1, Strict: true}); err != ErrInvalidFormat {
+		t.Errorf("strict verification in an unknown format returned %v, want %v", err, ErrInvalidFormat)
+	}
+}
+
+func TestVerifyStrict(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	message := []byte("message")
+	for _, format := range []SignatureFormat{FormatPadded, FormatCompressed, FormatCT} {
+		sig, err := firstPrivKey512.signWithRand(message, format, deterministicReader("strict "+format.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pub.VerifyStrict(message, sig, format); err != nil {
+			t.Errorf("VerifyStrict of a %s signature returned %v", format, err)
+		}
+		if err := pub.VerifyStrict(message, append(sig, 0), format); err != ErrNonCanonicalSignature {
+			t.Errorf("VerifyStrict of a %s signature with a trailing zero returned %v", format, err)
+		}
+	}
+}
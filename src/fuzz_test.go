@@ -0,0 +1,101 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// The seed corpora of the fuzz targets are in testdata/fuzz. The targets
+// check that decoding and verification return errors, never panic, on
+// arbitrary input.
+
+func FuzzParsePublicKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pubKey, err := ParsePublicKey(data)
+		if err == nil {
+			if err := pubKey.Validate(); err != nil {
+				t.Fatalf("ParsePublicKey returned an invalid key: %v", err)
+			}
+			encoded, err := MarshalPublicKey(pubKey)
+			if err != nil || !bytes.Equal(encoded, data) {
+				t.Fatalf("MarshalPublicKey does not reproduce the encoding: %v", err)
+			}
+		}
+		ParsePKIXPublicKey(data)
+		ParsePublicKeyPEM(data)
+	})
+}
+
+func FuzzParsePrivateKey(f *testing.F) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		privKey, err := ParsePrivateKey(data)
+		if err == nil {
+			if err := privKey.Validate(); err != nil {
+				t.Fatalf("ParsePrivateKey returned an invalid key: %v", err)
+			}
+			encoded, err := MarshalPrivateKey(privKey)
+			if err != nil || !bytes.Equal(encoded, data) {
+				t.Fatalf("MarshalPrivateKey does not reproduce the encoding: %v", err)
+			}
+		}
+		ParsePKCS8PrivateKey(data)
+		ParsePrivateKeyPEM(data)
+	})
+}
+
+func FuzzVerify(f *testing.F) {
+	pubKey, err := ParsePublicKeyPEM(mustReadFile(f, "testdata/falcon512_pub.pem"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Fuzz(func(t *testing.T, message, signature []byte) {
+		valid := pubKey.Verify(message, signature)
+		if w, err := pubKey.ExplainVerify(message, signature); err == nil && w.Valid != valid {
+			t.Fatalf("ExplainVerify reports %v, Verify %v", w.Valid, valid)
+		}
+		for _, format := range []SignatureFormat{FormatPadded, FormatCompressed, FormatCT} {
+			if pubKey.VerifyStrict(message, signature, format) == nil && !valid {
+				t.Fatalf("VerifyStrict accepted a %s signature rejected by Verify", format)
+			}
+		}
+		pubKey.VerifyFNDSA(message, signature, nil)
+	})
+}
+
+// FuzzVerifyPrecompile checks the parser of VerifyPrecompile, which
+// replaced the former VerifyBytes.
+func FuzzVerifyPrecompile(f *testing.F) {
+	valid, err := hex.DecodeString(strings.TrimSpace(string(mustReadFile(f, "testdata/precompile_valid.hex"))))
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(valid)
+	var precompile VerifyPrecompile
+	f.Fuzz(func(t *testing.T, input []byte) {
+		// The legacy Verify takes the polynomial of any length
+		h := make([]int16, len(input)/2)
+		for i := range h {
+			h[i] = int16(input[2*i])<<8 | int16(input[2*i+1])
+		}
+		Verify(h, input[:len(input)/2], input[len(input)/2:])
+
+		out, err := precompile.Run(input)
+		if err != nil {
+			return
+		}
+		if len(out) != 32 || !bytes.Equal(out[:31], make([]byte, 31)) || out[31] > 1 {
+			t.Fatalf("Run returned the word %x", out)
+		}
+		hash := input[:PrecompileHashLen]
+		signature := input[PrecompileHashLen : PrecompileHashLen+PrecompileSignatureLen]
+		pubKey, err := ParsePublicKey(input[PrecompileHashLen+PrecompileSignatureLen:])
+		if err != nil {
+			t.Fatalf("Run accepted a malformed key: %v", err)
+		}
+		if valid := pubKey.Verify(hash, signature); valid != (out[31] == 1) {
+			t.Fatalf("Run returned %x, Verify %v", out, valid)
+		}
+	})
+}
@@ -0,0 +1,326 @@
+// Package cbor is a minimal CBOR (RFC 8949) codec for COSE structures.
+//
+// Values are represented by Go types:
+//
+//	unsigned and negative integers  int64 (uint64 above math.MaxInt64)
+//	byte strings                    []byte
+//	text strings                    string
+//	arrays                          []any
+//	maps                            Map
+//	tags                            Tag
+//	false, true, null               bool, nil
+//
+// Marshal produces the core deterministic encoding of section 4.2.1: shortest
+// arguments, definite lengths and map keys sorted by their encodings.
+// Unmarshal accepts definite-length encodings only, and rejects floating-point
+// numbers, simple values other than false, true and null, duplicate map keys
+// and trailing bytes.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+const (
+	simpleFalse = 20
+	simpleTrue  = 21
+	simpleNull  = 22
+)
+
+// maxDepth bounds the nesting of arrays, maps and tags.
+const maxDepth = 16
+
+var (
+	// ErrUnsupportedType is returned when a value has no CBOR representation in this package
+	ErrUnsupportedType = errors.New("cbor: unsupported type")
+	// ErrDuplicateKey is returned when a map has two equal keys
+	ErrDuplicateKey = errors.New("cbor: duplicate map key")
+	// ErrMalformed is returned when data is not a supported CBOR encoding
+	ErrMalformed = errors.New("cbor: malformed data")
+)
+
+// Entry is a key/value pair of a Map.
+type Entry struct {
+	Key   any
+	Value any
+}
+
+// Map is a CBOR map, with keys of any comparable value type. Decoded maps
+// keep the order of the encoding.
+type Map []Entry
+
+// Get returns the value of key in the map.
+func (m Map) Get(key any) (any, bool) {
+	for _, entry := range m {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Tag is a tagged data item.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+// Marshal returns the deterministic encoding of v.
+func Marshal(v any) ([]byte, error) {
+	return appendValue(nil, v, 0)
+}
+
+func appendHead(b []byte, major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return append(b, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(b, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), arg)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	if v < 0 {
+		return appendHead(b, majorNegative, uint64(-1-v))
+	}
+	return appendHead(b, majorUnsigned, uint64(v))
+}
+
+func appendValue(b []byte, v any, depth int) ([]byte, error) {
+	if depth > maxDepth {
+		return nil, ErrUnsupportedType
+	}
+	switch v := v.(type) {
+	case nil:
+		return append(b, majorSimple<<5|simpleNull), nil
+	case bool:
+		if v {
+			return append(b, majorSimple<<5|simpleTrue), nil
+		}
+		return append(b, majorSimple<<5|simpleFalse), nil
+	case int:
+		return appendInt(b, int64(v)), nil
+	case int64:
+		return appendInt(b, v), nil
+	case uint64:
+		return appendHead(b, majorUnsigned, v), nil
+	case []byte:
+		return append(appendHead(b, majorBytes, uint64(len(v))), v...), nil
+	case string:
+		if !utf8.ValidString(v) {
+			return nil, ErrUnsupportedType
+		}
+		return append(appendHead(b, majorText, uint64(len(v))), v...), nil
+	case []any:
+		b = appendHead(b, majorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if b, err = appendValue(b, item, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case Map:
+		return appendMap(b, v, depth)
+	case Tag:
+		return appendValue(appendHead(b, majorTag, v.Number), v.Content, depth+1)
+	}
+	return nil, ErrUnsupportedType
+}
+
+// appendMap encodes the entries of m sorted by the bytewise order of their
+// encoded keys.
+func appendMap(b []byte, m Map, depth int) ([]byte, error) {
+	type encodedEntry struct {
+		key, value []byte
+	}
+	entries := make([]encodedEntry, len(m))
+	for i, entry := range m {
+		key, err := appendValue(nil, entry.Key, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := appendValue(nil, entry.Value, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = encodedEntry{key, value}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	b = appendHead(b, majorMap, uint64(len(entries)))
+	for i, entry := range entries {
+		if i > 0 && bytes.Equal(entries[i-1].key, entry.key) {
+			return nil, ErrDuplicateKey
+		}
+		b = append(append(b, entry.key...), entry.value...)
+	}
+	return b, nil
+}
+
+// Unmarshal decodes a single CBOR data item.
+func Unmarshal(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(d.data) {
+		return nil, ErrMalformed
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+// head reads the initial byte and argument of a data item.
+func (d *decoder) head() (byte, uint64, error) {
+	if d.off >= len(d.data) {
+		return 0, 0, ErrMalformed
+	}
+	initial := d.data[d.off]
+	d.off++
+	major, info := initial>>5, initial&0x1f
+	if info < 24 {
+		return major, uint64(info), nil
+	}
+	if info > 27 {
+		// indefinite lengths and reserved values
+		return 0, 0, ErrMalformed
+	}
+	size := 1 << (info - 24)
+	if len(d.data)-d.off < size {
+		return 0, 0, ErrMalformed
+	}
+	var arg uint64
+	for _, c := range d.data[d.off : d.off+size] {
+		arg = arg<<8 | uint64(c)
+	}
+	d.off += size
+	return major, arg, nil
+}
+
+// bytes reads n bytes of string content.
+func (d *decoder) bytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, ErrMalformed
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+func (d *decoder) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, ErrMalformed
+	}
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegative:
+		if arg > math.MaxInt64 {
+			return nil, ErrMalformed
+		}
+		return -1 - int64(arg), nil
+	case majorBytes:
+		b, err := d.bytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case majorText:
+		b, err := d.bytes(arg)
+		if err != nil || !utf8.Valid(b) {
+			return nil, ErrMalformed
+		}
+		return string(b), nil
+	case majorArray:
+		// each item takes at least one byte
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, ErrMalformed
+		}
+		array := make([]any, arg)
+		for i := range array {
+			if array[i], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	case majorMap:
+		if arg > uint64(len(d.data)-d.off)/2 {
+			return nil, ErrMalformed
+		}
+		m := make(Map, arg)
+		seen := make(map[any]bool, arg)
+		for i := range m {
+			if m[i].Key, err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+			switch m[i].Key.(type) {
+			case []any, Map, []byte, Tag:
+				// keys must be comparable with Map.Get
+				return nil, ErrUnsupportedType
+			}
+			if seen[m[i].Key] {
+				return nil, ErrDuplicateKey
+			}
+			seen[m[i].Key] = true
+			if m[i].Value, err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case majorTag:
+		content, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: content}, nil
+	}
+	// major type 7: only false, true and null are supported, as one-byte values
+	if d.data[d.off-1]&0x1f >= 24 {
+		return nil, ErrUnsupportedType
+	}
+	switch arg {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull:
+		return nil, nil
+	}
+	return nil, ErrUnsupportedType
+}
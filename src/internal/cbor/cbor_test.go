@@ -0,0 +1,135 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	// examples of RFC 8949, appendix A
+	testCases := []struct {
+		v    any
+		want string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"", "60"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{[]any{}, "80"},
+		{[]any{1, []any{2, 3}, []any{4, 5}}, "8301820203820405"},
+		{Map{}, "a0"},
+		{Map{{1, 2}, {3, 4}}, "a201020304"},
+		{Map{{"a", 1}, {"b", []any{2, 3}}}, "a26161016162820203"},
+		{Tag{1, 1363896240}, "c11a514b67b0"},
+		// keys are sorted by their encodings
+		{Map{{"a", 1}, {-1, 2}, {10, 3}, {1, 4}}, "a401040a032002616101"},
+	}
+	for _, tc := range testCases {
+		got, err := Marshal(tc.v)
+		if err != nil {
+			t.Errorf("Marshal(%v) returned %v", tc.v, err)
+			continue
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("Marshal(%v) = %x, want %s", tc.v, got, tc.want)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		data string
+		want any
+	}{
+		{"00", int64(0)},
+		{"1bffffffffffffffff", uint64(math.MaxUint64)},
+		{"3903e7", int64(-1000)},
+		{"f6", nil},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"62c3bc", "ü"},
+		{"8301820203820405", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"a26161016162820203", Map{{"a", int64(1)}, {"b", []any{int64(2), int64(3)}}}},
+		{"d28440a0f640", Tag{18, []any{[]byte{}, Map{}, nil, []byte{}}}},
+		// non-preferred arguments are accepted
+		{"1801", int64(1)},
+	}
+	for _, tc := range testCases {
+		data, _ := hex.DecodeString(tc.data)
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Errorf("Unmarshal(%s) returned %v", tc.data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"18",                 // truncated argument
+		"4401",               // truncated byte string
+		"5f4101ff",           // indefinite length
+		"9f01ff",             // indefinite length
+		"f93c00",             // float
+		"f7",                 // undefined
+		"0000",               // trailing byte
+		"a201020103",         // duplicate key
+		"a20102180103",       // duplicate key with a non-preferred encoding
+		"3bffffffffffffffff", // negative integer out of range
+		"62c328",             // invalid UTF-8
+		"9b7fffffffffffffff", // huge array
+		"a1400102",           // byte string key, trailing byte
+		"8181818181818181818181818181818181818100",
+	} {
+		b, _ := hex.DecodeString(data)
+		if _, err := Unmarshal(b); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", data)
+		}
+	}
+}
+
+func TestMarshalErrors(t *testing.T) {
+	for _, v := range []any{1.5, "\xff", Map{{1, 1}, {1, 2}}, []any{struct{}{}}} {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("Marshal(%#v) succeeded", v)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	v := Map{{int64(1), int64(-7)}, {int64(4), []byte("kid")}, {"text", []any{true, false, nil, Tag{98, int64(0)}}}}
+	data, err := Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, v) {
+		t.Errorf("round trip = %#v, want %#v", got, v)
+	}
+	if value, ok := got.(Map).Get(int64(4)); !ok || string(value.([]byte)) != "kid" {
+		t.Errorf("Get(4) = %v, %v", value, ok)
+	}
+}
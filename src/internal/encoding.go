@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	_ "github.com/Indra4091/falconGo/src/types"
+	"github.com/Indra4091/falconGo/src/util"
 )
 
 var (
@@ -70,10 +71,14 @@ func Decompress(x []byte, slen int, n int) ([]int, error) {
 		u += strconv.FormatInt(256^int64(elt), 2)[1:]
 	}
 	v := []int{}
-	for u[len(u)-1:] == "0" {
+	for u != "" && u[len(u)-1:] == "0" {
 		u = u[:len(u)-1]
 	}
 	for u != "" && len(v) < n {
+		// A coefficient takes at least 9 bits
+		if len(u) < 9 {
+			return nil, ErrInvalidEncoding
+		}
 		// Recover the sign of coef
 		sign := -1
 		if u[:1] == "1" {
@@ -83,10 +88,13 @@ func Decompress(x []byte, slen int, n int) ([]int, error) {
 		}
 		low, _ := strconv.ParseInt(u[1:8], 2, 64)
 		i, high := 8, 0
-		for u[i:i+1] == "0" {
+		for i < len(u) && u[i:i+1] == "0" {
 			i++
 			high++
 		}
+		if i == len(u) {
+			return nil, ErrInvalidEncoding
+		}
 		// Compute coef
 		coef := sign * (int(low) + (high << 7))
 		// Enforce a unique encoding for coef = 0
@@ -103,3 +111,178 @@ func Decompress(x []byte, slen int, n int) ([]int, error) {
 	}
 	return v, nil
 }
+
+// DecompressStrict decodes n coefficients compressed by Compress from x,
+// accepting only their canonical encoding: a zero coefficient must not be
+// encoded as -0, coefficients must not exceed 2047 in absolute value, and
+// all the bits after the last coefficient must be zero.
+func DecompressStrict(x []byte, n int) ([]int, error) {
+	v := make([]int, 0, n)
+	var acc uint32
+	accLen, pos := 0, 0
+	for len(v) < n {
+		// The sign and the 7 lower bits
+		if accLen < 8 {
+			if pos == len(x) {
+				return nil, ErrInvalidEncoding
+			}
+			acc = (acc << 8) | uint32(x[pos])
+			pos++
+			accLen += 8
+		}
+		accLen -= 8
+		b := (acc >> accLen) & 0xFF
+		m := int(b & 0x7F)
+		// The high bits in unary
+		for {
+			if accLen == 0 {
+				if pos == len(x) {
+					return nil, ErrInvalidEncoding
+				}
+				acc = (acc << 8) | uint32(x[pos])
+				pos++
+				accLen = 8
+			}
+			accLen--
+			if (acc>>accLen)&1 == 1 {
+				break
+			}
+			m += 1 << 7
+			if m > 2047 {
+				return nil, ErrInvalidEncoding
+			}
+		}
+		if b>>7 == 1 {
+			if m == 0 {
+				return nil, ErrInvalidEncoding
+			}
+			m = -m
+		}
+		v = append(v, m)
+	}
+	if acc&(uint32(1)<<accLen-1) != 0 {
+		return nil, ErrInvalidEncoding
+	}
+	for _, elt := range x[pos:] {
+		if elt != 0 {
+			return nil, ErrInvalidEncoding
+		}
+	}
+	return v, nil
+}
+
+// TrimI16Encode encodes each coefficient of v over a fixed number of bits
+// (two's complement, most significant bit first), as used by the
+// constant-time signature format. Coefficients must lie in
+// [-(2^(bits-1) - 1), 2^(bits-1) - 1], otherwise ErrEncodingTooLong is returned.
+func TrimI16Encode(v []int16, bits int) ([]byte, error) {
+	maxv := int16(1<<(bits-1)) - 1
+	for _, coef := range v {
+		if coef < -maxv || coef > maxv {
+			return nil, ErrEncodingTooLong
+		}
+	}
+	x := make([]byte, 0, (len(v)*bits+7)>>3)
+	mask := uint32(1)<<bits - 1
+	var acc uint32
+	accLen := 0
+	for _, coef := range v {
+		acc = (acc << bits) | (uint32(uint16(coef)) & mask)
+		accLen += bits
+		for accLen >= 8 {
+			accLen -= 8
+			x = append(x, byte(acc>>accLen))
+		}
+	}
+	if accLen > 0 {
+		x = append(x, byte(acc<<(8-accLen)))
+	}
+	return x, nil
+}
+
+// TrimI16Decode decodes n coefficients of the given bit width from x.
+// The encoding is rejected if x does not have the exact expected length,
+// if a coefficient is -2^(bits-1), or if the unused trailing bits are not zero.
+func TrimI16Decode(x []byte, n int, bits int) ([]int, error) {
+	if len(x) != (n*bits+7)>>3 {
+		return nil, ErrInvalidEncoding
+	}
+	v := make([]int, 0, n)
+	mask1 := uint32(1)<<bits - 1
+	mask2 := uint32(1) << (bits - 1)
+	var acc uint32
+	accLen := 0
+	for _, elt := range x {
+		acc = (acc << 8) | uint32(elt)
+		accLen += 8
+		for accLen >= bits && len(v) < n {
+			accLen -= bits
+			w := (acc >> accLen) & mask1
+			if w == mask2 {
+				// The -2^(bits-1) value is forbidden
+				return nil, ErrInvalidEncoding
+			}
+			w |= -(w & mask2)
+			v = append(v, int(int32(w)))
+		}
+	}
+	if acc&(uint32(1)<<accLen-1) != 0 {
+		// Extra bits in the last byte must be zero
+		return nil, ErrInvalidEncoding
+	}
+	return v, nil
+}
+
+// ModqEncode encodes polynomial coefficients in [0, q) over 14 bits each
+// (most significant bit first), as in the public key format of the
+// reference implementation.
+func ModqEncode(v []int16) ([]byte, error) {
+	for _, coef := range v {
+		if coef < 0 || int(coef) >= util.Q {
+			return nil, ErrInvalidEncoding
+		}
+	}
+	x := make([]byte, 0, (len(v)*14+7)>>3)
+	var acc uint32
+	accLen := 0
+	for _, coef := range v {
+		acc = (acc << 14) | uint32(coef)
+		accLen += 14
+		for accLen >= 8 {
+			accLen -= 8
+			x = append(x, byte(acc>>accLen))
+		}
+	}
+	if accLen > 0 {
+		x = append(x, byte(acc<<(8-accLen)))
+	}
+	return x, nil
+}
+
+// ModqDecode decodes n coefficients encoded by ModqEncode. The encoding is
+// rejected if x does not have the exact expected length, if a coefficient is
+// not lower than q, or if the unused trailing bits are not zero.
+func ModqDecode(x []byte, n int) ([]int16, error) {
+	if len(x) != (n*14+7)>>3 {
+		return nil, ErrInvalidEncoding
+	}
+	v := make([]int16, 0, n)
+	var acc uint32
+	accLen := 0
+	for _, elt := range x {
+		acc = (acc << 8) | uint32(elt)
+		accLen += 8
+		if accLen >= 14 {
+			accLen -= 14
+			w := (acc >> accLen) & 0x3FFF
+			if w >= util.Q {
+				return nil, ErrInvalidEncoding
+			}
+			v = append(v, int16(w))
+		}
+	}
+	if acc&(uint32(1)<<accLen-1) != 0 {
+		return nil, ErrInvalidEncoding
+	}
+	return v, nil
+}
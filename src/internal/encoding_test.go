@@ -61,6 +61,25 @@ func TestDecompress(t *testing.T) {
 			expected: []int{85, -12, -171, 369, -5, 127, -74, 62, -22, 226, -98, 224, -78, 70, 81, -87, 49, -375, 195, -182, 129, -68, -90, -72, -165, -40, 30, -29, -62, 142, 141, -90, 69, -371, 105, 299, 80, -92, -32, -109, 103, -10, 1, 264, 231, 61, -126, 400, 180, 101, -303, 225, -118, -41, 201, 44, 30, -101, 10, -248, 166, 175, 238, -79},
 			err:      nil,
 		},
+		{
+			// Truncated encodings are rejected
+			x:    []byte{85, 198, 106},
+			slen: 81,
+			n:    64,
+			err:  ErrInvalidEncoding,
+		},
+		{
+			x:    []byte{0, 0, 0},
+			slen: 81,
+			n:    64,
+			err:  ErrInvalidEncoding,
+		},
+		{
+			x:    []byte{},
+			slen: 81,
+			n:    64,
+			err:  ErrInvalidEncoding,
+		},
 		//{
 		//	// Test case 2
 		//	v:        []int{128, 256, 512, 1024},
@@ -79,3 +98,78 @@ func TestDecompress(t *testing.T) {
 		}
 	}
 }
+
+func TestDecompressStrict(t *testing.T) {
+	v := []int16{0, 1, -1, 127, -128, 2047, -2047, 300}
+	x, err := Compress(v, 40)
+	if err != nil {
+		t.Fatalf("Compress returned %v", err)
+	}
+	got, err := DecompressStrict(x, len(v))
+	if err != nil {
+		t.Fatalf("DecompressStrict returned %v", err)
+	}
+	for i := range v {
+		if got[i] != int(v[i]) {
+			t.Errorf("DecompressStrict()[%d] = %d, want %d", i, got[i], v[i])
+		}
+	}
+
+	// Decompress ignores non-zero bits after the last coefficient
+	padded := append([]byte(nil), x...)
+	padded[len(padded)-1] |= 1
+	if _, err := Decompress(padded, 40, len(v)); err != nil {
+		t.Errorf("Decompress with non-zero padding returned %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		x    []byte
+		n    int
+	}{
+		{"non-zero padding", padded, len(v)},
+		{"truncated", x[:5], len(v)},
+		{"-0", []byte{0x80, 0x80}, 1},
+		{"2048", []byte{0x00, 0x00, 0x00, 0x80}, 1},
+		{"empty", nil, 1},
+	} {
+		if _, err := DecompressStrict(tc.x, tc.n); err != ErrInvalidEncoding {
+			t.Errorf("DecompressStrict of %s returned %v, want %v", tc.name, err, ErrInvalidEncoding)
+		}
+	}
+}
+
+func TestTrimI16(t *testing.T) {
+	v := []int16{0, 1, -1, 2047, -2047, 100, -100, 5}
+	x, err := TrimI16Encode(v, 12)
+	if err != nil {
+		t.Fatalf("TrimI16Encode returned %v", err)
+	}
+	if len(x) != 12 {
+		t.Errorf("len(TrimI16Encode) = %d, want 12", len(x))
+	}
+	got, err := TrimI16Decode(x, len(v), 12)
+	if err != nil {
+		t.Fatalf("TrimI16Decode returned %v", err)
+	}
+	for i := range v {
+		if got[i] != int(v[i]) {
+			t.Errorf("TrimI16Decode()[%d] = %d, want %d", i, got[i], v[i])
+		}
+	}
+
+	if _, err := TrimI16Encode([]int16{-2048}, 12); err != ErrEncodingTooLong {
+		t.Errorf("TrimI16Encode(-2048) returned %v, want %v", err, ErrEncodingTooLong)
+	}
+	// -2^(bits-1) is forbidden
+	if _, err := TrimI16Decode([]byte{0x80, 0x00}, 1, 12); err != ErrInvalidEncoding {
+		t.Errorf("TrimI16Decode(-2048) returned %v, want %v", err, ErrInvalidEncoding)
+	}
+	// non-zero padding bits
+	if _, err := TrimI16Decode([]byte{0x00, 0x01}, 1, 12); err != ErrInvalidEncoding {
+		t.Errorf("TrimI16Decode with padding returned %v, want %v", err, ErrInvalidEncoding)
+	}
+	// wrong length
+	if _, err := TrimI16Decode(x[:len(x)-1], len(v), 12); err != ErrInvalidEncoding {
+		t.Errorf("TrimI16Decode of a short input returned %v, want %v", err, ErrInvalidEncoding)
+	}
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"io"
 	"log"
 	"math"
 
@@ -227,6 +228,43 @@ func FfnpFFT(t [][]complex128, T []interface{}) [][]complex128 {
 	return nil
 }
 
+// FfldlTree computes the ffLDL decomposition tree of G.
+// The tree uses the same nested layout as FfnpFFT: an inner node is
+// []interface{}{l10, T0, T1} and a leaf holds the two FFT coefficients of
+// a diagonal entry of D, as []interface{}{d0, d1}.
+// Format: FFT
+func FfldlTree(G [][][]complex128) []interface{} {
+	n := len(G[0][0]) * fftRatio
+	LD := LdlFFT(G)
+	L, D := LD[0], LD[1]
+	if n > 2 {
+		d0001 := fft.SplitFFT(D[0][0])
+		d1011 := fft.SplitFFT(D[1][1])
+		d00, d01 := d0001[0], d0001[1]
+		d10, d11 := d1011[0], d1011[1]
+		G0 := [][][]complex128{{d00, d01}, {fft.AdjFFT(d01), d00}}
+		G1 := [][][]complex128{{d10, d11}, {fft.AdjFFT(d11), d10}}
+		return []interface{}{L[1][0], FfldlTree(G0), FfldlTree(G1)}
+	}
+	return []interface{}{
+		L[1][0],
+		[]interface{}{D[0][0][0], D[0][0][1]},
+		[]interface{}{D[1][1][0], D[1][1][1]},
+	}
+}
+
+// NormalizeTree normalizes the leaves of an LDL tree (from ||b_i||**2 to sigma/||b_i||).
+// Format: FFT
+func NormalizeTree(tree []interface{}, sigma float64) {
+	if len(tree) == 3 {
+		NormalizeTree(tree[1].([]interface{}), sigma)
+		NormalizeTree(tree[2].([]interface{}), sigma)
+		return
+	}
+	tree[0] = complex(sigma/math.Sqrt(real(tree[0].(complex128))), 0)
+	tree[1] = complex(0, 0)
+}
+
 // Require: t = (t0, t1) ∈ FFT (Q[x]/(xn + 1))2, a Falcon tree T
 // Ensure: z = (z0, z1) ∈ FFT (Z[x]/(xn + 1))2
 // Format: All polynomials are in FFT representation.
@@ -244,30 +282,34 @@ func FfnpFFT(t [][]complex128, T []interface{}) [][]complex128 {
 // 12: z0 ← ffSampling n/2(t0, T0)
 // 13: z0 ← mergefft(z0)
 // 14: return z = (z0, z1)
-
-//FAIL: TestFfSamplingFFT
-
-func (T *FFTtree) FfSamplingFFT(t [][]complex128, sigmin float64) [][]complex128 {
-	//gives an error bruh
+//
+// T must be a tree built by FfldlTree and normalized by NormalizeTree.
+// The randomness used by SamplerZ is read from randomBytes.
+func FfSamplingFFT(t [][]complex128, T []interface{}, sigmin float64, randomBytes io.Reader) ([][]complex128, error) {
 	n := len(t[0]) * fftRatio
-	z := [][]complex128{{0 + 0i}, {0 + 0i}}
-	//var rb [9]byte
-	//util.RandomBytes(rb[:]) //where is this rb used bruh??
 	if n > 1 {
-		z[1] = fft.MergeFFT((*T).FfSamplingFFT(fft.SplitFFT(t[1]), sigmin))
-		t0b := fft.AddFFT(t[0], fft.MulFFT(fft.SubFFT(t[1], z[1]), T.Value))
-		z[0] = fft.MergeFFT((*T).FfSamplingFFT(fft.SplitFFT(t0b), sigmin))
-		log.Println("z", z)
-		return z
-	} else if n == 1 {
-		log.Println("real(t[0][0])", real(t[0][0]))
-		/*if T.Leftchild[:] == nil {
-			T.Leftchild = T.Rightchild
-		}*/
-		z[0] = []complex128{complex(float64(Samplerz(real(t[0][0]), real(T.Value[0]), sigmin)), 0)}
-		z[1] = []complex128{complex(float64(Samplerz(real(t[1][0]), real(T.Value[0]), sigmin)), 0)}
-		log.Println("z", z)
-		return z
+		l10, T0, T1 := T[0].([]complex128), T[1].([]interface{}), T[2].([]interface{})
+		z1, err := FfSamplingFFT(fft.SplitFFT(t[1]), T1, sigmin, randomBytes)
+		if err != nil {
+			return nil, err
+		}
+		z := [][]complex128{nil, fft.MergeFFT(z1)}
+		t0b := fft.AddFFT(t[0], fft.MulFFT(fft.SubFFT(t[1], z[1]), l10))
+		z0, err := FfSamplingFFT(fft.SplitFFT(t0b), T0, sigmin, randomBytes)
+		if err != nil {
+			return nil, err
+		}
+		z[0] = fft.MergeFFT(z0)
+		return z, nil
+	}
+	sigma := real(T[0].(complex128))
+	z0, err := SamplerzFrom(real(t[0][0]), sigma, sigmin, randomBytes)
+	if err != nil {
+		return nil, err
+	}
+	z1, err := SamplerzFrom(real(t[1][0]), sigma, sigmin, randomBytes)
+	if err != nil {
+		return nil, err
 	}
-	return z
+	return [][]complex128{{complex(float64(z0), 0)}, {complex(float64(z1), 0)}}, nil
 }
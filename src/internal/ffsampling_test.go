@@ -1,7 +1,9 @@
 package internal
 
 import (
+	cryptoRand "crypto/rand"
 	"log"
+	"math"
 	"reflect"
 	"testing"
 
@@ -143,7 +145,6 @@ func TestFfnpFFT(t *testing.T) {
 	}
 }
 
-// failed
 func TestFfSamplingFFT(t *testing.T) {
 	t0 := [][]complex128{
 		{(15.515338920986247 + 21.66571730816177i), (15.515338920986247 - 21.66571730816177i)},
@@ -153,19 +154,26 @@ func TestFfSamplingFFT(t *testing.T) {
 		(-0.13598924188939318 + 0.14027567658429987i),
 		(-0.13598924188939318 - 0.14027567658429987i),
 	}
-	T0 := []complex128{1.327605943729194, 0}
-	T1 := []complex128{1.2853654095931282, 0}
+	T0 := []interface{}{complex(1.327605943729194, 0), complex(0, 0)}
+	T1 := []interface{}{complex(1.2853654095931282, 0), complex(0, 0)}
 	sigmin := 1.1165085072329104
 
-	T := FFTtree{l10, T0, T1}
+	T := []interface{}{l10, T0, T1}
 
-	want := [][]complex128{
-		{(16 + 22i), (16 - 22i)},
-		{(21 + 16i), (21 - 16i)},
-	}
-	got := T.FfSamplingFFT(t0, sigmin)
-	if !reflect.DeepEqual(got, want) {
-		TestFfSamplingFFT(t)
-		//t.Errorf("FfSamplingFFT(%v, %v, %v, %v, %v) = %v, want %v", t0, l10, T0, T1, sigmin, got, want)
+	got, err := FfSamplingFFT(t0, T, sigmin, cryptoRand.Reader)
+	if err != nil {
+		t.Fatalf("FfSamplingFFT returned %v", err)
+	}
+	// The samples are integer polynomials close to the target.
+	for i := range got {
+		target := fft.IFFT(t0[i])
+		for j, coef := range fft.IFFT(got[i]) {
+			if math.Abs(coef-math.Round(coef)) > 1e-9 {
+				t.Errorf("z[%d][%d] = %v is not an integer", i, j, coef)
+			}
+			if math.Abs(coef-target[j]) > 10 {
+				t.Errorf("z[%d][%d] = %v is too far from %v", i, j, coef, target[j])
+			}
+		}
 	}
 }
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// The seed corpus of FuzzDecompress is in testdata/fuzz.
+
+func FuzzDecompress(f *testing.F) {
+	f.Fuzz(func(t *testing.T, x []byte, n uint16) {
+		n = n%1024 + 1
+		TrimI16Decode(x, int(n), 12)
+		v, err := Decompress(x, len(x), int(n))
+		if err == nil && len(v) != int(n) {
+			t.Fatalf("Decompress returned %d coefficients, want %d", len(v), n)
+		}
+		strict, err := DecompressStrict(x, int(n))
+		if err != nil {
+			return
+		}
+		// The canonical encoding is also accepted by Decompress, and
+		// is the one produced by Compress
+		if !reflect.DeepEqual(strict, v) {
+			t.Fatalf("DecompressStrict returned %v, Decompress %v", strict, v)
+		}
+		coefs := make([]int16, len(strict))
+		for i, c := range strict {
+			coefs[i] = int16(c)
+		}
+		encoded, err := Compress(coefs, len(x))
+		if err != nil || !bytes.Equal(encoded, x) {
+			t.Fatalf("Compress does not reproduce the canonical encoding: %v", err)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	cryptoRand "crypto/rand"
 	"errors"
+	"io"
 	"math"
 	"math/big"
 
@@ -283,13 +285,26 @@ func GsNorm(f, g []float64, q float64) float64 {
 }
 
 func GenPoly(n uint16) []int16 {
+	f, err := GenPolyFrom(n, cryptoRand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// GenPolyFrom is GenPoly drawing its randomness from randomBytes.
+func GenPolyFrom(n uint16, randomBytes io.Reader) ([]int16, error) {
 	sigma := 1.43300980528773 //1.17 * sqrt(12289 / 8192)
 	if n > 4096 {
 		panic("n < 4096")
 	}
 	var f0 []int8
 	for i := 0; i < 4096; i++ {
-		f0 = append(f0, Samplerz(0, sigma, (sigma-0.001)))
+		z, err := SamplerzFrom(0, sigma, (sigma - 0.001), randomBytes)
+		if err != nil {
+			return nil, err
+		}
+		f0 = append(f0, int8(z))
 	}
 	f := make([]int16, n)
 	k := int(math.Floor(4096 / float64(n)))
@@ -303,13 +318,29 @@ func GenPoly(n uint16) []int16 {
 		}
 		f[i] = int16(sum)
 	}
-	return f
+	return f, nil
 }
 
 func NtruGen(n uint16) (f, g, F, G []int16) {
+	f, g, F, G, err := NtruGenFrom(n, cryptoRand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return f, g, F, G
+}
+
+// NtruGenFrom is NtruGen drawing its randomness from randomBytes, so that a
+// key pair can be derived from a seed.
+func NtruGenFrom(n uint16, randomBytes io.Reader) (f, g, F, G []int16, err error) {
 	for {
-		f := GenPoly(n)
-		g := GenPoly(n)
+		f, err := GenPolyFrom(n, randomBytes)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		g, err := GenPolyFrom(n, randomBytes)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
 
 		if GsNorm(util.Int16ToFloat64(f), util.Int16ToFloat64(g), float64(util.Q)) > (math.Pow(1.17, 2) * float64(util.Q)) {
 			continue
@@ -326,6 +357,6 @@ func NtruGen(n uint16) (f, g, F, G []int16) {
 
 		F := util.BigIntToInt16(BigF)
 		G := util.BigIntToInt16(BigG)
-		return f, g, F, G
+		return f, g, F, G, nil
 	}
 }
@@ -1,8 +1,11 @@
 package internal
 
 import (
+	cryptoRand "crypto/rand"
+	"io"
 	"math"
 	"math/big"
+	"math/bits"
 
 	"github.com/Indra4091/falconGo/src/types"
 	"github.com/Indra4091/falconGo/src/util"
@@ -106,13 +109,19 @@ func approxexp(x, ccs float64) uint64 {
 	// Since z is positive, int is equivalent to floor
 	z := uint64(x * (1 << 63))
 	for _, elt := range C[1:] {
-		y = elt - ((z * y) >> 63)
+		y = elt - mulShift63(z, y)
 	}
 	z = uint64(ccs*float64(1<<63)) << 1
-	y = (z * y) >> 63
+	y = mulShift63(z, y)
 	return y
 }
 
+// mulShift63 returns (x * y) >> 63 computed over 128 bits.
+func mulShift63(x, y uint64) uint64 {
+	hi, lo := bits.Mul64(x, y)
+	return hi<<1 | lo>>63
+}
+
 // Require: Floating point values x, ccs ≥ 0
 // Ensure: A single bit, equal to 1 with probability ≈ ccs · exp(−x)
 // 1: s ← ⌊x/ ln(2)⌋
@@ -127,20 +136,23 @@ func approxexp(x, ccs float64) uint64 {
 // 10: return Jw < 0K ▷ Return 1 with probability 2−64 · z ≈ ccs · exp(−x)
 // https://falcon-sign.info/falcon.pdf#cf
 
-func berexp(x, ccs float64, randomBytes []byte) bool {
+func berexp(x, ccs float64, randomBytes io.Reader) (bool, error) {
 	var w int
+	var b [1]byte
 	s := math.Floor(x * ILN2)
 	r := x - s*LN2
 	s = util.Min(s, 63)
 	z := (approxexp(r, ccs) - 1) >> int(s)
-	for i := 56; i >= -8; i -= 8 {
-		b := int(new(big.Int).SetBytes(randomBytes).Uint64())
-		w = b - int((z>>uint64(i)))&0xFF
+	for i := 56; i >= 0; i -= 8 {
+		if _, err := io.ReadFull(randomBytes, b[:]); err != nil {
+			return false, err
+		}
+		w = int(b[0]) - int((z>>uint64(i))&0xFF)
 		if w != 0 {
 			break
 		}
 	}
-	return w < 0
+	return w < 0, nil
 }
 
 // Given floating-point values mu, sigma (and sigmin),
@@ -157,50 +169,41 @@ func berexp(x, ccs float64, randomBytes []byte) bool {
 // - a sample z from the distribution D_{Z, mu, sigma}.
 // https://falcon-sign.info/falcon.pdf#58
 func Samplerz(mu, sigma, sigmin float64) int8 {
+	z, err := SamplerzFrom(mu, sigma, sigmin, cryptoRand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return int8(z)
+}
+
+// SamplerzFrom is Samplerz drawing its randomness from randomBytes, which
+// makes the sampler reproducible for known-answer tests. Unlike Samplerz the
+// result is not truncated, so it can be used for centers far from zero.
+func SamplerzFrom(mu, sigma, sigmin float64, randomBytes io.Reader) (int, error) {
 	s := int(math.Floor(mu))
 	r := mu - float64(s)
 	dss := 1 / (2 * sigma * sigma)
 	ccs := sigmin / sigma
-	var fb [9]byte
+	var fb [RCDTprecLen]byte
 	var sb [1]byte
-	var tb []byte
 	for {
-		fb, sb, tb = generateAndSplitRandBytes()
+		if _, err := io.ReadFull(randomBytes, fb[:]); err != nil {
+			return 0, err
+		}
 		z0 := BaseSampler(fb)
-		b := int(new(big.Int).SetBytes(sb[:]).Uint64())
-		b &= 1
+		if _, err := io.ReadFull(randomBytes, sb[:]); err != nil {
+			return 0, err
+		}
+		b := int(sb[0]) & 1
 		z := float64(b + (2*b-1)*z0)
 		x := math.Pow((z-r), 2) * dss
 		x -= math.Pow(float64(z0), 2) * inv2sigma2
-		if berexp(x, ccs, tb) {
-			return int8(s + int(z))
+		accept, err := berexp(x, ccs, randomBytes)
+		if err != nil {
+			return 0, err
+		}
+		if accept {
+			return s + int(z), nil
 		}
 	}
 }
-
-func generateAndSplitRandBytes() ([9]byte, [1]byte, []byte) {
-	rb, err := generateRandBytes()
-	if err != nil {
-		panic(err)
-	}
-	return splitRandBytes(rb)
-}
-
-func generateRandBytes() ([11]byte, error) {
-	var rb [11]byte
-	err := util.RandomBytes(rb[:])
-	return rb, err
-}
-
-// splitRandBytes will split a byte slice (rb) into three byte slices
-func splitRandBytes(randBytes [11]byte) ([9]byte, [1]byte, []byte) {
-	var baseSamplerRandBytes [9]byte
-	var samplerzRandBytes [1]byte
-	var berexpSamplerRandBytes []byte
-
-	copy(baseSamplerRandBytes[:], randBytes[:9])
-	copy(samplerzRandBytes[:], randBytes[9:10])
-	berexpSamplerRandBytes = randBytes[10:]
-
-	return baseSamplerRandBytes, samplerzRandBytes, berexpSamplerRandBytes
-}
@@ -1,27 +1,21 @@
 package internal
 
 import (
-	"testing"
-
-	"github.com/Indra4091/falconGo/src/util"
-)
-
-/*
-import (
-
+	"bytes"
 	"encoding/hex"
 	"testing"
 
+	"github.com/Indra4091/falconGo/src/util"
 )
 
-	func decodeHexString(hexString string) []byte {
-		byteSlice, err := hex.DecodeString(hexString)
-		if err != nil {
-			panic(err)
-		}
-		return byteSlice
+func decodeHexString(hexString string) []byte {
+	byteSlice, err := hex.DecodeString(hexString)
+	if err != nil {
+		panic(err)
 	}
-*/
+	return byteSlice
+}
+
 func TestBaseSampler(t *testing.T) {
 	// Test that baseSampler returns a value in the expected range.
 	var data [9]byte
@@ -36,7 +30,6 @@ func TestBaseSampler(t *testing.T) {
 	}
 }
 
-/*
 func TestSamplerZ(t *testing.T) {
 	type vectors struct {
 		center            float64
@@ -145,8 +138,17 @@ func TestSamplerZ(t *testing.T) {
 		},
 	}
 	sigmin := 1.277833697
+	for i, tv := range testVectors {
+		got, err := SamplerzFrom(tv.center, tv.standardDeviation, sigmin, bytes.NewReader(tv.randombytes))
+		if err != nil {
+			t.Errorf("vector %d: SamplerzFrom returned %v", i, err)
+			continue
+		}
+		if got != tv.Output {
+			t.Errorf("vector %d: SamplerzFrom = %d, want %d", i, got, tv.Output)
+		}
+	}
 }
-*/
 
 func TestSamplerz(t *testing.T) {
 	sigma := 1.43300980528773
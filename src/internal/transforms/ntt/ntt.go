@@ -27,7 +27,9 @@ var sqr1 = roots_dict_Zq[2][0] // sqr1 is a square root of (-1) mod q (currently
 const NTTratio uint8 = 1
 
 var (
-	ErrDivByZero = errors.New("Division by zero")
+	ErrDivByZero      = errors.New("Division by zero")
+	ErrLengthMismatch = errors.New("polynomials of different lengths")
+	ErrInvalidLength  = errors.New("polynomial length is not a supported power of two")
 )
 
 // SplitNTT split a polynomial f in two or three polynomials
@@ -152,19 +154,30 @@ func INTT(fNTT []int16) []int16 {
 	return f
 }
 
-// Addition of two polynomials (coefficient representation).
-func addZq(f, g []int16) []int16 {
-	res := make([]int16, len(f))
+// checkLengths returns an error if f and g cannot be operands of the same
+// NTT operation.
+func checkLengths(f, g []int16) error {
 	if len(f) != len(g) {
-		panic("lenght of f != lengh of g")
+		return ErrLengthMismatch
+	}
+	if _, ok := roots_dict_Zq[int16(len(f))]; !ok || len(f) > 1024 {
+		return ErrInvalidLength
 	}
+	return nil
+}
 
+// Addition of two polynomials (coefficient representation).
+func addZq(f, g []int16) ([]int16, error) {
+	if len(f) != len(g) {
+		return nil, ErrLengthMismatch
+	}
+	res := make([]int16, len(f))
 	for i := range f {
-		z := int(f[i] + g[i])
+		z := int(f[i]) + int(g[i])
 		z = util.Pmod(z, int(util.Q))
 		res[i] = int16(z)
 	}
-	return res
+	return res, nil
 }
 
 // Negation of a polynomials (any representation).
@@ -180,20 +193,30 @@ func negZq(f []int16) []int16 {
 }
 
 // Substraction of two polynomials (any representation).
-func SubZq(f, g []int16) []int16 {
+func SubZq(f, g []int16) ([]int16, error) {
 	g = negZq(g)
 	return addZq(f, g)
 }
 
 // Multiplication of two polynomials (coefficient representation).
-func MulZq(f, g []int16) []int16 {
+func MulZq(f, g []int16) ([]int16, error) {
+	if err := checkLengths(f, g); err != nil {
+		return nil, err
+	}
 	ft := NTT(f)
 	gt := NTT(g)
-	return INTT(mulNTT(ft, gt))
+	prod, err := mulNTT(ft, gt)
+	if err != nil {
+		return nil, err
+	}
+	return INTT(prod), nil
 }
 
 // Division of two polynomials (coefficient representation).
 func DivZq(f, g []int16) ([]int16, error) {
+	if err := checkLengths(f, g); err != nil {
+		return nil, err
+	}
 	ft := NTT(f)
 	gt := NTT(g)
 	divNTT, err := divNTT(ft, gt)
@@ -204,34 +227,33 @@ func DivZq(f, g []int16) ([]int16, error) {
 }
 
 // Addition of two polynomials (NTT representation).
-func addNTT(fNTT, g_NTT []int16) []int16 {
+func addNTT(fNTT, g_NTT []int16) ([]int16, error) {
 	return addZq(fNTT, g_NTT)
 }
 
 // Substraction of two polynomials (NTT representation).
-func subNTT(fNTT, g_NTT []int16) []int16 {
+func subNTT(fNTT, g_NTT []int16) ([]int16, error) {
 	return SubZq(fNTT, g_NTT)
 }
 
-// Multiplication of two polynomials (coefficient representation).
-func mulNTT(fNTT, g_NTT []int16) []int16 {
-	res := make([]int16, len(fNTT))
+// Multiplication of two polynomials (NTT representation).
+func mulNTT(fNTT, g_NTT []int16) ([]int16, error) {
 	if len(fNTT) != len(g_NTT) {
-		panic("lenght of fNTT != lengh of g_NTT")
+		return nil, ErrLengthMismatch
 	}
-
+	res := make([]int16, len(fNTT))
 	for i := range fNTT {
 		z := int(fNTT[i]) * int(g_NTT[i])
 		z = util.Pmod(z, int(util.Q))
 		res[i] = int16(z)
 	}
-	return res
+	return res, nil
 }
 
 // Division of two polynomials (NTT representation).
 func divNTT(fNTT, g_NTT []int16) ([]int16, error) {
 	if len(fNTT) != len(g_NTT) {
-		panic("lenght of fNTT != lengh of g_NTT")
+		return nil, ErrLengthMismatch
 	}
 	res := make([]int16, len(fNTT))
 	for _, elt := range g_NTT {
@@ -66,9 +66,25 @@ func TestMulZq(t *testing.T) {
 	var g = []int16{10799, 6047, 4981, 12251, 3728, 271, 10710, 2031, 1507, 11686, 7495, 5929, 10484, 657, 118, 312, 697, 10761, 4697, 8764, 5858, 1422, 3398, 106, 2720, 7658, 11637, 614, 5677, 605, 2383, 1344, 10035, 12069, 4676, 1060, 8957, 8276, 7946, 9156, 10292, 6859, 9726, 7405, 6724, 6479, 4142, 3929, 10225, 4815, 982, 5536, 2312, 5176, 6962, 4888, 4932, 6558, 11804, 8035, 3279, 10832, 244, 3188}
 	var want = []int16{8830, 5637, 956, 5582, 228, 10121, 8668, 5598, 6720, 8223, 3172, 5855, 313, 276, 4711, 9208, 8306, 9632, 6807, 7926, 6697, 11265, 5252, 10179, 9448, 6155, 5237, 7870, 11273, 4691, 4263, 10665, 10911, 5101, 5412, 1766, 10254, 6255, 6518, 11467, 10913, 5024, 3669, 7762, 5661, 3878, 5243, 2454, 1903, 11724, 10208, 4252, 5305, 38, 7318, 8396, 4810, 7170, 9086, 8240, 1744, 927, 5886, 11965}
 
-	got := MulZq(f, g)
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("MulZq = %v, want %v", got, want)
+	got, err := MulZq(f, g)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("MulZq = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestLengthMismatch(t *testing.T) {
+	f := make([]int16, 8)
+	if _, err := MulZq(f, f[:4]); err != ErrLengthMismatch {
+		t.Errorf("MulZq of different lengths returned %v, want %v", err, ErrLengthMismatch)
+	}
+	if _, err := DivZq(f[:3], f[:3]); err != ErrInvalidLength {
+		t.Errorf("DivZq of length 3 returned %v, want %v", err, ErrInvalidLength)
+	}
+	if _, err := SubZq(f, f[:4]); err != ErrLengthMismatch {
+		t.Errorf("SubZq of different lengths returned %v, want %v", err, ErrLengthMismatch)
+	}
+	if _, err := divNTT(f, f[:4]); err != ErrLengthMismatch {
+		t.Errorf("divNTT of different lengths returned %v, want %v", err, ErrLengthMismatch)
 	}
 }
 
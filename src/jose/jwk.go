@@ -0,0 +1,239 @@
+// Package jose implements JSON Web Keys, JSON Web Signatures and JSON Web
+// Tokens for Falcon keys.
+//
+// Keys use the "AKP" (algorithm key pair) key type of the JOSE and COSE
+// post-quantum drafts: "pub" holds the public key encoding of
+// falcon.MarshalPublicKey and "priv" the private key encoding of
+// falcon.MarshalPrivateKey. Signatures are pure FN-DSA signatures with an
+// empty context, with the "alg" identifiers FN-DSA-512 and FN-DSA-1024.
+package jose
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+const (
+	// KeyTypeAKP is the key type of algorithm key pairs.
+	KeyTypeAKP = "AKP"
+	// AlgFNDSA512 identifies Falcon-512 signatures.
+	AlgFNDSA512 = "FN-DSA-512"
+	// AlgFNDSA1024 identifies Falcon-1024 signatures.
+	AlgFNDSA1024 = "FN-DSA-1024"
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned when an alg or kty is not a Falcon one
+	ErrUnsupportedAlgorithm = errors.New("jose: unsupported algorithm")
+	// ErrInvalidKey is returned when a JWK cannot be decoded or does not match its alg
+	ErrInvalidKey = errors.New("jose: invalid key")
+	// ErrNoPrivateKey is returned when a private key is requested from a public JWK
+	ErrNoPrivateKey = errors.New("jose: JWK has no private key")
+)
+
+var b64 = base64.RawURLEncoding
+
+// Algorithm returns the alg identifier of Falcon keys of degree n.
+func Algorithm(n uint16) (string, error) {
+	switch n {
+	case 512:
+		return AlgFNDSA512, nil
+	case 1024:
+		return AlgFNDSA1024, nil
+	}
+	return "", ErrUnsupportedAlgorithm
+}
+
+// degree returns the degree of the keys of the alg identifier.
+func degree(alg string) (uint16, error) {
+	switch alg {
+	case AlgFNDSA512:
+		return 512, nil
+	case AlgFNDSA1024:
+		return 1024, nil
+	}
+	return 0, ErrUnsupportedAlgorithm
+}
+
+// JWK is a JSON Web Key holding a Falcon public key, and optionally the
+// matching private key.
+type JWK struct {
+	KeyID     string
+	Algorithm string
+	Use       string
+	Public    []byte
+	Private   []byte
+}
+
+type jwkJSON struct {
+	Kty  string `json:"kty"`
+	Alg  string `json:"alg"`
+	Kid  string `json:"kid,omitempty"`
+	Use  string `json:"use,omitempty"`
+	Pub  string `json:"pub"`
+	Priv string `json:"priv,omitempty"`
+}
+
+// NewJWK returns the JWK of a public key.
+func NewJWK(pubKey *falcon.PublicKey) (*JWK, error) {
+	alg, err := Algorithm(pubKey.Degree())
+	if err != nil {
+		return nil, err
+	}
+	pub, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &JWK{Algorithm: alg, Public: pub}, nil
+}
+
+// NewPrivateJWK returns the JWK of a private key, which includes its public key.
+func NewPrivateJWK(privKey *falcon.PrivateKey) (*JWK, error) {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := NewJWK(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Private, err = falcon.MarshalPrivateKey(privKey); err != nil {
+		return nil, err
+	}
+	return jwk, nil
+}
+
+// IsPrivate reports whether the JWK holds a private key.
+func (jwk *JWK) IsPrivate() bool {
+	return len(jwk.Private) > 0
+}
+
+// PublicOnly returns a copy of the JWK without its private key.
+func (jwk *JWK) PublicOnly() *JWK {
+	public := *jwk
+	public.Private = nil
+	return &public
+}
+
+// PublicKey decodes the public key of the JWK.
+func (jwk *JWK) PublicKey() (*falcon.PublicKey, error) {
+	n, err := degree(jwk.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := falcon.ParsePublicKey(jwk.Public)
+	if err != nil || pubKey.Degree() != n {
+		return nil, ErrInvalidKey
+	}
+	return pubKey, nil
+}
+
+// PrivateKey decodes the private key of the JWK and checks that it matches
+// the public key.
+func (jwk *JWK) PrivateKey() (*falcon.PrivateKey, error) {
+	if !jwk.IsPrivate() {
+		return nil, ErrNoPrivateKey
+	}
+	n, err := degree(jwk.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := falcon.ParsePrivateKey(jwk.Private)
+	if err != nil || privKey.Degree() != n {
+		return nil, ErrInvalidKey
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	pub, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil || string(pub) != string(jwk.Public) {
+		return nil, ErrInvalidKey
+	}
+	return privKey, nil
+}
+
+// MarshalJSON encodes the JWK.
+func (jwk *JWK) MarshalJSON() ([]byte, error) {
+	if _, err := degree(jwk.Algorithm); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jwkJSON{
+		Kty:  KeyTypeAKP,
+		Alg:  jwk.Algorithm,
+		Kid:  jwk.KeyID,
+		Use:  jwk.Use,
+		Pub:  b64.EncodeToString(jwk.Public),
+		Priv: b64.EncodeToString(jwk.Private),
+	})
+}
+
+// UnmarshalJSON decodes a JWK and checks its keys.
+func (jwk *JWK) UnmarshalJSON(data []byte) error {
+	var raw jwkJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Kty != KeyTypeAKP {
+		return ErrUnsupportedAlgorithm
+	}
+	pub, err := b64.DecodeString(raw.Pub)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	var priv []byte
+	if raw.Priv != "" {
+		if priv, err = b64.DecodeString(raw.Priv); err != nil {
+			return ErrInvalidKey
+		}
+	}
+	decoded := JWK{KeyID: raw.Kid, Algorithm: raw.Alg, Use: raw.Use, Public: pub, Private: priv}
+	if _, err := decoded.PublicKey(); err != nil {
+		return err
+	}
+	if decoded.IsPrivate() {
+		if _, err := decoded.PrivateKey(); err != nil {
+			return err
+		}
+	}
+	*jwk = decoded
+	return nil
+}
+
+// Thumbprint returns the base64url SHA-256 JWK thumbprint (RFC 7638) over the
+// required members alg, kty and pub, in lexicographic order.
+func (jwk *JWK) Thumbprint() (string, error) {
+	if _, err := degree(jwk.Algorithm); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(struct {
+		Alg string `json:"alg"`
+		Kty string `json:"kty"`
+		Pub string `json:"pub"`
+	}{jwk.Algorithm, KeyTypeAKP, b64.EncodeToString(jwk.Public)})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(canonical)
+	return b64.EncodeToString(h[:]), nil
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// Key returns the keys of the set with the given key ID.
+func (set *JWKSet) Key(kid string) []*JWK {
+	var keys []*JWK
+	for _, key := range set.Keys {
+		if key.KeyID == kid {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,150 @@
+package jose
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+// The vectors in testdata are made with the Falcon-512 key of
+// ../testdata/falcon512_priv.pem.
+
+func readTestdata(t *testing.T, name string) []byte {
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func loadJWK(t *testing.T, name string) *JWK {
+	var jwk JWK
+	if err := json.Unmarshal(readTestdata(t, name), &jwk); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return &jwk
+}
+
+func loadKeys(t *testing.T) (*falcon.PrivateKey, *falcon.PublicKey) {
+	privKey, err := loadJWK(t, "falcon512_private.jwk").PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return privKey, privKey.GetPublicKey()
+}
+
+func TestJWK(t *testing.T) {
+	private := loadJWK(t, "falcon512_private.jwk")
+	public := loadJWK(t, "falcon512_public.jwk")
+	if !private.IsPrivate() || public.IsPrivate() {
+		t.Fatalf("unexpected private keys")
+	}
+	if !reflect.DeepEqual(private.PublicOnly(), public) {
+		t.Errorf("public JWK does not match the private JWK")
+	}
+	if public.KeyID != "falcon512-test" || public.Use != "sig" || public.Algorithm != AlgFNDSA512 || len(public.Public) != 897 {
+		t.Errorf("unexpected JWK fields")
+	}
+
+	pemKey, err := falcon.ParsePrivateKeyPEM(mustRead(t, "../testdata/falcon512_priv.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := private.PrivateKey()
+	if err != nil {
+		t.Fatalf("PrivateKey returned %v", err)
+	}
+	if !reflect.DeepEqual(privKey, pemKey) {
+		t.Errorf("JWK private key differs from the PEM key")
+	}
+
+	jwk, err := NewPrivateJWK(pemKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwk.KeyID, jwk.Use = "falcon512-test", "sig"
+	encoded, err := json.MarshalIndent(jwk, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded)+"\n" != string(readTestdata(t, "falcon512_private.jwk")) {
+		t.Errorf("MarshalJSON does not reproduce falcon512_private.jwk")
+	}
+	if _, err := public.PrivateKey(); err != ErrNoPrivateKey {
+		t.Errorf("PrivateKey of a public JWK returned %v", err)
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	thumbprint, err := loadJWK(t, "falcon512_public.jwk").Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "WqhTpyMEwNeZgCzSLSwhcgNWf0HTcrij9cHTHAkfrZI"; thumbprint != want {
+		t.Errorf("Thumbprint() = %s, want %s", thumbprint, want)
+	}
+	private, _ := loadJWK(t, "falcon512_private.jwk").Thumbprint()
+	if private != thumbprint {
+		t.Errorf("the thumbprint depends on the private key")
+	}
+}
+
+func TestJWKInvalid(t *testing.T) {
+	var public map[string]string
+	json.Unmarshal(readTestdata(t, "falcon512_public.jwk"), &public)
+	var private map[string]string
+	json.Unmarshal(readTestdata(t, "falcon512_private.jwk"), &private)
+
+	testCases := []struct {
+		name   string
+		modify func(m map[string]string)
+	}{
+		{"kty", func(m map[string]string) { m["kty"] = "OKP" }},
+		{"alg", func(m map[string]string) { m["alg"] = AlgFNDSA1024 }},
+		{"pub encoding", func(m map[string]string) { m["pub"] = "!!" }},
+		{"pub length", func(m map[string]string) { m["pub"] = m["pub"][:100] }},
+		{"priv mismatch", func(m map[string]string) {
+			pub := []byte(m["pub"])
+			pub[10] ^= 1
+			m["pub"] = string(pub)
+		}},
+	}
+	for _, tc := range testCases {
+		m := make(map[string]string)
+		for k, v := range private {
+			m[k] = v
+		}
+		tc.modify(m)
+		data, _ := json.Marshal(m)
+		var jwk JWK
+		if err := json.Unmarshal(data, &jwk); err == nil {
+			t.Errorf("%s: invalid JWK was decoded", tc.name)
+		}
+	}
+}
+
+func TestJWKSet(t *testing.T) {
+	set := JWKSet{Keys: []*JWK{loadJWK(t, "falcon512_public.jwk")}}
+	data, err := json.Marshal(&set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded JWKSet
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Key("falcon512-test")) != 1 || len(decoded.Key("other")) != 0 {
+		t.Errorf("Key does not find the keys by ID")
+	}
+}
+
+func mustRead(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,194 @@
+package jose
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+var (
+	// ErrInvalidJWS is returned when a JWS cannot be decoded
+	ErrInvalidJWS = errors.New("jose: malformed JWS")
+	// ErrInvalidSignature is returned when no signature of a JWS verifies
+	ErrInvalidSignature = errors.New("jose: invalid signature")
+	// ErrUnsupportedCritical is returned when a JWS has critical header parameters
+	ErrUnsupportedCritical = errors.New("jose: unsupported critical header parameter")
+)
+
+// Header is the protected header of a JWS. The alg parameter is set from the
+// signing key.
+type Header struct {
+	Algorithm   string   `json:"alg"`
+	KeyID       string   `json:"kid,omitempty"`
+	Type        string   `json:"typ,omitempty"`
+	ContentType string   `json:"cty,omitempty"`
+	Critical    []string `json:"crit,omitempty"`
+}
+
+// signingInput returns the encoded protected header and the JWS signing input.
+func signingInput(header *Header, encodedPayload string) (string, []byte, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", nil, err
+	}
+	protected := b64.EncodeToString(headerJSON)
+	return protected, []byte(protected + "." + encodedPayload), nil
+}
+
+// sign signs encodedPayload with privKey and returns the encoded protected
+// header and signature.
+func sign(encodedPayload string, privKey *falcon.PrivateKey, header Header) (string, string, error) {
+	alg, err := Algorithm(privKey.Degree())
+	if err != nil {
+		return "", "", err
+	}
+	header.Algorithm = alg
+	protected, input, err := signingInput(&header, encodedPayload)
+	if err != nil {
+		return "", "", err
+	}
+	signature, err := privKey.SignFNDSA(input, nil)
+	if err != nil {
+		return "", "", err
+	}
+	return protected, b64.EncodeToString(signature), nil
+}
+
+// verify checks the signature over protected.encodedPayload and returns the
+// decoded protected header.
+func verify(protected, encodedPayload, encodedSignature string, pubKey *falcon.PublicKey) (*Header, error) {
+	headerJSON, err := b64.DecodeString(protected)
+	if err != nil {
+		return nil, ErrInvalidJWS
+	}
+	var header Header
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, ErrInvalidJWS
+	}
+	if len(header.Critical) > 0 {
+		return nil, ErrUnsupportedCritical
+	}
+	n, err := degree(header.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if n != pubKey.Degree() {
+		return nil, ErrInvalidSignature
+	}
+	signature, err := b64.DecodeString(encodedSignature)
+	if err != nil {
+		return nil, ErrInvalidJWS
+	}
+	if pubKey.VerifyFNDSA([]byte(protected+"."+encodedPayload), signature, nil) != nil {
+		return nil, ErrInvalidSignature
+	}
+	return &header, nil
+}
+
+// SignCompact signs payload and returns the JWS compact serialization.
+func SignCompact(payload []byte, privKey *falcon.PrivateKey, header Header) (string, error) {
+	encodedPayload := b64.EncodeToString(payload)
+	protected, signature, err := sign(encodedPayload, privKey, header)
+	if err != nil {
+		return "", err
+	}
+	return protected + "." + encodedPayload + "." + signature, nil
+}
+
+// VerifyCompact verifies a JWS compact serialization and returns its payload
+// and protected header.
+func VerifyCompact(token string, pubKey *falcon.PublicKey) ([]byte, *Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, ErrInvalidJWS
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, ErrInvalidJWS
+	}
+	header, err := verify(parts[0], parts[1], parts[2], pubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload, header, nil
+}
+
+// Signer is a key and the protected header of one signature of a JWS JSON serialization.
+type Signer struct {
+	Key    *falcon.PrivateKey
+	Header Header
+}
+
+type jwsSignature struct {
+	Protected string                 `json:"protected"`
+	Header    map[string]interface{} `json:"header,omitempty"`
+	Signature string                 `json:"signature"`
+}
+
+// jwsJSON holds both the general and the flattened JSON serializations.
+type jwsJSON struct {
+	Payload    string         `json:"payload"`
+	Signatures []jwsSignature `json:"signatures,omitempty"`
+	jwsSignature
+}
+
+// SignJSON signs payload with every signer and returns the JWS general JSON
+// serialization, or the flattened one when there is a single signer.
+func SignJSON(payload []byte, signers ...Signer) ([]byte, error) {
+	if len(signers) == 0 {
+		return nil, ErrInvalidJWS
+	}
+	encodedPayload := b64.EncodeToString(payload)
+	signatures := make([]jwsSignature, len(signers))
+	for i, signer := range signers {
+		protected, signature, err := sign(encodedPayload, signer.Key, signer.Header)
+		if err != nil {
+			return nil, err
+		}
+		signatures[i] = jwsSignature{Protected: protected, Signature: signature}
+	}
+	if len(signatures) == 1 {
+		return json.Marshal(struct {
+			Payload string `json:"payload"`
+			jwsSignature
+		}{encodedPayload, signatures[0]})
+	}
+	return json.Marshal(struct {
+		Payload    string         `json:"payload"`
+		Signatures []jwsSignature `json:"signatures"`
+	}{encodedPayload, signatures})
+}
+
+// VerifyJSON verifies a JWS general or flattened JSON serialization and
+// returns its payload and the protected header of the first signature that
+// verifies under pubKey.
+func VerifyJSON(data []byte, pubKey *falcon.PublicKey) ([]byte, *Header, error) {
+	var jws jwsJSON
+	if err := json.Unmarshal(data, &jws); err != nil {
+		return nil, nil, ErrInvalidJWS
+	}
+	signatures := jws.Signatures
+	if jws.Protected != "" || jws.Signature != "" {
+		if len(signatures) > 0 {
+			return nil, nil, ErrInvalidJWS
+		}
+		signatures = []jwsSignature{jws.jwsSignature}
+	}
+	payload, err := b64.DecodeString(jws.Payload)
+	if err != nil || len(signatures) == 0 {
+		return nil, nil, ErrInvalidJWS
+	}
+	err = ErrInvalidSignature
+	for _, signature := range signatures {
+		header, e := verify(signature.Protected, jws.Payload, signature.Signature, pubKey)
+		if e == nil {
+			return payload, header, nil
+		}
+		if e != ErrInvalidSignature {
+			err = e
+		}
+	}
+	return nil, nil, err
+}
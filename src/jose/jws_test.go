@@ -0,0 +1,112 @@
+package jose
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+func TestVerifyCompactVector(t *testing.T) {
+	_, pubKey := loadKeys(t)
+	token := strings.TrimSpace(string(readTestdata(t, "compact.jws")))
+	payload, header, err := VerifyCompact(token, pubKey)
+	if err != nil {
+		t.Fatalf("VerifyCompact returned %v", err)
+	}
+	if string(payload) != "Falcon JWS test payload" || header.Algorithm != AlgFNDSA512 || header.KeyID != "falcon512-test" {
+		t.Errorf("unexpected payload %q or header %+v", payload, header)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := []string{
+		parts[0] + "." + b64.EncodeToString([]byte("another payload")) + "." + parts[2],
+		b64.EncodeToString([]byte(`{"alg":"FN-DSA-1024","kid":"falcon512-test"}`)) + "." + parts[1] + "." + parts[2],
+		parts[0] + "." + parts[1],
+		parts[0] + "." + parts[1] + "." + parts[2][:100],
+	}
+	for i, token := range tampered {
+		if _, _, err := VerifyCompact(token, pubKey); err == nil {
+			t.Errorf("tampered token %d verified", i)
+		}
+	}
+}
+
+func TestSignCompact(t *testing.T) {
+	privKey, pubKey := loadKeys(t)
+	token, err := SignCompact([]byte("payload"), privKey, Header{Type: "example", Algorithm: "ignored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, header, err := VerifyCompact(token, pubKey)
+	if err != nil || string(payload) != "payload" || header.Algorithm != AlgFNDSA512 || header.Type != "example" {
+		t.Errorf("VerifyCompact returned %q, %+v, %v", payload, header, err)
+	}
+
+	_, otherPub, err := falcon.NewKeyPair(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := VerifyCompact(token, otherPub); err != ErrInvalidSignature {
+		t.Errorf("VerifyCompact with another key returned %v, want %v", err, ErrInvalidSignature)
+	}
+
+	critical, _ := SignCompact([]byte("payload"), privKey, Header{Critical: []string{"exp"}})
+	if _, _, err := VerifyCompact(critical, pubKey); err != ErrUnsupportedCritical {
+		t.Errorf("critical header returned %v, want %v", err, ErrUnsupportedCritical)
+	}
+}
+
+func TestVerifyJSONVector(t *testing.T) {
+	_, pubKey := loadKeys(t)
+	general := readTestdata(t, "general.json")
+	payload, header, err := VerifyJSON(general, pubKey)
+	if err != nil {
+		t.Fatalf("VerifyJSON returned %v", err)
+	}
+	if string(payload) != "Falcon JWS JSON payload" || header.KeyID != "falcon512-test" {
+		t.Errorf("unexpected payload %q or header %+v", payload, header)
+	}
+
+	// each signature of the general serialization verifies as a flattened one
+	var jws struct {
+		Payload    string           `json:"payload"`
+		Signatures []map[string]any `json:"signatures"`
+	}
+	if err := json.Unmarshal(general, &jws); err != nil || len(jws.Signatures) != 2 {
+		t.Fatalf("general.json has no two signatures: %v", err)
+	}
+	for i, signature := range jws.Signatures {
+		signature["payload"] = jws.Payload
+		flattened, _ := json.Marshal(signature)
+		_, header, err := VerifyJSON(flattened, pubKey)
+		if err != nil {
+			t.Errorf("signature %d: VerifyJSON returned %v", i, err)
+			continue
+		}
+		if i == 1 && (header.KeyID != "second" || header.ContentType != "text/plain") {
+			t.Errorf("signature %d: unexpected header %+v", i, header)
+		}
+	}
+}
+
+func TestSignJSON(t *testing.T) {
+	privKey, pubKey := loadKeys(t)
+	flattened, err := SignJSON([]byte("payload"), Signer{Key: privKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(flattened), "signatures") {
+		t.Errorf("a single signature was not flattened: %s", flattened)
+	}
+	if payload, _, err := VerifyJSON(flattened, pubKey); err != nil || string(payload) != "payload" {
+		t.Errorf("VerifyJSON returned %q, %v", payload, err)
+	}
+	if _, _, err := VerifyJSON([]byte(`{"payload":"cGF5bG9hZA","signatures":[]}`), pubKey); err != ErrInvalidJWS {
+		t.Errorf("JWS without signatures returned %v, want %v", err, ErrInvalidJWS)
+	}
+	if _, err := SignJSON([]byte("payload")); err != ErrInvalidJWS {
+		t.Errorf("SignJSON without signers returned %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package jose
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+var (
+	// ErrExpired is returned when a token is used after its expiration time
+	ErrExpired = errors.New("jose: token is expired")
+	// ErrNotValidYet is returned when a token is used before its not-before time
+	ErrNotValidYet = errors.New("jose: token is not valid yet")
+	// ErrInvalidIssuer is returned when the issuer of a token is not the expected one
+	ErrInvalidIssuer = errors.New("jose: invalid issuer")
+	// ErrInvalidAudience is returned when a token is not intended for the expected audience
+	ErrInvalidAudience = errors.New("jose: invalid audience")
+	// ErrInvalidType is returned when the typ header of a token is not JWT
+	ErrInvalidType = errors.New("jose: invalid token type")
+)
+
+// NumericDate is a JWT time, in seconds since the epoch.
+type NumericDate int64
+
+// NewNumericDate returns the NumericDate of t, truncated to the second.
+func NewNumericDate(t time.Time) *NumericDate {
+	date := NumericDate(t.Unix())
+	return &date
+}
+
+// Time returns the NumericDate as a time.Time.
+func (date NumericDate) Time() time.Time {
+	return time.Unix(int64(date), 0)
+}
+
+// UnmarshalJSON decodes a NumericDate, truncating fractional seconds.
+func (date *NumericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	if math.IsNaN(seconds) || seconds > math.MaxInt64 || seconds < math.MinInt64 {
+		return errors.New("jose: invalid NumericDate")
+	}
+	*date = NumericDate(seconds)
+	return nil
+}
+
+// Audience is the aud claim, a single string or an array of strings.
+type Audience []string
+
+// MarshalJSON encodes a single audience as a string.
+func (aud Audience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0])
+	}
+	return json.Marshal([]string(aud))
+}
+
+// UnmarshalJSON decodes a string or an array of strings.
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*aud = multiple
+	return nil
+}
+
+// Contains reports whether the audience includes aud.
+func (aud Audience) Contains(audience string) bool {
+	for _, a := range aud {
+		if a == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims are the registered claims of a JWT (RFC 7519, section 4.1).
+// Application claims are added by embedding Claims in another struct.
+type Claims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// Expected are the values Claims are validated against. Empty fields are not checked.
+type Expected struct {
+	Issuer   string
+	Audience string
+	// Time is the validation time; the zero value selects time.Now().
+	Time time.Time
+	// Leeway is the tolerated clock skew for exp and nbf.
+	Leeway time.Duration
+}
+
+// Validate checks the exp, nbf, iss and aud claims.
+func (claims *Claims) Validate(expected Expected) error {
+	now := expected.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Time().Add(expected.Leeway)) {
+		return ErrExpired
+	}
+	if claims.NotBefore != nil && now.Add(expected.Leeway).Before(claims.NotBefore.Time()) {
+		return ErrNotValidYet
+	}
+	if expected.Issuer != "" && claims.Issuer != expected.Issuer {
+		return ErrInvalidIssuer
+	}
+	if expected.Audience != "" && !claims.Audience.Contains(expected.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// SignJWT encodes claims, any value that encodes to a JSON object, and signs
+// them as a JWT with the given key ID.
+func SignJWT(claims interface{}, privKey *falcon.PrivateKey, kid string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	return SignCompact(payload, privKey, Header{KeyID: kid, Type: "JWT"})
+}
+
+// VerifyJWT verifies the signature of a JWT and decodes its payload into
+// claims. The claims are not validated, see Claims.Validate.
+func VerifyJWT(token string, pubKey *falcon.PublicKey, claims interface{}) (*Header, error) {
+	payload, header, err := VerifyCompact(token, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if header.Type != "" && !strings.EqualFold(header.Type, "JWT") {
+		return nil, ErrInvalidType
+	}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidJWS
+	}
+	return header, nil
+}
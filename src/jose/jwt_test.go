@@ -0,0 +1,97 @@
+package jose
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClaims struct {
+	Claims
+	Scope string `json:"scope"`
+}
+
+func TestVerifyJWTVector(t *testing.T) {
+	_, pubKey := loadKeys(t)
+	token := strings.TrimSpace(string(readTestdata(t, "token.jwt")))
+	var claims testClaims
+	header, err := VerifyJWT(token, pubKey, &claims)
+	if err != nil {
+		t.Fatalf("VerifyJWT returned %v", err)
+	}
+	if header.Type != "JWT" || header.KeyID != "falcon512-test" {
+		t.Errorf("unexpected header %+v", header)
+	}
+	if claims.Issuer != "https://issuer.example" || claims.Subject != "user-1" || claims.Scope != "read" ||
+		claims.ID != "id-1" || !claims.Audience.Contains("api.example") {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	issued := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if !claims.IssuedAt.Time().Equal(issued) || !claims.ExpiresAt.Time().Equal(issued.AddDate(1, 0, 0)) {
+		t.Errorf("unexpected times iat %v exp %v", claims.IssuedAt.Time(), claims.ExpiresAt.Time())
+	}
+
+	expected := Expected{Issuer: "https://issuer.example", Audience: "api.example", Time: issued.AddDate(0, 6, 0)}
+	if err := claims.Validate(expected); err != nil {
+		t.Errorf("Validate returned %v", err)
+	}
+}
+
+func TestClaimsValidate(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	claims := Claims{
+		Issuer:    "iss",
+		Audience:  Audience{"a", "b"},
+		ExpiresAt: NewNumericDate(now.Add(time.Hour)),
+		NotBefore: NewNumericDate(now.Add(-time.Hour)),
+	}
+	testCases := []struct {
+		expected Expected
+		want     error
+	}{
+		{Expected{Time: now}, nil},
+		{Expected{Issuer: "iss", Audience: "b", Time: now}, nil},
+		{Expected{Time: now.Add(time.Hour)}, ErrExpired},
+		{Expected{Time: now.Add(time.Hour), Leeway: time.Minute}, nil},
+		{Expected{Time: now.Add(-2 * time.Hour)}, ErrNotValidYet},
+		{Expected{Time: now.Add(-time.Hour - time.Minute), Leeway: 2 * time.Minute}, nil},
+		{Expected{Issuer: "other", Time: now}, ErrInvalidIssuer},
+		{Expected{Audience: "c", Time: now}, ErrInvalidAudience},
+	}
+	for i, tc := range testCases {
+		if err := claims.Validate(tc.expected); err != tc.want {
+			t.Errorf("case %d: Validate returned %v, want %v", i, err, tc.want)
+		}
+	}
+}
+
+func TestClaimsJSON(t *testing.T) {
+	var claims Claims
+	if err := json.Unmarshal([]byte(`{"aud":["x","y"],"exp":1700000000.75}`), &claims); err != nil {
+		t.Fatal(err)
+	}
+	if len(claims.Audience) != 2 || *claims.ExpiresAt != 1700000000 {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	encoded, _ := json.Marshal(Claims{Audience: Audience{"x"}})
+	if string(encoded) != `{"aud":"x"}` {
+		t.Errorf("single audience encoded as %s", encoded)
+	}
+}
+
+func TestSignJWT(t *testing.T) {
+	privKey, pubKey := loadKeys(t)
+	token, err := SignJWT(testClaims{Claims{Subject: "s"}, "write"}, privKey, "kid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var claims testClaims
+	if _, err := VerifyJWT(token, pubKey, &claims); err != nil || claims.Scope != "write" || claims.Subject != "s" {
+		t.Errorf("VerifyJWT returned %+v, %v", claims, err)
+	}
+	other, _ := SignCompact([]byte(`{}`), privKey, Header{Type: "at+jwt"})
+	if _, err := VerifyJWT(other, pubKey, &claims); err != ErrInvalidType {
+		t.Errorf("VerifyJWT of typ at+jwt returned %v, want %v", err, ErrInvalidType)
+	}
+}
@@ -0,0 +1,191 @@
+package falcon
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The vectors in testdata/kat come from the Falcon reference implementation
+// (see testdata/kat/README): the first entries of the NIST round-3 KAT files
+// and FN-DSA signatures over a message representative computed outside this
+// package.
+
+// katEntry is an entry of a NIST KAT response file.
+type katEntry map[string]string
+
+func readKAT(t *testing.T, name string) []katEntry {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []katEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " = ")
+		if !ok {
+			continue
+		}
+		if key == "count" {
+			entries = append(entries, katEntry{})
+		}
+		if len(entries) > 0 {
+			entries[len(entries)-1][key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func (e katEntry) bytes(t *testing.T, key string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(e[key])
+	if err != nil {
+		t.Fatalf("count %s: %s: %v", e["count"], key, err)
+	}
+	return b
+}
+
+// checkKATKeys parses the encoded keys of a vector and checks that they
+// re-encode to the same bytes and match each other.
+func checkKATKeys(t *testing.T, name string, pk, sk []byte) *PublicKey {
+	t.Helper()
+	pub, err := ParsePublicKey(pk)
+	if err != nil {
+		t.Fatalf("%s: ParsePublicKey returned %v", name, err)
+	}
+	if enc, err := MarshalPublicKey(pub); err != nil || !bytes.Equal(enc, pk) {
+		t.Errorf("%s: public key does not re-encode to the vector (%v)", name, err)
+	}
+	priv, err := ParsePrivateKey(sk)
+	if err != nil {
+		t.Fatalf("%s: ParsePrivateKey returned %v", name, err)
+	}
+	if enc, err := MarshalPrivateKey(priv); err != nil || !bytes.Equal(enc, sk) {
+		t.Errorf("%s: private key does not re-encode to the vector (%v)", name, err)
+	}
+	derived, err := priv.DerivePublicKey()
+	if err != nil {
+		t.Fatalf("%s: DerivePublicKey returned %v", name, err)
+	}
+	if !derived.Equal(pub) {
+		t.Errorf("%s: the public key derived from the private key differs from the vector", name)
+	}
+	return pub
+}
+
+func TestNISTKAT(t *testing.T) {
+	for n, name := range map[uint16]string{512: "testdata/kat/falcon512-KAT.rsp", 1024: "testdata/kat/falcon1024-KAT.rsp"} {
+		entries := readKAT(t, name)
+		if len(entries) == 0 {
+			t.Fatalf("%s: no entries", name)
+		}
+		for _, e := range entries {
+			id := name + " count " + e["count"]
+			pub := checkKATKeys(t, id, e.bytes(t, "pk"), e.bytes(t, "sk"))
+			msg, sm := e.bytes(t, "msg"), e.bytes(t, "sm")
+			// The NIST API bundles the signature as its bytelength on 16 bits,
+			// the salt, the message, the header 0x20 + LOGN and the
+			// compressed s2; the header of this package is 0x30 + LOGN.
+			siglen := int(sm[0])<<8 | int(sm[1])
+			if len(sm) != 2+SaltLen+len(msg)+siglen || !bytes.Equal(sm[2+SaltLen:2+SaltLen+len(msg)], msg) {
+				t.Fatalf("%s: malformed sm", id)
+			}
+			if sm[2+SaltLen+len(msg)] != 0x20+LOGN[n] {
+				t.Fatalf("%s: unexpected signature header %#x", id, sm[2+SaltLen+len(msg)])
+			}
+			sig := []byte{FormatCompressed.header(n)}
+			sig = append(sig, sm[2:2+SaltLen]...)
+			sig = append(sig, sm[3+SaltLen+len(msg):]...)
+			if err := pub.VerifyStrict(msg, sig, FormatCompressed); err != nil {
+				t.Errorf("%s: VerifyStrict returned %v", id, err)
+			}
+			msg[0] ^= 1
+			if pub.Verify(msg, sig) {
+				t.Errorf("%s: signature verified for another message", id)
+			}
+		}
+	}
+}
+
+type fndsaKATKey struct {
+	N          uint16 `json:"n"`
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+	Vectors    []struct {
+		PreHash   string `json:"pre_hash"`
+		Context   string `json:"context"`
+		Message   string `json:"message"`
+		Format    string `json:"format"`
+		Signature string `json:"signature"`
+	} `json:"vectors"`
+}
+
+func TestFNDSAKAT(t *testing.T) {
+	var keys []fndsaKATKey
+	if err := json.Unmarshal(mustReadFile(t, "testdata/kat/fndsa.json"), &keys); err != nil {
+		t.Fatal(err)
+	}
+	preHashes := map[string]PreHash{}
+	for ph := PreHashNone; ph <= PreHashSHAKE256; ph++ {
+		preHashes[ph.String()] = ph
+	}
+	formats := map[string]SignatureFormat{}
+	for _, format := range []SignatureFormat{FormatPadded, FormatCompressed, FormatCT} {
+		formats[format.String()] = format
+	}
+	tested := map[PreHash]bool{}
+	for _, key := range keys {
+		pk, err := hex.DecodeString(key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sk, err := hex.DecodeString(key.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub := checkKATKeys(t, "fndsa.json", pk, sk)
+		if pub.Degree() != key.N {
+			t.Fatalf("fndsa.json: key of degree %d, want %d", pub.Degree(), key.N)
+		}
+		for i, v := range key.Vectors {
+			ph, ok := preHashes[v.PreHash]
+			if !ok {
+				t.Fatalf("vector %d: unknown pre-hash %q", i, v.PreHash)
+			}
+			format, ok := formats[v.Format]
+			if !ok {
+				t.Fatalf("vector %d: unknown format %q", i, v.Format)
+			}
+			context, _ := hex.DecodeString(v.Context)
+			message, _ := hex.DecodeString(v.Message)
+			sig, err := hex.DecodeString(v.Signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := &Options{PreHash: ph, Context: context, Format: format, Strict: true}
+			if err := pub.VerifyFNDSA(message, sig, opts); err != nil {
+				t.Errorf("Falcon-%d %s %s: VerifyFNDSA returned %v", key.N, v.PreHash, v.Format, err)
+			}
+			opts.Context = append(context, '!')
+			if err := pub.VerifyFNDSA(message, sig, opts); err != ErrInvalidSignature {
+				t.Errorf("Falcon-%d %s %s: VerifyFNDSA with another context returned %v", key.N, v.PreHash, v.Format, err)
+			}
+			tested[ph] = true
+		}
+	}
+	for ph := range preHashes {
+		if !tested[preHashes[ph]] {
+			t.Errorf("no vector for pre-hash %s", ph)
+		}
+	}
+}
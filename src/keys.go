@@ -0,0 +1,235 @@
+package falcon
+
+import (
+	"errors"
+
+	"github.com/Indra4091/falconGo/src/internal"
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+/*
+Binary encodings of Falcon keys, as specified by the Falcon reference implementation:
+
+  - public key:  header 0x00 + LOGN, then h over 14 bits per coefficient
+  - private key: header 0x50 + LOGN, then f, g and F with a fixed bitlength per
+    coefficient depending on the degree; G is recomputed from f, g and F
+*/
+
+const (
+	headerPublicKey  byte = 0x00
+	headerPrivateKey byte = 0x50
+)
+
+var (
+	// ErrInvalidKeyEncoding is returned when a key encoding cannot be decoded
+	ErrInvalidKeyEncoding = errors.New("invalid key encoding")
+)
+
+// Bitlength of the coefficients of f, g (maxFgBits) and F, G (maxFGBits) in
+// the private key encoding, indexed by LOGN.
+var (
+	maxFgBits = [11]int{0, 8, 8, 8, 8, 8, 7, 7, 6, 6, 5}
+	maxFGBits = [11]int{0, 8, 8, 8, 8, 8, 8, 8, 8, 8, 8}
+)
+
+// Degree returns the degree n of the public key.
+func (pubKey *PublicKey) Degree() uint16 {
+	return pubKey.n
+}
+
+// Degree returns the degree n of the private key.
+func (privKey *PrivateKey) Degree() uint16 {
+	return privKey.n
+}
+
+// Polynomial returns a copy of the public polynomial h, with coefficients in [0, q).
+func (pubKey *PublicKey) Polynomial() []int16 {
+	return append([]int16(nil), pubKey.h...)
+}
+
+// NTT returns the public key polynomial h in the NTT domain, with
+// coefficients in [0, q).
+func (pubKey *PublicKey) NTT() []int16 {
+	return ntt.NTT(pubKey.h)
+}
+
+// Polynomials returns copies of the private polynomials f, g, F and G, in
+// the order expected by NewKeyPairFromPrivateKey.
+func (privKey *PrivateKey) Polynomials() [4][]int16 {
+	var polys [4][]int16
+	for i, poly := range [][]int16{privKey.f, privKey.g, privKey.F, privKey.G} {
+		polys[i] = append([]int16(nil), poly...)
+	}
+	return polys
+}
+
+// PublicKeyFromPolynomial returns the public key of the polynomial h, whose
+// length must be a valid degree and whose coefficients must lie in [0, q).
+func PublicKeyFromPolynomial(h []int16) (*PublicKey, error) {
+	n := uint16(len(h))
+	if len(h) > 1024 || !isValidDegree(n) {
+		return nil, ErrInvalidDegree
+	}
+	for _, coef := range h {
+		if coef < 0 || int(coef) >= util.Q {
+			return nil, ErrInvalidKeyEncoding
+		}
+	}
+	pubKey := NewPublicKey()
+	pubKey.n = n
+	pubKey.h = append([]int16(nil), h...)
+	return pubKey, nil
+}
+
+// publicKeyLen returns the bytelength of an encoded public key of degree n.
+func publicKeyLen(n uint16) int {
+	return 1 + (int(n)*14+7)>>3
+}
+
+// privateKeyLen returns the bytelength of an encoded private key of degree n.
+func privateKeyLen(n uint16) int {
+	logn := LOGN[n]
+	return 1 + 2*((int(n)*maxFgBits[logn]+7)>>3) + (int(n)*maxFGBits[logn]+7)>>3
+}
+
+// degreeFromHeader returns the degree announced by a key header of the given type.
+func degreeFromHeader(header, keyType byte) (uint16, error) {
+	if header&0xF0 != keyType {
+		return 0, ErrInvalidKeyEncoding
+	}
+	logn := header & 0x0F
+	if logn < 1 || logn > 10 {
+		return 0, ErrInvalidDegree
+	}
+	return 1 << logn, nil
+}
+
+// MarshalPublicKey encodes a public key as header‖h.
+func MarshalPublicKey(pubKey *PublicKey) ([]byte, error) {
+	if !isValidDegree(pubKey.n) || len(pubKey.h) != int(pubKey.n) {
+		return nil, ErrInvalidDegree
+	}
+	encH, err := internal.ModqEncode(pubKey.h)
+	if err != nil {
+		return nil, ErrInvalidKeyEncoding
+	}
+	return append([]byte{headerPublicKey + LOGN[pubKey.n]}, encH...), nil
+}
+
+// ParsePublicKey decodes a public key encoded by MarshalPublicKey.
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidKeyEncoding
+	}
+	n, err := degreeFromHeader(data[0], headerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != publicKeyLen(n) {
+		return nil, ErrInvalidKeyEncoding
+	}
+	h, err := internal.ModqDecode(data[1:], int(n))
+	if err != nil {
+		return nil, ErrInvalidKeyEncoding
+	}
+	pubKey := NewPublicKey()
+	pubKey.n = n
+	pubKey.h = h
+	return pubKey, nil
+}
+
+// MarshalPrivateKey encodes a private key as header‖f‖g‖F.
+func MarshalPrivateKey(privKey *PrivateKey) ([]byte, error) {
+	n := privKey.n
+	if !isValidDegree(n) {
+		return nil, ErrInvalidDegree
+	}
+	if len(privKey.f) != int(n) || len(privKey.g) != int(n) || len(privKey.F) != int(n) {
+		return nil, ErrInvalidPolysLength
+	}
+	logn := LOGN[n]
+	data := make([]byte, 0, privateKeyLen(n))
+	data = append(data, headerPrivateKey+logn)
+	for i, poly := range [][]int16{privKey.f, privKey.g, privKey.F} {
+		bits := maxFgBits[logn]
+		if i == 2 {
+			bits = maxFGBits[logn]
+		}
+		enc, err := internal.TrimI16Encode(poly, bits)
+		if err != nil {
+			return nil, ErrInvalidKeyEncoding
+		}
+		data = append(data, enc...)
+	}
+	return data, nil
+}
+
+// ParsePrivateKey decodes a private key encoded by MarshalPrivateKey.
+// G is recomputed from fG - gF = q mod (x^n + 1), and the key is validated
+// with PrivateKey.Validate.
+func ParsePrivateKey(data []byte) (*PrivateKey, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidKeyEncoding
+	}
+	n, err := degreeFromHeader(data[0], headerPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != privateKeyLen(n) {
+		return nil, ErrInvalidKeyEncoding
+	}
+	logn := LOGN[n]
+	var polys [3][]int16
+	data = data[1:]
+	for i := range polys {
+		bits := maxFgBits[logn]
+		if i == 2 {
+			bits = maxFGBits[logn]
+		}
+		size := (int(n)*bits + 7) >> 3
+		v, err := internal.TrimI16Decode(data[:size], int(n), bits)
+		if err != nil {
+			return nil, ErrInvalidKeyEncoding
+		}
+		polys[i] = util.IntToInt16(v)
+		data = data[size:]
+	}
+	f, g, F := polys[0], polys[1], polys[2]
+	G, err := completePrivateKey(f, g, F)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := GetPrivateKey(n, f, g, F, G)
+	if err != nil {
+		return nil, err
+	}
+	if err := privKey.Validate(); err != nil {
+		return nil, err
+	}
+	return privKey, nil
+}
+
+// completePrivateKey computes G = gF/f mod q with coefficients in (-q/2, q/2],
+// which is the exact G when fG - gF = q.
+func completePrivateKey(f, g, F []int16) ([]int16, error) {
+	gF, err := ntt.MulZq(g, F)
+	if err != nil {
+		return nil, ErrInvalidKeyEncoding
+	}
+	G, err := ntt.DivZq(gF, f)
+	if err != nil {
+		return nil, ErrInvalidKeyEncoding
+	}
+	maxG := int16(1<<(maxFGBits[LOGN[uint16(len(f))]]-1)) - 1
+	for i, v := range G {
+		if v > util.Q>>1 {
+			v -= util.Q
+		}
+		if v < -maxG || v > maxG {
+			return nil, ErrInvalidKeyEncoding
+		}
+		G[i] = v
+	}
+	return G, nil
+}
@@ -0,0 +1,143 @@
+package falcon
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+func TestMarshalPublicKey(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	data, err := MarshalPublicKey(pub)
+	if err != nil {
+		t.Fatalf("MarshalPublicKey returned %v", err)
+	}
+	if len(data) != 897 || data[0] != 0x09 {
+		t.Errorf("encoded public key has length %d and header %#x, want 897 and 0x09", len(data), data[0])
+	}
+	got, err := ParsePublicKey(data)
+	if err != nil {
+		t.Fatalf("ParsePublicKey returned %v", err)
+	}
+	if !reflect.DeepEqual(got, pub) {
+		t.Errorf("ParsePublicKey(MarshalPublicKey(pub)) != pub")
+	}
+
+	for _, bad := range [][]byte{nil, data[:896], append(data, 0), append([]byte{0x19}, data[1:]...), append([]byte{0x0b}, data[1:]...)} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey accepted a %d-byte encoding with header %#x", len(bad), bad)
+		}
+	}
+	// a coefficient equal to q is rejected
+	bad := bytes.Clone(data)
+	bad[1], bad[2] = 0xC0, 0x04
+	if _, err := ParsePublicKey(bad); err == nil {
+		t.Errorf("ParsePublicKey accepted a coefficient equal to q")
+	}
+}
+
+func TestMarshalPrivateKey(t *testing.T) {
+	data, err := MarshalPrivateKey(firstPrivKey512)
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey returned %v", err)
+	}
+	if len(data) != 1281 || data[0] != 0x59 {
+		t.Errorf("encoded private key has length %d and header %#x, want 1281 and 0x59", len(data), data[0])
+	}
+	got, err := ParsePrivateKey(data)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey returned %v", err)
+	}
+	if !reflect.DeepEqual(got, firstPrivKey512) {
+		t.Errorf("ParsePrivateKey(MarshalPrivateKey(priv)) != priv")
+	}
+	if _, err := ParsePrivateKey(data[:1280]); err == nil {
+		t.Errorf("ParsePrivateKey accepted a truncated encoding")
+	}
+}
+
+func TestMarshalPrivateKeyDegrees(t *testing.T) {
+	for _, n := range []uint16{2, 16, 128} {
+		priv, pub, err := NewKeyPair(n)
+		if err != nil {
+			t.Fatalf("NewKeyPair(%d) returned %v", n, err)
+		}
+		data, err := MarshalPrivateKey(priv)
+		if err != nil {
+			t.Fatalf("n = %d: MarshalPrivateKey returned %v", n, err)
+		}
+		got, err := ParsePrivateKey(data)
+		if err != nil {
+			t.Fatalf("n = %d: ParsePrivateKey returned %v", n, err)
+		}
+		if !reflect.DeepEqual(got, priv) {
+			t.Errorf("n = %d: private key does not round-trip", n)
+		}
+		pubData, err := MarshalPublicKey(pub)
+		if err != nil {
+			t.Fatalf("n = %d: MarshalPublicKey returned %v", n, err)
+		}
+		if len(pubData) != publicKeyLen(n) {
+			t.Errorf("n = %d: public key length = %d, want %d", n, len(pubData), publicKeyLen(n))
+		}
+	}
+}
+
+func TestPolynomials(t *testing.T) {
+	privKey, err := GeneratePrivateKeyFrom(64, deterministicReader("polynomials"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := GeneratePrivateKeyFrom(64, deterministicReader("polynomials"))
+	if err != nil || !again.Equal(privKey) {
+		t.Fatalf("GeneratePrivateKeyFrom is not deterministic: %v", err)
+	}
+	again, _, err = NewKeyPairFromPrivateKey(64, privKey.Polynomials())
+	if err != nil || !again.Equal(privKey) {
+		t.Errorf("NewKeyPairFromPrivateKey(Polynomials()) returned %v", err)
+	}
+
+	pubKey := privKey.GetPublicKey()
+	decoded, err := PublicKeyFromPolynomial(pubKey.Polynomial())
+	if err != nil || !decoded.Equal(pubKey) {
+		t.Errorf("PublicKeyFromPolynomial(Polynomial()) returned %v", err)
+	}
+	h := pubKey.Polynomial()
+	h[0] = util.Q
+	if _, err := PublicKeyFromPolynomial(h); err != ErrInvalidKeyEncoding {
+		t.Errorf("PublicKeyFromPolynomial with a coefficient of q returned %v", err)
+	}
+	if _, err := PublicKeyFromPolynomial(h[:63]); err != ErrInvalidDegree {
+		t.Errorf("PublicKeyFromPolynomial of 63 coefficients returned %v", err)
+	}
+}
+
+func TestPublicKeyNTT(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	hNTT := pub.NTT()
+	for i, c := range hNTT {
+		if c < 0 || int(c) >= util.Q {
+			t.Fatalf("hNTT[%d] = %d is not in [0, q)", i, c)
+		}
+	}
+	if !equalInt16(ntt.INTT(hNTT), pub.h) {
+		t.Error("INTT(NTT(h)) != h")
+	}
+	// Products are pointwise in the NTT domain
+	f := make([]int16, 512)
+	f[1] = 1
+	product, err := ntt.MulZq(f, pub.h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh := ntt.NTT(product)
+	fNTT := ntt.NTT(f)
+	for i := range fh {
+		if int(fh[i]) != int(fNTT[i])*int(hNTT[i])%util.Q {
+			t.Fatalf("NTT(f*h)[%d] = %d, want NTT(f)[%d]*NTT(h)[%d]", i, fh[i], i, i)
+		}
+	}
+}
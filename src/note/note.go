@@ -0,0 +1,475 @@
+// Package note signs and verifies notes in the signed-note format of the Go
+// checksum database and of transparency log checkpoints, with Falcon keys.
+// Its API follows golang.org/x/mod/sumdb/note.
+//
+// A signed note is the UTF-8 text, ending in a newline, a blank line, and
+// one line per signature:
+//
+//	— <name> base64(<key hash> || <signature>)
+//
+// where the key hash is the first 4 bytes, big endian, of
+// SHA-256(<name> || "\n" || <algorithm> || <public key>). The signature is
+// a pure FN-DSA signature of the text in the padded format. Verifier keys
+// are encoded as "<name>+<hash>+base64(<algorithm> || <public key>)" and
+// signer keys as "PRIVATE+KEY+<name>+<hash>+base64(<algorithm> || <private
+// key>)", with the hash as 8 hexadecimal digits and the keys in the
+// encodings of falcon.MarshalPublicKey and falcon.MarshalPrivateKey.
+//
+// The algorithm bytes 0xfa (Falcon-512) and 0xfb (Falcon-1024) are not
+// allocated in the C2SP signed-note registry and are provisional.
+package note
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+const (
+	// AlgFalcon512 identifies Falcon-512 keys.
+	AlgFalcon512 byte = 0xfa
+	// AlgFalcon1024 identifies Falcon-1024 keys.
+	AlgFalcon1024 byte = 0xfb
+)
+
+// maxSignatures bounds the number of signatures of a note.
+const maxSignatures = 100
+
+var (
+	errMalformedNote      = errors.New("malformed note")
+	errInvalidSigner      = errors.New("invalid signer")
+	errInvalidVerifierKey = errors.New("invalid verifier key")
+	errInvalidSignerKey   = errors.New("invalid signer key")
+)
+
+// A Verifier verifies messages signed with a specific key.
+type Verifier interface {
+	// Name returns the server name associated with the key.
+	Name() string
+	// KeyHash returns the key hash.
+	KeyHash() uint32
+	// Verify reports whether sig is a valid signature of msg.
+	Verify(msg, sig []byte) bool
+}
+
+// A Signer signs messages using a specific key.
+type Signer interface {
+	// Name returns the server name associated with the key.
+	Name() string
+	// KeyHash returns the key hash.
+	KeyHash() uint32
+	// Sign returns a signature of msg.
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Verifiers finds the verifiers of signatures.
+type Verifiers interface {
+	// Verifier returns the Verifier of the key with the given name and hash,
+	// or an *UnknownVerifierError if the key is not known.
+	Verifier(name string, hash uint32) (Verifier, error)
+}
+
+// algorithm returns the algorithm byte of keys of degree n.
+func algorithm(n uint16) (byte, error) {
+	switch n {
+	case 512:
+		return AlgFalcon512, nil
+	case 1024:
+		return AlgFalcon1024, nil
+	}
+	return 0, falcon.ErrInvalidDegree
+}
+
+// keyHash computes the key hash of a key with the given name and encoding
+// (algorithm byte followed by the public key).
+func keyHash(name string, key []byte) uint32 {
+	h := sha256.New()
+	h.Write([]byte(name))
+	h.Write([]byte("\n"))
+	h.Write(key)
+	return binary.BigEndian.Uint32(h.Sum(nil))
+}
+
+// isValidName reports whether name is a valid key name: non-empty, without
+// spaces, control characters or plus signs.
+func isValidName(name string) bool {
+	return name != "" && utf8.ValidString(name) && strings.IndexFunc(name, unicode.IsSpace) < 0 && !strings.Contains(name, "+")
+}
+
+// encodedPublicKey returns the algorithm byte followed by the public key.
+func encodedPublicKey(pubKey *falcon.PublicKey) ([]byte, error) {
+	alg, err := algorithm(pubKey.Degree())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := falcon.MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{alg}, raw...), nil
+}
+
+// NewVerifierKey returns the encoded verifier key of pubKey under the given name.
+func NewVerifierKey(name string, pubKey *falcon.PublicKey) (string, error) {
+	if !isValidName(name) {
+		return "", errInvalidVerifierKey
+	}
+	key, err := encodedPublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s+%08x+%s", name, keyHash(name, key), base64.StdEncoding.EncodeToString(key)), nil
+}
+
+// NewSignerKey returns the encoded signer key of privKey under the given name.
+func NewSignerKey(name string, privKey *falcon.PrivateKey) (string, error) {
+	if !isValidName(name) {
+		return "", errInvalidSignerKey
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return "", err
+	}
+	key, err := encodedPublicKey(pubKey)
+	if err != nil {
+		return "", err
+	}
+	raw, err := falcon.MarshalPrivateKey(privKey)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(append([]byte{key[0]}, raw...))
+	return fmt.Sprintf("PRIVATE+KEY+%s+%08x+%s", name, keyHash(name, key), encoded), nil
+}
+
+// GenerateKey generates a key pair of degree n and returns the encoded
+// signer and verifier keys. The randomness of key generation is read from
+// random, or from crypto/rand if random is nil.
+func GenerateKey(random io.Reader, name string, n uint16) (skey, vkey string, err error) {
+	if !isValidName(name) {
+		return "", "", errInvalidSignerKey
+	}
+	var privKey *falcon.PrivateKey
+	if random == nil {
+		privKey, err = falcon.GeneratePrivateKey(n)
+	} else {
+		privKey, err = falcon.GeneratePrivateKeyFrom(n, random)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if skey, err = NewSignerKey(name, privKey); err != nil {
+		return "", "", err
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return "", "", err
+	}
+	if vkey, err = NewVerifierKey(name, pubKey); err != nil {
+		return "", "", err
+	}
+	return skey, vkey, nil
+}
+
+// splitKey splits an encoded key into its name, hash and decoded key.
+func splitKey(encoded string) (name string, hash uint32, key []byte, ok bool) {
+	name, rest, ok1 := strings.Cut(encoded, "+")
+	hashHex, key64, ok2 := strings.Cut(rest, "+")
+	if !ok1 || !ok2 || !isValidName(name) || len(hashHex) != 8 {
+		return "", 0, nil, false
+	}
+	h, err := strconv.ParseUint(hashHex, 16, 32)
+	if err != nil {
+		return "", 0, nil, false
+	}
+	key, err = base64.StdEncoding.DecodeString(key64)
+	if err != nil || len(key) == 0 {
+		return "", 0, nil, false
+	}
+	return name, uint32(h), key, true
+}
+
+type verifier struct {
+	name   string
+	hash   uint32
+	pubKey *falcon.PublicKey
+}
+
+func (v *verifier) Name() string    { return v.name }
+func (v *verifier) KeyHash() uint32 { return v.hash }
+
+func (v *verifier) Verify(msg, sig []byte) bool {
+	return v.pubKey.VerifyFNDSA(msg, sig, nil) == nil
+}
+
+// NewVerifier returns the Verifier of an encoded verifier key.
+func NewVerifier(vkey string) (Verifier, error) {
+	name, hash, key, ok := splitKey(vkey)
+	if !ok {
+		return nil, errInvalidVerifierKey
+	}
+	pubKey, err := falcon.ParsePublicKey(key[1:])
+	if err != nil {
+		return nil, errInvalidVerifierKey
+	}
+	if alg, _ := algorithm(pubKey.Degree()); alg != key[0] || keyHash(name, key) != hash {
+		return nil, errInvalidVerifierKey
+	}
+	return &verifier{name: name, hash: hash, pubKey: pubKey}, nil
+}
+
+type signer struct {
+	name string
+	hash uint32
+	key  *falcon.ExpandedKey
+}
+
+func (s *signer) Name() string    { return s.name }
+func (s *signer) KeyHash() uint32 { return s.hash }
+
+func (s *signer) Sign(msg []byte) ([]byte, error) {
+	return s.key.Sign(nil, msg, &falcon.Options{Format: falcon.FormatPadded})
+}
+
+// NewSigner returns the Signer of an encoded signer key. The key is
+// expanded once for all its signatures.
+func NewSigner(skey string) (Signer, error) {
+	const prefix = "PRIVATE+KEY+"
+	if !strings.HasPrefix(skey, prefix) {
+		return nil, errInvalidSignerKey
+	}
+	name, hash, key, ok := splitKey(skey[len(prefix):])
+	if !ok {
+		return nil, errInvalidSignerKey
+	}
+	privKey, err := falcon.ParsePrivateKey(key[1:])
+	if err != nil {
+		return nil, errInvalidSignerKey
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, errInvalidSignerKey
+	}
+	pub, err := encodedPublicKey(pubKey)
+	if err != nil || pub[0] != key[0] || keyHash(name, pub) != hash {
+		return nil, errInvalidSignerKey
+	}
+	expanded, err := privKey.Expand()
+	if err != nil {
+		return nil, err
+	}
+	return &signer{name: name, hash: hash, key: expanded}, nil
+}
+
+// VerifierList returns Verifiers looking up the given verifiers.
+func VerifierList(list ...Verifier) Verifiers {
+	m := make(verifierMap)
+	for _, v := range list {
+		k := nameHash{v.Name(), v.KeyHash()}
+		m[k] = append(m[k], v)
+	}
+	return m
+}
+
+type nameHash struct {
+	name string
+	hash uint32
+}
+
+type verifierMap map[nameHash][]Verifier
+
+func (m verifierMap) Verifier(name string, hash uint32) (Verifier, error) {
+	v, ok := m[nameHash{name, hash}]
+	if !ok {
+		return nil, &UnknownVerifierError{name, hash}
+	}
+	if len(v) > 1 {
+		return nil, &ambiguousVerifierError{name, hash}
+	}
+	return v[0], nil
+}
+
+// A Note is a text and its signatures.
+type Note struct {
+	Text           string      // text of note
+	Sigs           []Signature // verified signatures
+	UnverifiedSigs []Signature // unverified signatures
+}
+
+// A Signature is a single signature found in a note.
+type Signature struct {
+	// Name and Hash give the name and key hash of the key that made the signature.
+	Name string
+	Hash uint32
+
+	// Base64 records the base64 encoding of the key hash and the signature.
+	Base64 string
+}
+
+// An UnverifiedNoteError indicates that a note has no signature by a
+// known verifier.
+type UnverifiedNoteError struct {
+	Note *Note
+}
+
+func (e *UnverifiedNoteError) Error() string {
+	return "note has no verifiable signatures"
+}
+
+// An UnknownVerifierError indicates that a Verifier is not known for the key.
+type UnknownVerifierError struct {
+	Name    string
+	KeyHash uint32
+}
+
+func (e *UnknownVerifierError) Error() string {
+	return fmt.Sprintf("unknown key %s+%08x", e.Name, e.KeyHash)
+}
+
+type ambiguousVerifierError struct {
+	name string
+	hash uint32
+}
+
+func (e *ambiguousVerifierError) Error() string {
+	return fmt.Sprintf("ambiguous key %s+%08x", e.name, e.hash)
+}
+
+// An InvalidSignatureError indicates that a signature of a known key does not verify.
+type InvalidSignatureError struct {
+	Name string
+	Hash uint32
+}
+
+func (e *InvalidSignatureError) Error() string {
+	return fmt.Sprintf("invalid signature for key %s+%08x", e.Name, e.Hash)
+}
+
+var sigPrefix = []byte("— ")
+
+// isValidText reports whether text is a valid note text: UTF-8, ending in
+// a newline, without other control characters than newlines.
+func isValidText(text string) bool {
+	if !utf8.ValidString(text) || !strings.HasSuffix(text, "\n") {
+		return false
+	}
+	for _, r := range text {
+		if r < 0x20 && r != '\n' {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign signs the note with the given signers and returns the encoded
+// signed note, keeping the signatures of n.Sigs whose keys are not among
+// the signers.
+func Sign(n *Note, signers ...Signer) ([]byte, error) {
+	if !isValidText(n.Text) {
+		return nil, errMalformedNote
+	}
+	var buf bytes.Buffer
+	buf.WriteString(n.Text)
+	buf.WriteString("\n")
+
+	have := make(map[nameHash]bool)
+	for _, s := range signers {
+		name, hash := s.Name(), s.KeyHash()
+		have[nameHash{name, hash}] = true
+		if !isValidName(name) {
+			return nil, errInvalidSigner
+		}
+		sig, err := s.Sign([]byte(n.Text))
+		if err != nil {
+			return nil, err
+		}
+		var hbuf [4]byte
+		binary.BigEndian.PutUint32(hbuf[:], hash)
+		fmt.Fprintf(&buf, "%s%s %s\n", sigPrefix, name, base64.StdEncoding.EncodeToString(append(hbuf[:], sig...)))
+	}
+	for _, sig := range n.Sigs {
+		if !have[nameHash{sig.Name, sig.Hash}] {
+			fmt.Fprintf(&buf, "%s%s %s\n", sigPrefix, sig.Name, sig.Base64)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Open decodes a signed note and verifies its signatures with the known
+// verifiers. Signatures of unknown keys are returned in UnverifiedSigs; a
+// signature of a known key that does not verify fails with an
+// *InvalidSignatureError, and a note without any verified signature fails
+// with an *UnverifiedNoteError.
+func Open(msg []byte, known Verifiers) (*Note, error) {
+	if known == nil {
+		known = VerifierList()
+	}
+	// The text ends at the last blank line
+	split := bytes.LastIndex(msg, []byte("\n\n"))
+	if split < 0 {
+		return nil, errMalformedNote
+	}
+	text, sigs := msg[:split+1], msg[split+2:]
+	if !isValidText(string(text)) || len(sigs) == 0 || sigs[len(sigs)-1] != '\n' {
+		return nil, errMalformedNote
+	}
+
+	n := &Note{Text: string(text)}
+	seen := make(map[nameHash]bool)
+	seenUnverified := make(map[string]bool)
+	numSig := 0
+	for len(sigs) > 0 {
+		i := bytes.IndexByte(sigs, '\n')
+		line := sigs[:i]
+		sigs = sigs[i+1:]
+		if !bytes.HasPrefix(line, sigPrefix) {
+			return nil, errMalformedNote
+		}
+		name, b64, ok := strings.Cut(string(line[len(sigPrefix):]), " ")
+		sig, err := base64.StdEncoding.DecodeString(b64)
+		if !ok || err != nil || !isValidName(name) || len(sig) < 5 {
+			return nil, errMalformedNote
+		}
+		hash := binary.BigEndian.Uint32(sig[:4])
+		sig = sig[4:]
+
+		if numSig++; numSig > maxSignatures {
+			return nil, errMalformedNote
+		}
+		v, err := known.Verifier(name, hash)
+		if _, ok := err.(*UnknownVerifierError); ok {
+			// Keep only the first of identical unverified signatures
+			if !seenUnverified[string(line)] {
+				seenUnverified[string(line)] = true
+				n.UnverifiedSigs = append(n.UnverifiedSigs, Signature{Name: name, Hash: hash, Base64: b64})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Keep only the first signature of a key
+		if seen[nameHash{name, hash}] {
+			continue
+		}
+		seen[nameHash{name, hash}] = true
+		if !v.Verify(text, sig) {
+			return nil, &InvalidSignatureError{name, hash}
+		}
+		n.Sigs = append(n.Sigs, Signature{Name: name, Hash: hash, Base64: b64})
+	}
+	if len(n.Sigs) == 0 {
+		return nil, &UnverifiedNoteError{n}
+	}
+	return n, nil
+}
@@ -0,0 +1,178 @@
+package note
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+const checkpoint = "example.com/log\n42\nqINS1GRFhWHwdkUeqLEoP4yEMkTBBzxBkGwGQlVlVcs=\n"
+
+func testKeys(t *testing.T, name string) (skey, vkey string) {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/falcon512_priv.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skey, err = NewSignerKey(name, privKey); err != nil {
+		t.Fatal(err)
+	}
+	if vkey, err = NewVerifierKey(name, privKey.GetPublicKey()); err != nil {
+		t.Fatal(err)
+	}
+	return skey, vkey
+}
+
+func TestKeys(t *testing.T) {
+	skey, vkey := testKeys(t, "example.com/log")
+	if !strings.HasPrefix(skey, "PRIVATE+KEY+example.com/log+") || !strings.HasPrefix(vkey, "example.com/log+") {
+		t.Fatalf("keys %.40s %.40s", skey, vkey)
+	}
+	s, err := NewSigner(skey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewVerifier(vkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name() != v.Name() || s.KeyHash() != v.KeyHash() {
+		t.Errorf("signer %s+%08x, verifier %s+%08x", s.Name(), s.KeyHash(), v.Name(), v.KeyHash())
+	}
+
+	// The key hash covers the name and the encoded public key
+	parts := strings.SplitN(vkey, "+", 3)
+	key, _ := base64.StdEncoding.DecodeString(parts[2])
+	if key[0] != AlgFalcon512 || len(key) != 898 {
+		t.Errorf("verifier key of %d bytes with algorithm %#x", len(key), key[0])
+	}
+	sum := sha256.Sum256(append([]byte("example.com/log\n"), key...))
+	if h := binary.BigEndian.Uint32(sum[:]); h != v.KeyHash() {
+		t.Errorf("key hash %08x, want %08x", v.KeyHash(), h)
+	}
+
+	for _, bad := range []string{
+		"",
+		"example.com/log+" + parts[1] + "+" + "AAAA",
+		"other.example/log+" + parts[1] + "+" + parts[2],
+		"example.com/log+00000000+" + parts[2],
+		"example.com/log+" + parts[1],
+		"PRIVATE+KEY+" + vkey,
+	} {
+		if _, err := NewVerifier(bad); err == nil {
+			t.Errorf("NewVerifier(%.40q) succeeded", bad)
+		}
+		if _, err := NewSigner(bad); err == nil {
+			t.Errorf("NewSigner(%.40q) succeeded", bad)
+		}
+	}
+	if _, err := NewSigner(vkey); err == nil {
+		t.Error("NewSigner of a verifier key succeeded")
+	}
+	for _, name := range []string{"", "a b", "a+b"} {
+		if _, _, err := GenerateKey(nil, name, 512); err == nil {
+			t.Errorf("GenerateKey with name %q succeeded", name)
+		}
+	}
+}
+
+func TestSignOpen(t *testing.T) {
+	skey, vkey := testKeys(t, "example.com/log")
+	s, _ := NewSigner(skey)
+	v, _ := NewVerifier(vkey)
+	skey2, vkey2, err := GenerateKey(nil, "witness.example", 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, _ := NewSigner(skey2)
+	v2, _ := NewVerifier(vkey2)
+
+	msg, err := Sign(&Note{Text: checkpoint}, s, s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(msg), checkpoint+"\n— example.com/log ") {
+		t.Fatalf("signed note %.120q", msg)
+	}
+
+	n, err := Open(msg, VerifierList(v, v2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Text != checkpoint || len(n.Sigs) != 2 || len(n.UnverifiedSigs) != 0 {
+		t.Errorf("note %+v", n)
+	}
+
+	// The witness signature is kept, unverified, by a verifier of the log key only
+	n, err = Open(msg, VerifierList(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Sigs) != 1 || len(n.UnverifiedSigs) != 1 || n.UnverifiedSigs[0].Name != "witness.example" {
+		t.Errorf("note %+v", n)
+	}
+
+	// Signing again replaces the signature of the same key and keeps the others
+	resigned, err := Sign(n, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(resigned, VerifierList(v)); err != nil {
+		t.Error(err)
+	}
+
+	var unverified *UnverifiedNoteError
+	if _, err := Open(msg, VerifierList()); !errors.As(err, &unverified) || len(unverified.Note.UnverifiedSigs) != 2 {
+		t.Errorf("Open without verifiers returned %v", err)
+	}
+
+	tampered := strings.Replace(string(msg), "42", "43", 1)
+	var invalid *InvalidSignatureError
+	if _, err := Open([]byte(tampered), VerifierList(v)); !errors.As(err, &invalid) || invalid.Name != "example.com/log" {
+		t.Errorf("Open of a modified note returned %v", err)
+	}
+
+	if _, err := Open(msg, VerifierList(v, v)); err == nil {
+		t.Error("Open with an ambiguous verifier succeeded")
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	skey, vkey := testKeys(t, "example.com/log")
+	s, _ := NewSigner(skey)
+	v, _ := NewVerifier(vkey)
+	for _, text := range []string{"no newline", "control\x01\n", "\xff\n"} {
+		if _, err := Sign(&Note{Text: text}, s); err == nil {
+			t.Errorf("Sign(%q) succeeded", text)
+		}
+	}
+
+	msg, err := Sign(&Note{Text: checkpoint}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.TrimPrefix(string(msg), checkpoint+"\n")
+	for _, bad := range []string{
+		checkpoint,
+		checkpoint + "\n",
+		checkpoint + "\n" + strings.TrimSuffix(line, "\n"),
+		checkpoint + "\n" + strings.TrimPrefix(line, "— "),
+		checkpoint + "\n— example.com/log AAAA\n",
+		checkpoint + "\n— example.com/log !!!!\n",
+		checkpoint + "\n" + strings.Repeat("— other.example AAAAAAAA\n", maxSignatures+1),
+	} {
+		if _, err := Open([]byte(bad), VerifierList(v)); err != errMalformedNote {
+			t.Errorf("Open(%.120q) returned %v, want %v", bad, err, errMalformedNote)
+		}
+	}
+}
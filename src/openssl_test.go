@@ -0,0 +1,235 @@
+package falcon
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testdata/pki/*.txt hold the output of openssl x509, req and crl -text
+// (OpenSSL 3.0.17, without oqs-provider) for the certificates, request and
+// revocation list of testdata/pki, which were created by this package. The
+// tests below parse the PEM files and check that every field printed by
+// OpenSSL matches, including the signature value.
+
+var opensslAttributeNames = map[string]string{
+	"2.5.4.3":  "CN",
+	"2.5.4.6":  "C",
+	"2.5.4.10": "O",
+	"2.5.4.11": "OU",
+}
+
+// opensslName formats a name as OpenSSL prints it, in encoding order.
+func opensslName(name pkix.Name) string {
+	var parts []string
+	for _, atv := range name.Names {
+		parts = append(parts, fmt.Sprintf("%s = %v", opensslAttributeNames[atv.Type.String()], atv.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func opensslTime(t time.Time) string {
+	return t.UTC().Format("Jan _2 15:04:05 2006 GMT")
+}
+
+// opensslKeyID formats a key identifier as colon-separated uppercase hex.
+func opensslKeyID(id []byte) string {
+	var parts []string
+	for _, b := range id {
+		parts = append(parts, fmt.Sprintf("%02X", b))
+	}
+	return strings.Join(parts, ":")
+}
+
+func opensslKeyUsage(usage x509.KeyUsage) string {
+	var names []string
+	for _, u := range []struct {
+		bit  x509.KeyUsage
+		name string
+	}{
+		{x509.KeyUsageDigitalSignature, "Digital Signature"},
+		{x509.KeyUsageCertSign, "Certificate Sign"},
+		{x509.KeyUsageCRLSign, "CRL Sign"},
+	} {
+		if usage&u.bit != 0 {
+			names = append(names, u.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func opensslSAN(dnsNames, emails []string, ips []string) string {
+	var names []string
+	for _, name := range dnsNames {
+		names = append(names, "DNS:"+name)
+	}
+	for _, email := range emails {
+		names = append(names, "email:"+email)
+	}
+	for _, ip := range ips {
+		names = append(names, "IP Address:"+ip)
+	}
+	return strings.Join(names, ", ")
+}
+
+// opensslText splits the output of openssl -text into trimmed lines, and
+// returns them with the signature value decoded from its hex dump.
+func opensslText(t *testing.T, name string) (lines []string, signature []byte) {
+	t.Helper()
+	inSignature := false
+	for _, line := range strings.Split(string(mustReadFile(t, "testdata/pki/"+name+".txt")), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "Signature Value:" {
+			inSignature = true
+			continue
+		}
+		if inSignature {
+			b, err := hex.DecodeString(strings.ReplaceAll(line, ":", ""))
+			if err != nil {
+				t.Fatalf("%s: signature dump: %v", name, err)
+			}
+			signature = append(signature, b...)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, signature
+}
+
+// checkOpenSSLText checks that the text of name holds each line of want, in order.
+func checkOpenSSLText(t *testing.T, name string, lines []string, want []string) {
+	t.Helper()
+	i := 0
+	for _, line := range lines {
+		if i < len(want) && line == want[i] {
+			i++
+		}
+	}
+	if i < len(want) {
+		t.Errorf("%s: openssl printed no line %q after %q", name, want[i], want[:i])
+	}
+}
+
+func TestOpenSSLTextCertificates(t *testing.T) {
+	for _, name := range []string{"root", "intermediate", "leaf", "server"} {
+		cert := loadCertificate(t, name)
+		lines, signature := opensslText(t, name)
+		if string(signature) != string(cert.Signature) {
+			t.Errorf("%s: the signature printed by openssl differs", name)
+		}
+		want := []string{
+			fmt.Sprintf("Version: %d (%#x)", cert.Version, cert.Version-1),
+			fmt.Sprintf("Serial Number: %d (%#x)", cert.SerialNumber, cert.SerialNumber),
+			"Signature Algorithm: " + cert.SignatureAlgorithm.String(),
+			"Issuer: " + opensslName(cert.Issuer),
+			"Not Before: " + opensslTime(cert.NotBefore),
+			"Not After : " + opensslTime(cert.NotAfter),
+			"Subject: " + opensslName(cert.Subject),
+			"Public Key Algorithm: " + cert.PublicKeyAlgorithm.String(),
+		}
+		if cert.BasicConstraintsValid {
+			constraints := "CA:FALSE"
+			if cert.IsCA {
+				constraints = fmt.Sprintf("CA:TRUE, pathlen:%d", cert.MaxPathLen)
+			}
+			want = append(want, "X509v3 Basic Constraints: critical", constraints)
+		}
+		var ips []string
+		for _, ip := range cert.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		if san := opensslSAN(cert.DNSNames, cert.EmailAddresses, ips); san != "" {
+			want = append(want, "X509v3 Subject Alternative Name:", san)
+		}
+		want = append(want, "X509v3 Key Usage: critical", opensslKeyUsage(cert.KeyUsage))
+		for _, eku := range cert.ExtKeyUsage {
+			if !eku.Equal(OIDExtKeyUsageServerAuth) {
+				t.Fatalf("%s: no OpenSSL name for the extended key usage %v", name, eku)
+			}
+			want = append(want, "X509v3 Extended Key Usage:", "TLS Web Server Authentication")
+		}
+		want = append(want, "X509v3 Subject Key Identifier:", opensslKeyID(cert.SubjectKeyId))
+		if len(cert.AuthorityKeyId) > 0 {
+			want = append(want, "X509v3 Authority Key Identifier:", opensslKeyID(cert.AuthorityKeyId))
+		}
+		checkOpenSSLText(t, name, lines, append(want, "Signature Algorithm: "+cert.SignatureAlgorithm.String()))
+	}
+}
+
+func TestOpenSSLTextRequest(t *testing.T) {
+	csr, err := ParseCertificateRequestPEM(mustReadFile(t, "testdata/pki/request.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		t.Errorf("CheckSignature returned %v", err)
+	}
+	lines, signature := opensslText(t, "request")
+	if string(signature) != string(csr.Signature) {
+		t.Errorf("request: the signature printed by openssl differs")
+	}
+	var ips []string
+	for _, ip := range csr.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	checkOpenSSLText(t, "request", lines, []string{
+		fmt.Sprintf("Version: %d (%#x)", csr.Version+1, csr.Version),
+		"Subject: " + opensslName(csr.Subject),
+		"Public Key Algorithm: " + csr.PublicKeyAlgorithm.String(),
+		"Requested Extensions:",
+		"X509v3 Subject Alternative Name:",
+		opensslSAN(csr.DNSNames, csr.EmailAddresses, ips),
+		"Signature Algorithm: " + csr.SignatureAlgorithm.String(),
+	})
+}
+
+func TestOpenSSLTextRevocationList(t *testing.T) {
+	der, err := decodePEM(mustReadFile(t, "testdata/pki/crl.pem"), pemTypeCRL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl, err := ParseRevocationList(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, intermediate, _ := loadPKI(t)
+	if err := rl.CheckSignatureFrom(intermediate); err != nil {
+		t.Errorf("CheckSignatureFrom returned %v", err)
+	}
+	lines, signature := opensslText(t, "crl")
+	if string(signature) != string(rl.Signature) {
+		t.Errorf("crl: the signature printed by openssl differs")
+	}
+	want := []string{
+		"Version 2 (0x1)",
+		"Signature Algorithm: " + rl.SignatureAlgorithm.String(),
+		"Issuer: " + opensslName(rl.Issuer),
+		"Last Update: " + opensslTime(rl.ThisUpdate),
+		"Next Update: " + opensslTime(rl.NextUpdate),
+		"X509v3 Authority Key Identifier:",
+		opensslKeyID(rl.AuthorityKeyId),
+		"X509v3 CRL Number:",
+		rl.Number.String(),
+		"Revoked Certificates:",
+	}
+	if len(rl.RevokedCertificates) != 2 {
+		t.Fatalf("crl: %d revoked certificates, want 2", len(rl.RevokedCertificates))
+	}
+	for _, revoked := range rl.RevokedCertificates {
+		want = append(want,
+			"Serial Number: "+opensslSerial(revoked.SerialNumber),
+			"Revocation Date: "+opensslTime(revoked.RevocationTime))
+	}
+	checkOpenSSLText(t, "crl", lines, append(want, "Signature Algorithm: "+rl.SignatureAlgorithm.String()))
+}
+
+// opensslSerial formats a serial number as OpenSSL prints it in a revocation
+// list: uppercase hex of an even length.
+func opensslSerial(serial *big.Int) string {
+	return strings.ToUpper(hex.EncodeToString(serial.Bytes()))
+}
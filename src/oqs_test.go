@@ -0,0 +1,183 @@
+package falcon
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// The tests of this file check interoperability with OpenSSL 3 and
+// oqs-provider. They are skipped unless the openssl command can load
+// oqs-provider (see OPENSSL_MODULES) and knows its Falcon algorithms.
+
+// requireOQS skips the test if openssl cannot load oqs-provider.
+func requireOQS(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("openssl"); err != nil {
+		t.Skip("openssl not found")
+	}
+	out, err := exec.Command("openssl", "list", "-signature-algorithms", "-provider", "oqsprovider").CombinedOutput()
+	if err != nil || !bytes.Contains(out, []byte("falcon512")) || !bytes.Contains(out, []byte("falcon1024")) {
+		t.Skip("openssl without oqs-provider")
+	}
+}
+
+// openssl runs an openssl command with oqs-provider and the default
+// provider loaded, and returns its output.
+func openssl(t *testing.T, command string, args ...string) []byte {
+	t.Helper()
+	args = append([]string{command, "-provider", "default", "-provider", "oqsprovider"}, args...)
+	out, err := exec.Command("openssl", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("openssl %v: %v\n%s", args, err, out)
+	}
+	return out
+}
+
+func TestOQSProviderKeys(t *testing.T) {
+	requireOQS(t)
+	dir := t.TempDir()
+	for alg, n := range map[string]uint16{"falcon512": 512, "falcon1024": 1024} {
+		privFile := filepath.Join(dir, alg+"_priv.pem")
+		pubFile := filepath.Join(dir, alg+"_pub.pem")
+		openssl(t, "genpkey", "-algorithm", alg, "-out", privFile)
+		openssl(t, "pkey", "-in", privFile, "-pubout", "-out", pubFile)
+		privPEM, pubPEM := mustReadFile(t, privFile), mustReadFile(t, pubFile)
+
+		priv, err := ParsePrivateKeyPEM(privPEM)
+		if err != nil {
+			t.Fatalf("%s: ParsePrivateKeyPEM of the openssl key returned %v", alg, err)
+		}
+		pub, err := ParsePublicKeyPEM(pubPEM)
+		if err != nil {
+			t.Fatalf("%s: ParsePublicKeyPEM of the openssl key returned %v", alg, err)
+		}
+		if priv.Degree() != n || !pub.Equal(priv.GetPublicKey()) {
+			t.Errorf("%s: the openssl public key does not match its private key", alg)
+		}
+		if got, err := MarshalPublicKeyPEM(pub); err != nil || !bytes.Equal(got, pubPEM) {
+			t.Errorf("%s: the openssl public key does not round-trip: %v", alg, err)
+		}
+
+		// openssl reads back the private key encoded by MarshalPrivateKeyPEM
+		encoded, err := MarshalPrivateKeyPEM(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encodedFile := filepath.Join(dir, alg+"_encoded.pem")
+		if err := os.WriteFile(encodedFile, encoded, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if derived := openssl(t, "pkey", "-in", encodedFile, "-pubout"); !bytes.Equal(derived, pubPEM) {
+			t.Errorf("%s: openssl derives another public key from MarshalPrivateKeyPEM", alg)
+		}
+	}
+}
+
+// writeFile writes data to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestOQSProviderCertificates checks that openssl parses and verifies the
+// certificates, requests and revocation lists created by this package, and
+// the reverse.
+func TestOQSProviderCertificates(t *testing.T) {
+	requireOQS(t)
+	dir := t.TempDir()
+	root, intermediate, leaf := loadPKI(t)
+	intermediateKey := loadPKIKey(t, "intermediate")
+	rootFile := writeFile(t, dir, "root.pem", MarshalCertificatePEM(root.Raw))
+	intermediateFile := writeFile(t, dir, "intermediate.pem", MarshalCertificatePEM(intermediate.Raw))
+	attime := strconv.FormatInt(pkiTime.Unix(), 10)
+
+	// A certificate issued by the intermediate CA
+	der, err := CreateCertificate(deterministicReader("oqs"), &Certificate{
+		Subject:        pkix.Name{CommonName: "oqs.example.com"},
+		NotBefore:      pkiTime.Add(-time.Hour),
+		NotAfter:       pkiTime.AddDate(0, 1, 0),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []asn1.ObjectIdentifier{OIDExtKeyUsageServerAuth},
+		DNSNames:       []string{"oqs.example.com"},
+		EmailAddresses: []string{"pki@example.com"},
+	}, intermediate, firstPrivKey512.GetPublicKey(), intermediateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := writeFile(t, dir, "cert.pem", MarshalCertificatePEM(der))
+	if out := openssl(t, "x509", "-in", certFile, "-noout", "-text"); !bytes.Contains(out, []byte("DNS:oqs.example.com")) {
+		t.Errorf("openssl x509 -text does not show the SAN:\n%s", out)
+	}
+	for _, file := range []string{certFile, writeFile(t, dir, "leaf.pem", MarshalCertificatePEM(leaf.Raw))} {
+		openssl(t, "verify", "-attime", attime, "-CAfile", rootFile, "-untrusted", intermediateFile, file)
+	}
+
+	// A certificate request
+	der, err = CreateCertificateRequest(deterministicReader("oqs"), &CertificateRequest{
+		Subject:  pkix.Name{CommonName: "requested.example.com"},
+		DNSNames: []string{"requested.example.com"},
+	}, firstPrivKey512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csrFile := writeFile(t, dir, "csr.pem", MarshalCertificateRequestPEM(der))
+	if out := openssl(t, "req", "-in", csrFile, "-noout", "-verify", "-text"); !bytes.Contains(out, []byte("requested.example.com")) {
+		t.Errorf("openssl req -text does not show the subject:\n%s", out)
+	}
+
+	// Revocation lists, with and without revoked certificates
+	for i, revoked := range [][]pkix.RevokedCertificate{nil, {{SerialNumber: leaf.SerialNumber, RevocationTime: pkiTime}}} {
+		der, err := CreateRevocationList(deterministicReader("oqs"), &RevocationList{
+			Number:              big.NewInt(int64(i + 1)),
+			ThisUpdate:          pkiTime,
+			NextUpdate:          pkiTime.AddDate(0, 0, 7),
+			RevokedCertificates: revoked,
+		}, intermediate, intermediateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		crlFile := writeFile(t, dir, "crl.pem", MarshalRevocationListPEM(der))
+		out := openssl(t, "crl", "-in", crlFile, "-noout", "-text", "-CAfile", intermediateFile)
+		if !bytes.Contains(out, []byte("verify OK")) {
+			t.Errorf("openssl crl does not verify the list of %d revocations:\n%s", len(revoked), out)
+		}
+	}
+
+	// A self-signed certificate and a request made by openssl
+	for _, alg := range []string{"falcon512", "falcon1024"} {
+		keyFile := filepath.Join(dir, alg+"_key.pem")
+		caFile := filepath.Join(dir, alg+"_ca.pem")
+		openssl(t, "req", "-x509", "-new", "-newkey", alg, "-keyout", keyFile, "-nodes",
+			"-subj", "/CN=OQS test CA", "-days", "30", "-out", caFile)
+		certs, err := ParseCertificatesPEM(mustReadFile(t, caFile))
+		if err != nil {
+			t.Fatalf("%s: ParseCertificatesPEM of the openssl certificate returned %v", alg, err)
+		}
+		if err := certs[0].CheckSignatureFrom(certs[0]); err != nil {
+			t.Errorf("%s: the openssl certificate does not verify: %v", alg, err)
+		}
+
+		reqFile := filepath.Join(dir, alg+"_csr.pem")
+		openssl(t, "req", "-new", "-key", keyFile, "-subj", "/CN=oqs.example.com", "-out", reqFile)
+		csr, err := ParseCertificateRequestPEM(mustReadFile(t, reqFile))
+		if err != nil {
+			t.Fatalf("%s: ParseCertificateRequestPEM of the openssl request returned %v", alg, err)
+		}
+		if err := csr.CheckSignature(); err != nil {
+			t.Errorf("%s: the openssl request does not verify: %v", alg, err)
+		}
+	}
+}
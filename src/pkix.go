@@ -0,0 +1,283 @@
+package falcon
+
+import (
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+
+	"golang.org/x/crypto/cryptobyte"
+	cryptobyte_asn1 "golang.org/x/crypto/cryptobyte/asn1"
+)
+
+/*
+PKIX and PKCS#8 containers for Falcon keys.
+
+The algorithm identifiers are those assigned by Open Quantum Safe (used by
+oqs-provider and the IETF drafts), with absent parameters. The subjectPublicKey
+bit string holds the public key encoding of MarshalPublicKey, and the PKCS#8
+privateKey octet string holds the private key encoding of MarshalPrivateKey
+followed by the public key encoding, as written by oqs-provider.
+*/
+
+var (
+	// OIDFalcon512 identifies Falcon-512 keys and signatures.
+	OIDFalcon512 = asn1.ObjectIdentifier{1, 3, 9999, 3, 11}
+	// OIDFalcon1024 identifies Falcon-1024 keys and signatures.
+	OIDFalcon1024 = asn1.ObjectIdentifier{1, 3, 9999, 3, 14}
+)
+
+const (
+	pemTypePublicKey  = "PUBLIC KEY"
+	pemTypePrivateKey = "PRIVATE KEY"
+)
+
+var (
+	// ErrUnsupportedAlgorithm is returned when an algorithm identifier is not a Falcon OID
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm identifier")
+	// ErrInvalidASN1 is returned when a DER structure is malformed
+	ErrInvalidASN1 = errors.New("malformed DER structure")
+	// ErrInvalidPEM is returned when no PEM block of the expected type is found
+	ErrInvalidPEM = errors.New("no PEM block of the expected type")
+)
+
+// OIDFromDegree returns the algorithm identifier of Falcon keys of degree n.
+func OIDFromDegree(n uint16) (asn1.ObjectIdentifier, error) {
+	switch n {
+	case 512:
+		return OIDFalcon512, nil
+	case 1024:
+		return OIDFalcon1024, nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
+
+// DegreeFromOID returns the degree of Falcon keys with the algorithm identifier oid.
+func DegreeFromOID(oid asn1.ObjectIdentifier) (uint16, error) {
+	switch {
+	case oid.Equal(OIDFalcon512):
+		return 512, nil
+	case oid.Equal(OIDFalcon1024):
+		return 1024, nil
+	}
+	return 0, ErrUnsupportedAlgorithm
+}
+
+type algorithmIdentifier struct {
+	Algorithm asn1.ObjectIdentifier
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm algorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type pkcs8 struct {
+	Version    int
+	Algorithm  algorithmIdentifier
+	PrivateKey []byte
+}
+
+// readAlgorithmIdentifier reads an AlgorithmIdentifier and returns the Falcon
+// degree it identifies. Parameters must be absent.
+func readAlgorithmIdentifier(s *cryptobyte.String) (uint16, error) {
+	var algID cryptobyte.String
+	var oid asn1.ObjectIdentifier
+	if !s.ReadASN1(&algID, cryptobyte_asn1.SEQUENCE) || !algID.ReadASN1ObjectIdentifier(&oid) {
+		return 0, ErrInvalidASN1
+	}
+	if !algID.Empty() {
+		return 0, ErrUnsupportedAlgorithm
+	}
+	return DegreeFromOID(oid)
+}
+
+// MarshalPKIXPublicKey encodes a Falcon-512 or Falcon-1024 public key as a
+// DER SubjectPublicKeyInfo.
+func MarshalPKIXPublicKey(pubKey *PublicKey) ([]byte, error) {
+	oid, err := OIDFromDegree(pubKey.n)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(subjectPublicKeyInfo{
+		Algorithm: algorithmIdentifier{oid},
+		PublicKey: asn1.BitString{Bytes: encoded, BitLength: 8 * len(encoded)},
+	})
+}
+
+// ParsePKIXPublicKey decodes a DER SubjectPublicKeyInfo holding a Falcon public key.
+func ParsePKIXPublicKey(der []byte) (*PublicKey, error) {
+	input := cryptobyte.String(der)
+	var spki cryptobyte.String
+	if !input.ReadASN1(&spki, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, ErrInvalidASN1
+	}
+	n, err := readAlgorithmIdentifier(&spki)
+	if err != nil {
+		return nil, err
+	}
+	var bits asn1.BitString
+	if !spki.ReadASN1BitString(&bits) || !spki.Empty() || bits.BitLength%8 != 0 {
+		return nil, ErrInvalidASN1
+	}
+	pubKey, err := ParsePublicKey(bits.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if pubKey.n != n {
+		return nil, ErrInvalidKeyEncoding
+	}
+	return pubKey, nil
+}
+
+// MarshalPKCS8PrivateKey encodes a Falcon-512 or Falcon-1024 private key as a
+// DER PKCS#8 PrivateKeyInfo.
+func MarshalPKCS8PrivateKey(privKey *PrivateKey) ([]byte, error) {
+	oid, err := OIDFromDegree(privKey.n)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := MarshalPrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	encodedPub, err := MarshalPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return asn1.Marshal(pkcs8{
+		Version:    0,
+		Algorithm:  algorithmIdentifier{oid},
+		PrivateKey: append(encoded, encodedPub...),
+	})
+}
+
+// ParsePKCS8PrivateKey decodes a DER PKCS#8 PrivateKeyInfo holding a Falcon
+// private key. The privateKey field may hold the private key encoding alone
+// or followed by the public key, optionally wrapped in an inner OCTET STRING.
+// When the public key is present, it must match the private key.
+func ParsePKCS8PrivateKey(der []byte) (*PrivateKey, error) {
+	input := cryptobyte.String(der)
+	var info cryptobyte.String
+	var version int64
+	var keyBytes []byte
+	if !input.ReadASN1(&info, cryptobyte_asn1.SEQUENCE) || !input.Empty() {
+		return nil, ErrInvalidASN1
+	}
+	if !info.ReadASN1Int64WithTag(&version, cryptobyte_asn1.INTEGER) || (version != 0 && version != 1) {
+		return nil, ErrInvalidASN1
+	}
+	n, err := readAlgorithmIdentifier(&info)
+	if err != nil {
+		return nil, err
+	}
+	if !info.ReadASN1Bytes(&keyBytes, cryptobyte_asn1.OCTET_STRING) {
+		return nil, ErrInvalidASN1
+	}
+	// attributes [0] and publicKey [1] are ignored
+	if !info.SkipOptionalASN1(cryptobyte_asn1.Tag(0).Constructed().ContextSpecific()) ||
+		!info.SkipOptionalASN1(cryptobyte_asn1.Tag(1).ContextSpecific()) || !info.Empty() {
+		return nil, ErrInvalidASN1
+	}
+
+	inner := cryptobyte.String(keyBytes)
+	var unwrapped []byte
+	if inner.ReadASN1Bytes(&unwrapped, cryptobyte_asn1.OCTET_STRING) && inner.Empty() {
+		keyBytes = unwrapped
+	}
+
+	skLen, pkLen := privateKeyLen(n), publicKeyLen(n)
+	if len(keyBytes) != skLen && len(keyBytes) != skLen+pkLen {
+		return nil, ErrInvalidKeyEncoding
+	}
+	privKey, err := ParsePrivateKey(keyBytes[:skLen])
+	if err != nil {
+		return nil, err
+	}
+	if privKey.n != n {
+		return nil, ErrInvalidKeyEncoding
+	}
+	if len(keyBytes) > skLen {
+		pubKey, err := ParsePublicKey(keyBytes[skLen:])
+		if err != nil {
+			return nil, err
+		}
+		derived, err := privKey.DerivePublicKey()
+		if err != nil {
+			return nil, err
+		}
+		if !equalInt16(pubKey.h, derived.h) {
+			return nil, ErrInvalidKeyEncoding
+		}
+	}
+	return privKey, nil
+}
+
+func equalInt16(a, b []int16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalPublicKeyPEM encodes a public key as a "PUBLIC KEY" PEM block.
+func MarshalPublicKeyPEM(pubKey *PublicKey) ([]byte, error) {
+	der, err := MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}), nil
+}
+
+// ParsePublicKeyPEM decodes the first "PUBLIC KEY" PEM block of data.
+func ParsePublicKeyPEM(data []byte) (*PublicKey, error) {
+	der, err := decodePEM(data, pemTypePublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKIXPublicKey(der)
+}
+
+// MarshalPrivateKeyPEM encodes a private key as a "PRIVATE KEY" PEM block.
+func MarshalPrivateKeyPEM(privKey *PrivateKey) ([]byte, error) {
+	der, err := MarshalPKCS8PrivateKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemTypePrivateKey, Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM decodes the first "PRIVATE KEY" PEM block of data.
+func ParsePrivateKeyPEM(data []byte) (*PrivateKey, error) {
+	der, err := decodePEM(data, pemTypePrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePKCS8PrivateKey(der)
+}
+
+// decodePEM returns the content of the first PEM block of the given type.
+func decodePEM(data []byte, blockType string) ([]byte, error) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, ErrInvalidPEM
+		}
+		if block.Type == blockType {
+			return block.Bytes, nil
+		}
+	}
+}
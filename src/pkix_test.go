@@ -0,0 +1,167 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/asn1"
+	"encoding/pem"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// The files in testdata were written by this package, falcon512_*.pem from
+// firstPrivKey512: they only check that encodings round-trip. Keys written
+// by OpenSSL with oqs-provider are checked by TestOQSProviderKeys, and the
+// NIST KAT keys wrapped by OpenSSL by TestPKIXKATKeys.
+
+func TestPKIXPublicKeyTestdata(t *testing.T) {
+	for _, name := range []string{"falcon512", "falcon1024"} {
+		pubPEM, err := os.ReadFile("testdata/" + name + "_pub.pem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		privPEM, err := os.ReadFile("testdata/" + name + "_priv.pem")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pub, err := ParsePublicKeyPEM(pubPEM)
+		if err != nil {
+			t.Fatalf("%s: ParsePublicKeyPEM returned %v", name, err)
+		}
+		priv, err := ParsePrivateKeyPEM(privPEM)
+		if err != nil {
+			t.Fatalf("%s: ParsePrivateKeyPEM returned %v", name, err)
+		}
+		if !reflect.DeepEqual(priv.GetPublicKey(), pub) {
+			t.Errorf("%s: public key does not match the private key", name)
+		}
+
+		gotPub, err := MarshalPublicKeyPEM(pub)
+		if err != nil {
+			t.Fatalf("%s: MarshalPublicKeyPEM returned %v", name, err)
+		}
+		if !bytes.Equal(gotPub, pubPEM) {
+			t.Errorf("%s: public key does not round-trip", name)
+		}
+		gotPriv, err := MarshalPrivateKeyPEM(priv)
+		if err != nil {
+			t.Fatalf("%s: MarshalPrivateKeyPEM returned %v", name, err)
+		}
+		if !bytes.Equal(gotPriv, privPEM) {
+			t.Errorf("%s: private key does not round-trip", name)
+		}
+	}
+
+	pub, _ := ParsePublicKeyPEM(mustReadFile(t, "testdata/falcon512_pub.pem"))
+	if !reflect.DeepEqual(pub, firstPrivKey512.GetPublicKey()) {
+		t.Errorf("falcon512_pub.pem does not hold the public key of firstPrivKey512")
+	}
+}
+
+// TestPKIXKATKeys checks the keys of the first entry of the NIST KAT files,
+// wrapped by openssl in the layouts of oqs-provider (testdata/kat/keys.sh).
+func TestPKIXKATKeys(t *testing.T) {
+	for _, n := range []string{"512", "1024"} {
+		kat := readKAT(t, "testdata/kat/falcon"+n+"-KAT.rsp")[0]
+		pubPEM := mustReadFile(t, "testdata/kat/falcon"+n+"_pub.pem")
+		privPEM := mustReadFile(t, "testdata/kat/falcon"+n+"_priv.pem")
+
+		pub, err := ParsePublicKeyPEM(pubPEM)
+		if err != nil {
+			t.Fatalf("falcon%s: ParsePublicKeyPEM returned %v", n, err)
+		}
+		if enc, _ := MarshalPublicKey(pub); !bytes.Equal(enc, kat.bytes(t, "pk")) {
+			t.Errorf("falcon%s: the public key differs from the KAT", n)
+		}
+		if got, err := MarshalPublicKeyPEM(pub); err != nil || !bytes.Equal(got, pubPEM) {
+			t.Errorf("falcon%s: the public key does not re-encode to the file: %v", n, err)
+		}
+
+		priv, err := ParsePrivateKeyPEM(privPEM)
+		if err != nil {
+			t.Fatalf("falcon%s: ParsePrivateKeyPEM returned %v", n, err)
+		}
+		if enc, _ := MarshalPrivateKey(priv); !bytes.Equal(enc, kat.bytes(t, "sk")) {
+			t.Errorf("falcon%s: the private key differs from the KAT", n)
+		}
+		if got, err := MarshalPrivateKeyPEM(priv); err != nil || !bytes.Equal(got, privPEM) {
+			t.Errorf("falcon%s: the private key does not re-encode to the file: %v", n, err)
+		}
+	}
+}
+
+func TestParsePKCS8PrivateKeyLayouts(t *testing.T) {
+	skOnly, _ := MarshalPrivateKey(firstPrivKey512)
+	pk, _ := MarshalPublicKey(firstPrivKey512.GetPublicKey())
+	wrapped, _ := asn1MarshalOctetString(append(bytes.Clone(skOnly), pk...))
+	for _, keyBytes := range [][]byte{skOnly, append(bytes.Clone(skOnly), pk...), wrapped} {
+		der, err := asn1MarshalPKCS8(OIDFalcon512, keyBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		priv, err := ParsePKCS8PrivateKey(der)
+		if err != nil {
+			t.Errorf("ParsePKCS8PrivateKey returned %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(priv, firstPrivKey512) {
+			t.Errorf("ParsePKCS8PrivateKey returned another key")
+		}
+	}
+
+	// mismatched public key, wrong OID and trailing data are rejected
+	other := bytes.Clone(pk)
+	other[10] ^= 0x01
+	bad := [][]byte{}
+	der, _ := asn1MarshalPKCS8(OIDFalcon512, append(bytes.Clone(skOnly), other...))
+	bad = append(bad, der)
+	der, _ = asn1MarshalPKCS8(OIDFalcon1024, skOnly)
+	bad = append(bad, der)
+	der, _ = MarshalPKCS8PrivateKey(firstPrivKey512)
+	bad = append(bad, append(der, 0))
+	for i, der := range bad {
+		if _, err := ParsePKCS8PrivateKey(der); err == nil {
+			t.Errorf("case %d: ParsePKCS8PrivateKey accepted an invalid key", i)
+		}
+	}
+}
+
+func TestPKIXUnsupportedDegree(t *testing.T) {
+	priv, pub, err := NewKeyPair(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MarshalPKIXPublicKey(pub); err != ErrUnsupportedAlgorithm {
+		t.Errorf("MarshalPKIXPublicKey(n = 64) returned %v, want %v", err, ErrUnsupportedAlgorithm)
+	}
+	if _, err := MarshalPKCS8PrivateKey(priv); err != ErrUnsupportedAlgorithm {
+		t.Errorf("MarshalPKCS8PrivateKey(n = 64) returned %v, want %v", err, ErrUnsupportedAlgorithm)
+	}
+}
+
+func TestParsePEMWrongType(t *testing.T) {
+	privPEM := mustReadFile(t, "testdata/falcon512_priv.pem")
+	if _, err := ParsePublicKeyPEM(privPEM); err != ErrInvalidPEM {
+		t.Errorf("ParsePublicKeyPEM(private key) returned %v, want %v", err, ErrInvalidPEM)
+	}
+	block, _ := pem.Decode(privPEM)
+	if _, err := ParsePKIXPublicKey(block.Bytes); err == nil {
+		t.Errorf("ParsePKIXPublicKey accepted a PKCS#8 structure")
+	}
+}
+
+func mustReadFile(t testing.TB, name string) []byte {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func asn1MarshalOctetString(b []byte) ([]byte, error) {
+	return asn1.Marshal(b)
+}
+
+func asn1MarshalPKCS8(oid []int, keyBytes []byte) ([]byte, error) {
+	return asn1.Marshal(pkcs8{Algorithm: algorithmIdentifier{oid}, PrivateKey: keyBytes})
+}
@@ -0,0 +1,78 @@
+package falcon
+
+import (
+	"errors"
+)
+
+/*
+Falcon-512 verification precompile.
+
+The input layout and the gas cost are this package's own, for EVM backends
+that register it with the RequiredGas/Run methods of go-ethereum precompiles.
+
+The input is the concatenation of three fixed-length fields:
+
+	offset  length  field
+	0       32      message hash, signed as the Falcon message
+	32      666     signature in the padded format (header 0x39, salt, compressed s1)
+	698     897     public key (header 0x09, h over 14 bits per coefficient)
+
+The output is a 32-byte big-endian word, 1 if the signature is valid and 0
+otherwise. An input of the wrong length, or whose signature or public key is
+not a valid encoding, is malformed: Run returns an error, which makes the
+precompile call fail, instead of a 0 word.
+*/
+
+const (
+	// PrecompileHashLen is the bytelength of the message hash field.
+	PrecompileHashLen = 32
+	// PrecompileSignatureLen is the bytelength of the signature field.
+	PrecompileSignatureLen = 666
+	// PrecompilePublicKeyLen is the bytelength of the public key field.
+	PrecompilePublicKeyLen = 897
+	// PrecompileInputLen is the bytelength of a precompile input.
+	PrecompileInputLen = PrecompileHashLen + PrecompileSignatureLen + PrecompilePublicKeyLen
+
+	// VerifyPrecompileGas is the gas charged by a verification, the price of
+	// ECRECOVER.
+	VerifyPrecompileGas uint64 = 3000
+)
+
+var (
+	// ErrInvalidPrecompileInput is returned when a precompile input is malformed
+	ErrInvalidPrecompileInput = errors.New("malformed precompile input")
+)
+
+// VerifyPrecompile is the Falcon-512 verification precompile. It has the
+// methods of the PrecompiledContract interface of go-ethereum.
+type VerifyPrecompile struct{}
+
+// RequiredGas returns the gas charged for input.
+func (VerifyPrecompile) RequiredGas(input []byte) uint64 {
+	return VerifyPrecompileGas
+}
+
+// Run verifies the signature of the input and returns the result word.
+func (VerifyPrecompile) Run(input []byte) ([]byte, error) {
+	if len(input) != PrecompileInputLen {
+		return nil, ErrInvalidPrecompileInput
+	}
+	hash := input[:PrecompileHashLen]
+	signature := input[PrecompileHashLen : PrecompileHashLen+PrecompileSignatureLen]
+	pubKey, err := ParsePublicKey(input[PrecompileHashLen+PrecompileSignatureLen:])
+	if err != nil || pubKey.n != 512 {
+		return nil, ErrInvalidPrecompileInput
+	}
+	if signature[0] != FormatPadded.header(512) {
+		return nil, ErrInvalidPrecompileInput
+	}
+	_, salt, s1, err := decodeSignature(signature, 512)
+	if err != nil {
+		return nil, ErrInvalidPrecompileInput
+	}
+	result := make([]byte, 32)
+	if verifyPoint(512, pubKey.h, hashToPointN(hash, salt, 512), s1) {
+		result[31] = 1
+	}
+	return result, nil
+}
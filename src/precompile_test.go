@@ -0,0 +1,76 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// precompile_valid.hex holds the vector of the former VerifyBytes test, a
+// signature of the firstPrivKey512 key, laid out as a precompile input.
+func TestVerifyPrecompile(t *testing.T) {
+	valid, err := hex.DecodeString(strings.TrimSpace(string(mustReadFile(t, "testdata/precompile_valid.hex"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid) != PrecompileInputLen {
+		t.Fatalf("test vector of %d bytes, want %d", len(valid), PrecompileInputLen)
+	}
+	pubKey, _ := MarshalPublicKey(firstPrivKey512.GetPublicKey())
+	hash := bytes.Repeat([]byte{0xab}, PrecompileHashLen)
+	sig, err := firstPrivKey512.SignFormat(hash, FormatPadded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := append(append(append([]byte(nil), hash...), sig...), pubKey...)
+
+	mutate := func(input []byte, offset int, b byte) []byte {
+		out := append([]byte(nil), input...)
+		out[offset] = b
+		return out
+	}
+	sigOffset := PrecompileHashLen
+	pkOffset := PrecompileHashLen + PrecompileSignatureLen
+
+	testCases := []struct {
+		name   string
+		input  []byte
+		result byte
+		err    error
+	}{
+		{"reference vector", valid, 1, nil},
+		{"fresh signature", fresh, 1, nil},
+		{"other message hash", mutate(valid, 0, valid[0]^1), 0, nil},
+		{"other salt", mutate(valid, sigOffset+1, valid[sigOffset+1]^1), 0, nil},
+		// Clearing bits keeps the coefficients of h below q
+		{"other public key", mutate(valid, pkOffset+100, 0), 0, nil},
+		{"empty input", nil, 0, ErrInvalidPrecompileInput},
+		{"short input", valid[:PrecompileInputLen-1], 0, ErrInvalidPrecompileInput},
+		{"long input", append(append([]byte(nil), valid...), 0), 0, ErrInvalidPrecompileInput},
+		{"CT signature header", mutate(valid, sigOffset, 0x59), 0, ErrInvalidPrecompileInput},
+		{"Falcon-1024 signature header", mutate(valid, sigOffset, 0x3a), 0, ErrInvalidPrecompileInput},
+		{"public key header", mutate(valid, pkOffset, 0x0a), 0, ErrInvalidPrecompileInput},
+		{"public key coefficient out of range", mutate(mutate(valid, pkOffset+1, 0xff), pkOffset+2, 0xff), 0, ErrInvalidPrecompileInput},
+		{"signature encoding", append(append(append([]byte(nil), valid[:sigOffset+41]...), make([]byte, PrecompileSignatureLen-41)...), valid[pkOffset:]...), 0, ErrInvalidPrecompileInput},
+	}
+	var precompile VerifyPrecompile
+	for _, tc := range testCases {
+		if gas := precompile.RequiredGas(tc.input); gas != VerifyPrecompileGas {
+			t.Errorf("%s: RequiredGas = %d, want %d", tc.name, gas, VerifyPrecompileGas)
+		}
+		out, err := precompile.Run(tc.input)
+		if err != tc.err {
+			t.Errorf("%s: Run returned %v, want %v", tc.name, err, tc.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		want := make([]byte, 32)
+		want[31] = tc.result
+		if !bytes.Equal(out, want) {
+			t.Errorf("%s: Run = %x, want %x", tc.name, out, want)
+		}
+	}
+}
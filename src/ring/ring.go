@@ -0,0 +1,288 @@
+// Package ring implements arithmetic in Falcon's polynomial ring
+// Z_q[x]/(x^n + 1), with q = 12289 and n a power of two up to 1024.
+//
+// A Poly carries its degree and its representation, coefficients or NTT,
+// so that the operations can check their operands: they return errors
+// instead of panicking on operands of different degrees or
+// representations. The operations never modify their operands.
+package ring
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+// Q is the integer modulus.
+const Q = util.Q
+
+// MaxDegree is the largest supported degree.
+const MaxDegree = 1024
+
+// Representation is the representation of the values of a Poly.
+type Representation uint8
+
+const (
+	// Coefficients represents a polynomial by its n coefficients.
+	Coefficients Representation = iota
+	// NTT represents a polynomial by its evaluations at the n roots of
+	// x^n + 1, in the order of the NTT of this module.
+	NTT
+)
+
+func (r Representation) String() string {
+	switch r {
+	case Coefficients:
+		return "coefficients"
+	case NTT:
+		return "NTT"
+	}
+	return fmt.Sprintf("Representation(%d)", uint8(r))
+}
+
+var (
+	// ErrInvalidDegree is returned for degrees that are not powers of two between 2 and MaxDegree
+	ErrInvalidDegree = errors.New("invalid degree")
+	// ErrDegreeMismatch is returned when the operands have different degrees
+	ErrDegreeMismatch = errors.New("polynomials of different degrees")
+	// ErrRepresentationMismatch is returned when the operands have different representations
+	ErrRepresentationMismatch = errors.New("polynomials of different representations")
+	// ErrNotInvertible is returned when dividing by a polynomial which is not invertible
+	ErrNotInvertible = errors.New("polynomial is not invertible")
+)
+
+// A Poly is an element of Z_q[x]/(x^n + 1). Its values are kept reduced
+// in [0, q).
+type Poly struct {
+	values []int16
+	repr   Representation
+}
+
+func checkDegree(n int) error {
+	if n < 2 || n > MaxDegree || n&(n-1) != 0 {
+		return ErrInvalidDegree
+	}
+	return nil
+}
+
+// New returns the polynomial with the given coefficients, reduced mod q.
+// Its degree is len(coeffs).
+func New(coeffs []int16) (*Poly, error) {
+	return newPoly(coeffs, Coefficients)
+}
+
+// NewNTT returns the polynomial with the given NTT representation, reduced
+// mod q.
+func NewNTT(values []int16) (*Poly, error) {
+	return newPoly(values, NTT)
+}
+
+func newPoly(values []int16, repr Representation) (*Poly, error) {
+	if err := checkDegree(len(values)); err != nil {
+		return nil, err
+	}
+	p := &Poly{values: make([]int16, len(values)), repr: repr}
+	for i, v := range values {
+		p.values[i] = int16(util.Pmod(int(v), Q))
+	}
+	return p, nil
+}
+
+// Zero returns the zero polynomial of degree n in coefficient representation.
+func Zero(n int) (*Poly, error) {
+	if err := checkDegree(n); err != nil {
+		return nil, err
+	}
+	return &Poly{values: make([]int16, n)}, nil
+}
+
+// Degree returns n, the number of coefficients of p.
+func (p *Poly) Degree() int {
+	return len(p.values)
+}
+
+// Representation returns the representation of p.
+func (p *Poly) Representation() Representation {
+	return p.repr
+}
+
+// Values returns a copy of the values of p, in [0, q), in its representation.
+func (p *Poly) Values() []int16 {
+	return append([]int16(nil), p.values...)
+}
+
+// Centered returns the coefficients of p in (-q/2, q/2].
+func (p *Poly) Centered() []int16 {
+	c := p.ToCoefficients()
+	res := make([]int16, len(c.values))
+	for i, v := range c.values {
+		if v > Q/2 {
+			v -= Q
+		}
+		res[i] = v
+	}
+	return res
+}
+
+// ToNTT returns p in NTT representation.
+func (p *Poly) ToNTT() *Poly {
+	if p.repr == NTT {
+		return p.clone()
+	}
+	return &Poly{values: ntt.NTT(p.values), repr: NTT}
+}
+
+// ToCoefficients returns p in coefficient representation.
+func (p *Poly) ToCoefficients() *Poly {
+	if p.repr == Coefficients {
+		return p.clone()
+	}
+	return &Poly{values: ntt.INTT(p.values), repr: Coefficients}
+}
+
+func (p *Poly) clone() *Poly {
+	return &Poly{values: p.Values(), repr: p.repr}
+}
+
+// Equal reports whether p and x are the same polynomial, in any representation.
+func (p *Poly) Equal(x *Poly) bool {
+	if x == nil || len(p.values) != len(x.values) {
+		return false
+	}
+	if p.repr != x.repr {
+		x = x.convert(p.repr)
+	}
+	for i := range p.values {
+		if p.values[i] != x.values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Poly) convert(repr Representation) *Poly {
+	if repr == NTT {
+		return p.ToNTT()
+	}
+	return p.ToCoefficients()
+}
+
+// check returns an error if p and x cannot be operands of the same operation.
+func (p *Poly) check(x *Poly) error {
+	if len(p.values) != len(x.values) {
+		return ErrDegreeMismatch
+	}
+	if p.repr != x.repr {
+		return ErrRepresentationMismatch
+	}
+	return nil
+}
+
+// pointwise returns the polynomial of values op(p[i], x[i]) mod q.
+func (p *Poly) pointwise(x *Poly, op func(a, b int) int) *Poly {
+	res := &Poly{values: make([]int16, len(p.values)), repr: p.repr}
+	for i := range p.values {
+		res.values[i] = int16(util.Pmod(op(int(p.values[i]), int(x.values[i])), Q))
+	}
+	return res
+}
+
+// Add returns p + x. The polynomials must have the same degree and representation.
+func (p *Poly) Add(x *Poly) (*Poly, error) {
+	if err := p.check(x); err != nil {
+		return nil, err
+	}
+	return p.pointwise(x, func(a, b int) int { return a + b }), nil
+}
+
+// Sub returns p - x. The polynomials must have the same degree and representation.
+func (p *Poly) Sub(x *Poly) (*Poly, error) {
+	if err := p.check(x); err != nil {
+		return nil, err
+	}
+	return p.pointwise(x, func(a, b int) int { return a - b }), nil
+}
+
+// Neg returns -p.
+func (p *Poly) Neg() *Poly {
+	res := &Poly{values: make([]int16, len(p.values)), repr: p.repr}
+	for i, v := range p.values {
+		res.values[i] = int16(util.Pmod(-int(v), Q))
+	}
+	return res
+}
+
+// Mul returns p * x in the representation of the operands. The
+// polynomials must have the same degree and representation.
+func (p *Poly) Mul(x *Poly) (*Poly, error) {
+	if err := p.check(x); err != nil {
+		return nil, err
+	}
+	res := p.ToNTT().pointwise(x.ToNTT(), func(a, b int) int { return a * b })
+	return res.convert(p.repr), nil
+}
+
+// Inverse returns the inverse of p in the ring, or ErrNotInvertible if p
+// has a zero NTT value.
+func (p *Poly) Inverse() (*Poly, error) {
+	t := p.ToNTT()
+	for i, v := range t.values {
+		if v == 0 {
+			return nil, ErrNotInvertible
+		}
+		t.values[i] = inverseModQ(v)
+	}
+	return t.convert(p.repr), nil
+}
+
+// Div returns p / x. The polynomials must have the same degree and
+// representation, and x must be invertible.
+func (p *Poly) Div(x *Poly) (*Poly, error) {
+	if err := p.check(x); err != nil {
+		return nil, err
+	}
+	inv, err := x.Inverse()
+	if err != nil {
+		return nil, err
+	}
+	return p.Mul(inv)
+}
+
+// inverseModQ returns v^(q-2) mod q, the inverse of v != 0.
+func inverseModQ(v int16) int16 {
+	res, base := 1, int(v)
+	for e := Q - 2; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			res = res * base % Q
+		}
+		base = base * base % Q
+	}
+	return int16(res)
+}
+
+// SquaredNorm returns the squared Euclidean norm of the centered
+// coefficients of p.
+func (p *Poly) SquaredNorm() int64 {
+	var norm int64
+	for _, c := range p.Centered() {
+		norm += int64(c) * int64(c)
+	}
+	return norm
+}
+
+// InfinityNorm returns the largest absolute value of the centered
+// coefficients of p.
+func (p *Poly) InfinityNorm() int16 {
+	var norm int16
+	for _, c := range p.Centered() {
+		if c < 0 {
+			c = -c
+		}
+		if c > norm {
+			norm = c
+		}
+	}
+	return norm
+}
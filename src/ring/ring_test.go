@@ -0,0 +1,188 @@
+package ring
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+)
+
+func randomPoly(t *testing.T, rng *rand.Rand, n int) *Poly {
+	t.Helper()
+	coeffs := make([]int16, n)
+	for i := range coeffs {
+		coeffs[i] = int16(rng.Intn(Q))
+	}
+	p, err := New(coeffs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// schoolbookMul multiplies in Z_q[x]/(x^n + 1) by definition.
+func schoolbookMul(f, g []int16) []int16 {
+	n := len(f)
+	acc := make([]int, n)
+	for i := range f {
+		for j := range g {
+			v := int(f[i]) * int(g[j])
+			if i+j < n {
+				acc[i+j] += v
+			} else {
+				acc[i+j-n] -= v
+			}
+		}
+	}
+	res := make([]int16, n)
+	for i, v := range acc {
+		res[i] = int16(((v % Q) + Q) % Q)
+	}
+	return res
+}
+
+func TestArithmetic(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{2, 4, 64, 512, 1024} {
+		f, g := randomPoly(t, rng, n), randomPoly(t, rng, n)
+
+		prod, err := f.Mul(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := New(schoolbookMul(f.Values(), g.Values()))
+		if prod.Representation() != Coefficients || !prod.Equal(want) {
+			t.Errorf("n = %d: Mul differs from the schoolbook product", n)
+		}
+		prodNTT, err := f.ToNTT().Mul(g.ToNTT())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prodNTT.Representation() != NTT || !prodNTT.Equal(prod) {
+			t.Errorf("n = %d: Mul in NTT representation differs", n)
+		}
+
+		sum, _ := f.Add(g)
+		diff, _ := sum.Sub(g)
+		if !diff.Equal(f) {
+			t.Errorf("n = %d: (f + g) - g != f", n)
+		}
+		if zero, _ := f.Add(f.Neg()); zero.SquaredNorm() != 0 {
+			t.Errorf("n = %d: f + (-f) != 0", n)
+		}
+
+		quo, err := prod.Div(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !quo.Equal(f) {
+			t.Errorf("n = %d: (f * g) / g != f", n)
+		}
+		if !f.ToNTT().ToCoefficients().Equal(f) || f.ToNTT().Equal(f.ToNTT().Neg()) {
+			t.Errorf("n = %d: NTT conversions are not inverse", n)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	one, _ := New([]int16{1, 0, 0, 0})
+	inv, err := one.Inverse()
+	if err != nil || !inv.Equal(one) {
+		t.Errorf("Inverse(1) = %v, %v", inv, err)
+	}
+	x, _ := New([]int16{0, 1, 0, 0})
+	inv, err = x.Inverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// x^-1 = -x^3 in Z_q[x]/(x^4 + 1)
+	if got := inv.Centered(); got[0] != 0 || got[1] != 0 || got[2] != 0 || got[3] != -1 {
+		t.Errorf("Inverse(x) = %v", got)
+	}
+
+	// x + 1479 vanishes at the root -1479 of x^2 + 1
+	p, _ := New([]int16{1479, 1})
+	if _, err := p.Inverse(); err != ErrNotInvertible {
+		t.Errorf("Inverse of a zero divisor returned %v", err)
+	}
+	if _, err := one.Div(mustZero(t, 4)); err != ErrNotInvertible {
+		t.Errorf("division by zero returned %v", err)
+	}
+}
+
+func mustZero(t *testing.T, n int) *Poly {
+	t.Helper()
+	p, err := Zero(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestErrors(t *testing.T) {
+	for _, n := range []int{0, 1, 3, 48, 2048} {
+		if _, err := New(make([]int16, n)); err != ErrInvalidDegree {
+			t.Errorf("New of %d coefficients returned %v", n, err)
+		}
+		if _, err := Zero(n); err != ErrInvalidDegree {
+			t.Errorf("Zero(%d) returned %v", n, err)
+		}
+	}
+	a, b := mustZero(t, 8), mustZero(t, 16)
+	for name, op := range map[string]func(*Poly, *Poly) (*Poly, error){
+		"Add": (*Poly).Add, "Sub": (*Poly).Sub, "Mul": (*Poly).Mul, "Div": (*Poly).Div,
+	} {
+		if _, err := op(a, b); err != ErrDegreeMismatch {
+			t.Errorf("%s of different degrees returned %v", name, err)
+		}
+		if _, err := op(a, a.ToNTT()); err != ErrRepresentationMismatch {
+			t.Errorf("%s of different representations returned %v", name, err)
+		}
+	}
+	if a.Equal(b) || a.Equal(nil) {
+		t.Error("polynomials of different degrees are equal")
+	}
+}
+
+func TestNorms(t *testing.T) {
+	p, _ := New([]int16{3, -4, Q - 1, 6145})
+	if got := p.Centered(); got[0] != 3 || got[1] != -4 || got[2] != -1 || got[3] != -6144 {
+		t.Errorf("Centered() = %v", got)
+	}
+	if got := p.ToNTT().SquaredNorm(); got != 9+16+1+6144*6144 {
+		t.Errorf("SquaredNorm() = %d", got)
+	}
+	if got := p.InfinityNorm(); got != 6144 {
+		t.Errorf("InfinityNorm() = %d", got)
+	}
+}
+
+// The public key is h = g / f mod q.
+func TestPublicKey(t *testing.T) {
+	data, err := os.ReadFile("../testdata/falcon512_priv.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	polys := privKey.Polynomials()
+	f, _ := New(polys[0])
+	g, _ := New(polys[1])
+	h, err := New(privKey.GetPublicKey().Polynomial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fh, err := f.Mul(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fh.Equal(g) {
+		t.Error("f * h != g")
+	}
+	if quo, _ := g.Div(f); !quo.Equal(h) {
+		t.Error("g / f != h")
+	}
+}
@@ -0,0 +1,137 @@
+package falcon
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"io"
+)
+
+/*
+crypto.Signer and crypto.PublicKey interoperability.
+
+Sign follows the convention of crypto/ed25519: a zero hash function selects
+pure FN-DSA over the full message, and a hash function selects HashFN-DSA
+over the digest of the message. *Options may be passed as crypto.SignerOpts
+to set a context string or the signature format.
+*/
+
+var (
+	_ crypto.Signer     = (*PrivateKey)(nil)
+	_ crypto.SignerOpts = (*Options)(nil)
+)
+
+// preHashOf maps the hash functions of the crypto package to pre-hash functions.
+var preHashOf = map[crypto.Hash]PreHash{
+	crypto.SHA256:   PreHashSHA256,
+	crypto.SHA384:   PreHashSHA384,
+	crypto.SHA512:   PreHashSHA512,
+	crypto.SHA3_224: PreHashSHA3_224,
+	crypto.SHA3_256: PreHashSHA3_256,
+	crypto.SHA3_384: PreHashSHA3_384,
+	crypto.SHA3_512: PreHashSHA3_512,
+}
+
+// HashFunc implements crypto.SignerOpts. It returns the hash function of
+// the pre-hash, or 0 for pure FN-DSA and for the SHAKE pre-hashes, which
+// have no crypto.Hash value.
+func (opts *Options) HashFunc() crypto.Hash {
+	if opts == nil {
+		return 0
+	}
+	for h, ph := range preHashOf {
+		if ph == opts.PreHash {
+			return h
+		}
+	}
+	return 0
+}
+
+// SignerOptions converts crypto.SignerOpts to the FN-DSA options used by
+// Sign, for signers that forward the options to another process.
+func SignerOptions(opts crypto.SignerOpts) (*Options, error) {
+	if o, ok := opts.(*Options); ok {
+		if o == nil {
+			return &Options{}, nil
+		}
+		return o, nil
+	}
+	if opts == nil || opts.HashFunc() == 0 {
+		return &Options{}, nil
+	}
+	ph, ok := preHashOf[opts.HashFunc()]
+	if !ok {
+		return nil, ErrUnsupportedPreHash
+	}
+	return &Options{PreHash: ph}, nil
+}
+
+// Public returns the public key of privKey, as a *PublicKey, or nil if f is
+// not invertible mod q.
+func (privKey *PrivateKey) Public() crypto.PublicKey {
+	pubKey, err := privKey.DerivePublicKey()
+	if err != nil {
+		return nil
+	}
+	return pubKey
+}
+
+// Sign implements crypto.Signer with FN-DSA. If opts.HashFunc() is zero,
+// digest is the full message, signed with pure FN-DSA. Otherwise digest is
+// the hash of the message with opts.HashFunc(), signed with HashFN-DSA, and
+// the signature verifies with VerifyFNDSA and the matching PreHash. If opts
+// is an *Options, digest is the message or its digest depending on
+// opts.PreHash. The randomness of the signature is read from random, or from
+// crypto/rand if random is nil.
+func (privKey *PrivateKey) Sign(random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return signDigest(privKey.signWithRand, random, digest, opts)
+}
+
+// signDigest implements crypto.Signer on top of a round-3 signing function.
+func signDigest(sign func([]byte, SignatureFormat, io.Reader) ([]byte, error), random io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	o, err := SignerOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	mp, err := messageRepresentative(digest, o)
+	if err != nil {
+		return nil, err
+	}
+	if random == nil {
+		random = rand.Reader
+	}
+	return sign(mp, o.Format, random)
+}
+
+// Equal reports whether privKey and x are the same private key. The
+// coefficients are compared in constant time.
+func (privKey *PrivateKey) Equal(x crypto.PrivateKey) bool {
+	other, ok := x.(*PrivateKey)
+	if !ok || other == nil || privKey.n != other.n {
+		return false
+	}
+	eq := 1
+	for _, p := range [][2][]int16{{privKey.f, other.f}, {privKey.g, other.g}, {privKey.F, other.F}, {privKey.G, other.G}} {
+		eq &= constantTimeEqualInt16(p[0], p[1])
+	}
+	return eq == 1
+}
+
+// Equal reports whether pubKey and x are the same public key.
+func (pubKey *PublicKey) Equal(x crypto.PublicKey) bool {
+	other, ok := x.(*PublicKey)
+	return ok && other != nil && pubKey.n == other.n && equalInt16(pubKey.h, other.h)
+}
+
+// constantTimeEqualInt16 returns 1 if a and b are equal and 0 otherwise.
+// Only the lengths may leak through timing.
+func constantTimeEqualInt16(a, b []int16) int {
+	if len(a) != len(b) {
+		return 0
+	}
+	var diff uint16
+	for i := range a {
+		diff |= uint16(a[i] ^ b[i])
+	}
+	return subtle.ConstantTimeEq(int32(diff), 0)
+}
@@ -0,0 +1,106 @@
+package falcon
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestCryptoSigner(t *testing.T) {
+	var signer crypto.Signer = firstPrivKey512
+	pub, ok := signer.Public().(*PublicKey)
+	if !ok || !pub.Equal(firstPrivKey512.GetPublicKey()) {
+		t.Fatal("Public does not return the public key")
+	}
+	message := []byte("message")
+	sum256 := sha256.Sum256(message)
+	sum512 := sha512.Sum512(message)
+
+	testCases := []struct {
+		digest []byte
+		opts   crypto.SignerOpts
+		verify *Options
+	}{
+		{message, crypto.Hash(0), nil},
+		{message, nil, nil},
+		{message, (*Options)(nil), nil},
+		{sum256[:], crypto.SHA256, &Options{PreHash: PreHashSHA256}},
+		{sum512[:], crypto.SHA512, &Options{PreHash: PreHashSHA512}},
+		{message, &Options{Context: []byte("ctx"), Format: FormatCT}, &Options{Context: []byte("ctx")}},
+		{sum256[:], &Options{PreHash: PreHashSHA256, Context: []byte("ctx")}, &Options{PreHash: PreHashSHA256, Context: []byte("ctx")}},
+	}
+	for i, tc := range testCases {
+		sig, err := signer.Sign(nil, tc.digest, tc.opts)
+		if err != nil {
+			t.Fatalf("case %d: Sign returned %v", i, err)
+		}
+		if err := pub.VerifyFNDSA(message, sig, tc.verify); err != nil {
+			t.Errorf("case %d: VerifyFNDSA returned %v", i, err)
+		}
+	}
+
+	// The randomness of the signature comes from the given reader
+	sig1, _ := signer.Sign(deterministicReader("signer"), message, crypto.Hash(0))
+	sig2, _ := signer.Sign(deterministicReader("signer"), message, crypto.Hash(0))
+	if !bytes.Equal(sig1, sig2) {
+		t.Error("signatures with the same randomness differ")
+	}
+
+	if _, err := signer.Sign(nil, message, crypto.SHA256); err != ErrInvalidDigestLength {
+		t.Errorf("Sign with a message as digest returned %v, want %v", err, ErrInvalidDigestLength)
+	}
+	if _, err := signer.Sign(nil, message[:4], crypto.MD5); err != ErrUnsupportedPreHash {
+		t.Errorf("Sign with MD5 returned %v, want %v", err, ErrUnsupportedPreHash)
+	}
+}
+
+func TestOptionsHashFunc(t *testing.T) {
+	testCases := []struct {
+		opts *Options
+		want crypto.Hash
+	}{
+		{nil, 0},
+		{&Options{}, 0},
+		{&Options{PreHash: PreHashSHA256}, crypto.SHA256},
+		{&Options{PreHash: PreHashSHA3_512}, crypto.SHA3_512},
+		{&Options{PreHash: PreHashSHAKE256}, 0},
+	}
+	for _, tc := range testCases {
+		if got := tc.opts.HashFunc(); got != tc.want {
+			t.Errorf("HashFunc(%v) = %v, want %v", tc.opts, got, tc.want)
+		}
+	}
+}
+
+func TestKeyEqual(t *testing.T) {
+	other, otherPub, err := NewKeyPair(512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := MarshalPrivateKey(firstPrivKey512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, err := ParsePrivateKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edPriv, _ := ed25519.GenerateKey(nil)
+
+	if !firstPrivKey512.Equal(same) || !same.Equal(firstPrivKey512) {
+		t.Error("Equal is false for the same private key")
+	}
+	if firstPrivKey512.Equal(other) || firstPrivKey512.Equal(edPriv) || firstPrivKey512.Equal(nil) || firstPrivKey512.Equal((*PrivateKey)(nil)) {
+		t.Error("Equal is true for a different private key")
+	}
+	pub := firstPrivKey512.GetPublicKey()
+	if !pub.Equal(same.Public()) {
+		t.Error("Equal is false for the same public key")
+	}
+	if pub.Equal(otherPub) || pub.Equal(edPub) || pub.Equal(nil) || pub.Equal((*PublicKey)(nil)) {
+		t.Error("Equal is true for a different public key")
+	}
+}
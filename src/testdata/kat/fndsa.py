@@ -0,0 +1,78 @@
+#!/usr/bin/env python3
+"""Generates fndsa.json with the Falcon reference implementation.
+
+M' is computed here with hashlib, independently of the Go code, and signed
+by ref (ref.c built against the reference implementation), which hashes
+salt ‖ M' to a point as in round 3. Usage: fndsa.py path/to/ref > fndsa.json
+"""
+import hashlib
+import json
+import subprocess
+import sys
+
+REF = sys.argv[1]
+
+# Name, DER encoding of the OID and hash function of each pre-hash.
+NIST = bytes.fromhex("06096086480165030402")
+PRE_HASHES = [
+    ("SHA-256", NIST + b"\x01", lambda m: hashlib.sha256(m).digest()),
+    ("SHA-384", NIST + b"\x02", lambda m: hashlib.sha384(m).digest()),
+    ("SHA-512", NIST + b"\x03", lambda m: hashlib.sha512(m).digest()),
+    ("SHA3-224", NIST + b"\x07", lambda m: hashlib.sha3_224(m).digest()),
+    ("SHA3-256", NIST + b"\x08", lambda m: hashlib.sha3_256(m).digest()),
+    ("SHA3-384", NIST + b"\x09", lambda m: hashlib.sha3_384(m).digest()),
+    ("SHA3-512", NIST + b"\x0a", lambda m: hashlib.sha3_512(m).digest()),
+    ("SHAKE128", NIST + b"\x0b", lambda m: hashlib.shake_128(m).digest(32)),
+    ("SHAKE256", NIST + b"\x0c", lambda m: hashlib.shake_256(m).digest(64)),
+]
+FORMATS = {"compressed": 1, "padded": 2, "ct": 3}
+
+
+def ref(*args):
+    return subprocess.run([REF, *args], check=True, capture_output=True, text=True).stdout.split()
+
+
+def vector(priv, pre_hash, context, message, fmt):
+    if pre_hash == "none":
+        mp = b"\x00" + bytes([len(context)]) + context + message
+    else:
+        _, oid, h = next(p for p in PRE_HASHES if p[0] == pre_hash)
+        mp = b"\x01" + bytes([len(context)]) + context + oid + h(message)
+    seed = hashlib.sha256(mp + fmt.encode()).hexdigest()
+    (sig,) = ref("sign", priv, str(FORMATS[fmt]), seed, mp.hex())
+    return {
+        "pre_hash": pre_hash,
+        "context": context.hex(),
+        "message": message.hex(),
+        "format": fmt,
+        "signature": sig,
+    }
+
+
+def key(logn, vectors):
+    priv, pub = ref("keygen", str(logn), hashlib.sha256(b"fndsa-%d" % logn).hexdigest())
+    return {
+        "n": 1 << logn,
+        "private_key": priv,
+        "public_key": pub,
+        "vectors": [vector(priv, *v) for v in vectors],
+    }
+
+
+message = b"FN-DSA known-answer test"
+context = b"falconGo"
+vectors512 = [
+    ("none", b"", message, "compressed"),
+    ("none", context, message, "compressed"),
+    ("none", b"", b"", "padded"),
+    ("none", context, message, "ct"),
+]
+vectors512 += [(p[0], context if i % 2 else b"", message, "compressed") for i, p in enumerate(PRE_HASHES)]
+vectors1024 = [
+    ("none", b"", message, "compressed"),
+    ("none", context, message, "ct"),
+    ("SHA-512", context, message, "padded"),
+    ("SHAKE256", b"", message, "compressed"),
+]
+json.dump([key(9, vectors512), key(10, vectors1024)], sys.stdout, indent=2)
+print()
@@ -0,0 +1,54 @@
+/* Command-line wrapper of the Falcon reference implementation. */
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include "falcon.h"
+
+static size_t unhex(unsigned char *out, const char *s) {
+	size_t n = strlen(s) / 2;
+	for (size_t i = 0; i < n; i++) sscanf(s + 2 * i, "%2hhx", &out[i]);
+	return n;
+}
+
+static void hex(const unsigned char *b, size_t n) {
+	for (size_t i = 0; i < n; i++) printf("%02x", b[i]);
+	printf("\n");
+}
+
+static unsigned char priv[4096], pub[4096], sig[4096], msg[1 << 16], seed[256], tmp[1 << 17];
+
+int main(int argc, char **argv) {
+	shake256_context rng;
+	if (argc == 4 && !strcmp(argv[1], "keygen")) {
+		unsigned logn = atoi(argv[2]);
+		size_t sl = unhex(seed, argv[3]);
+		shake256_init_prng_from_seed(&rng, seed, sl);
+		if (falcon_keygen_make(&rng, logn, priv, FALCON_PRIVKEY_SIZE(logn), pub, FALCON_PUBKEY_SIZE(logn), tmp, sizeof tmp)) return 1;
+		hex(priv, FALCON_PRIVKEY_SIZE(logn));
+		hex(pub, FALCON_PUBKEY_SIZE(logn));
+		return 0;
+	}
+	if (argc == 6 && !strcmp(argv[1], "sign")) {
+		size_t pl = unhex(priv, argv[2]);
+		int type = atoi(argv[3]);
+		size_t sl = unhex(seed, argv[4]);
+		size_t ml = unhex(msg, argv[5]);
+		int logn = falcon_get_logn(priv, pl);
+		size_t siglen = type == FALCON_SIG_CT ? FALCON_SIG_CT_SIZE(logn) :
+			type == FALCON_SIG_PADDED ? FALCON_SIG_PADDED_SIZE(logn) : FALCON_SIG_COMPRESSED_MAXSIZE(logn);
+		shake256_init_prng_from_seed(&rng, seed, sl);
+		if (falcon_sign_dyn(&rng, sig, &siglen, type, priv, pl, msg, ml, tmp, sizeof tmp)) return 1;
+		hex(sig, siglen);
+		return 0;
+	}
+	if (argc == 5 && !strcmp(argv[1], "verify")) {
+		size_t kl = unhex(pub, argv[2]);
+		size_t sl = unhex(sig, argv[3]);
+		size_t ml = unhex(msg, argv[4]);
+		int r = falcon_verify(sig, sl, 0, pub, kl, msg, ml, tmp, sizeof tmp);
+		printf("%d\n", r);
+		return r != 0;
+	}
+	fprintf(stderr, "usage: ref keygen logn seed | sign priv type seed msg | verify pub sig msg\n");
+	return 2;
+}
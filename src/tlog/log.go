@@ -0,0 +1,333 @@
+package tlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Indra4091/falconGo/src/note"
+)
+
+// MaxRecordSize is the maximum size of a record.
+const MaxRecordSize = 1 << 24
+
+const (
+	recordsFile    = "records"
+	checkpointFile = "checkpoint"
+)
+
+var (
+	// ErrRecordTooLarge is returned when appending a record larger than MaxRecordSize
+	ErrRecordTooLarge = errors.New("record too large")
+	// ErrCorruptLog is returned by Open when the records file is damaged
+	// within the latest checkpoint, or does not match it
+	ErrCorruptLog = errors.New("records do not match the latest checkpoint")
+)
+
+// A Log is an append-only log of records stored in a directory. The
+// records are stored in a single file, each prefixed by its uint32 big
+// endian length, and the latest signed checkpoint in another. The hashes
+// of the complete subtrees are kept in memory, so that root hashes and
+// proofs take O(log n) hashes.
+type Log struct {
+	mu      sync.Mutex
+	dir     string
+	f       *os.File
+	offsets []int64  // offset of each record
+	end     int64    // offset of the end of the last record
+	levels  [][]Hash // levels[k][i] is the hash of records [i<<k, (i+1)<<k)
+}
+
+// Open opens the log stored in dir, creating it if needed. Records which
+// cannot be read past the tree size of the latest checkpoint, such as a
+// record truncated by an interrupted Append, are discarded. Open returns
+// ErrCorruptLog if the records up to that size cannot be read or do not
+// have the root hash of the checkpoint.
+func Open(dir string) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, recordsFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, f: f}
+	if err := l.load(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// load reads the records file, computes the hashes of the tree and checks
+// them against the latest checkpoint.
+func (l *Log) load() error {
+	c, err := l.storedCheckpoint()
+	if err != nil {
+		return err
+	}
+	info, err := l.f.Stat()
+	if err != nil {
+		return err
+	}
+	r := io.NewSectionReader(l.f, 0, info.Size())
+	var prefix [4]byte
+	var record []byte
+	for {
+		if _, err := io.ReadFull(r, prefix[:]); err != nil {
+			break
+		}
+		n := binary.BigEndian.Uint32(prefix[:])
+		if n > MaxRecordSize {
+			break
+		}
+		if cap(record) < int(n) {
+			record = make([]byte, n)
+		}
+		record = record[:n]
+		if _, err := io.ReadFull(r, record); err != nil {
+			break
+		}
+		l.push(l.end, RecordHash(record))
+		l.end += 4 + int64(n)
+	}
+	size := int64(len(l.offsets))
+	if c != nil && (size < c.Size || l.treeHash(c.Size) != c.Hash) {
+		return ErrCorruptLog
+	}
+	if l.end != info.Size() {
+		return l.f.Truncate(l.end)
+	}
+	return nil
+}
+
+// storedCheckpoint returns the latest checkpoint, or nil if there is none.
+// Its signatures are not verified: the log only checks its own records
+// against it.
+func (l *Log) storedCheckpoint() (*Checkpoint, error) {
+	signed, err := l.LatestCheckpoint()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var unverified *note.UnverifiedNoteError
+	if _, err := note.Open(signed, nil); !errors.As(err, &unverified) {
+		return nil, ErrMalformedCheckpoint
+	}
+	return ParseCheckpoint(unverified.Note.Text)
+}
+
+// push adds the leaf hash of the record at offset to the tree.
+func (l *Log) push(offset int64, leaf Hash) {
+	l.offsets = append(l.offsets, offset)
+	h := leaf
+	for k := 0; ; k++ {
+		if k == len(l.levels) {
+			l.levels = append(l.levels, nil)
+		}
+		l.levels[k] = append(l.levels[k], h)
+		if len(l.levels[k])%2 == 1 {
+			return
+		}
+		h = NodeHash(l.levels[k][len(l.levels[k])-2], h)
+	}
+}
+
+// Close closes the records file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// Append appends a record to the log and returns its index. The record is
+// not synced to disk until Sync or Checkpoint is called.
+func (l *Log) Append(record []byte) (int64, error) {
+	if len(record) > MaxRecordSize {
+		return 0, ErrRecordTooLarge
+	}
+	buf := make([]byte, 4+len(record))
+	binary.BigEndian.PutUint32(buf, uint32(len(record)))
+	copy(buf[4:], record)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.WriteAt(buf, l.end); err != nil {
+		// Drop what was written of the record
+		l.f.Truncate(l.end)
+		return 0, err
+	}
+	l.push(l.end, RecordHash(record))
+	l.end += int64(len(buf))
+	return int64(len(l.offsets)) - 1, nil
+}
+
+// Sync commits the records to disk.
+func (l *Log) Sync() error {
+	return l.f.Sync()
+}
+
+// Size returns the number of records of the log.
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(len(l.offsets))
+}
+
+// Record returns the record at index.
+func (l *Log) Record(index int64) ([]byte, error) {
+	l.mu.Lock()
+	if index < 0 || index >= int64(len(l.offsets)) {
+		l.mu.Unlock()
+		return nil, ErrInvalidIndex
+	}
+	offset := l.offsets[index]
+	end := l.end
+	if index+1 < int64(len(l.offsets)) {
+		end = l.offsets[index+1]
+	}
+	l.mu.Unlock()
+
+	buf := make([]byte, end-offset)
+	if _, err := l.f.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+	return buf[4:], nil
+}
+
+// subtreeHash returns the hash of the records [a, b), 0 <= a < b <= size.
+func (l *Log) subtreeHash(a, b int64) Hash {
+	n := b - a
+	if n&(n-1) == 0 && a%n == 0 {
+		k := bits.TrailingZeros64(uint64(n))
+		return l.levels[k][a>>k]
+	}
+	k := split(n)
+	return NodeHash(l.subtreeHash(a, a+k), l.subtreeHash(a+k, b))
+}
+
+// treeHash returns the root hash of the tree of the first size records.
+func (l *Log) treeHash(size int64) Hash {
+	if size == 0 {
+		return EmptyHash
+	}
+	return l.subtreeHash(0, size)
+}
+
+// TreeHash returns the root hash of the tree of the first size records.
+func (l *Log) TreeHash(size int64) (Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if size < 0 || size > int64(len(l.offsets)) {
+		return Hash{}, ErrInvalidIndex
+	}
+	return l.treeHash(size), nil
+}
+
+// InclusionProof returns the proof of inclusion of the record at index in
+// the tree of the first size records.
+func (l *Log) InclusionProof(index, size int64) ([]Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if index < 0 || index >= size || size > int64(len(l.offsets)) {
+		return nil, ErrInvalidIndex
+	}
+	return l.inclusionProof(index, 0, size), nil
+}
+
+// inclusionProof returns PATH(m, D[a:b]) of RFC 6962, section 2.1.1.
+func (l *Log) inclusionProof(m, a, b int64) []Hash {
+	if b-a <= 1 {
+		return nil
+	}
+	k := split(b - a)
+	if m < k {
+		return append(l.inclusionProof(m, a, a+k), l.subtreeHash(a+k, b))
+	}
+	return append(l.inclusionProof(m-k, a+k, b), l.subtreeHash(a, a+k))
+}
+
+// ConsistencyProof returns the proof that the tree of the first oldSize
+// records is a prefix of the tree of the first newSize records.
+func (l *Log) ConsistencyProof(oldSize, newSize int64) ([]Hash, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if oldSize < 0 || oldSize > newSize || newSize > int64(len(l.offsets)) {
+		return nil, ErrInvalidIndex
+	}
+	if oldSize == 0 || oldSize == newSize {
+		return nil, nil
+	}
+	return l.consistencyProof(oldSize, 0, newSize, true), nil
+}
+
+// consistencyProof returns SUBPROOF(m, D[a:b], complete) of RFC 6962,
+// section 2.1.2.
+func (l *Log) consistencyProof(m, a, b int64, complete bool) []Hash {
+	if m == b-a {
+		if complete {
+			return nil
+		}
+		return []Hash{l.subtreeHash(a, b)}
+	}
+	k := split(b - a)
+	if m <= k {
+		return append(l.consistencyProof(m, a, a+k, complete), l.subtreeHash(a+k, b))
+	}
+	return append(l.consistencyProof(m-k, a+k, b, false), l.subtreeHash(a, a+k))
+}
+
+// Checkpoint syncs the records to disk, signs a checkpoint of the whole
+// log with signer and stores it as the latest checkpoint.
+func (l *Log) Checkpoint(origin string, signer note.Signer) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Sync(); err != nil {
+		return nil, err
+	}
+	if origin == "" || strings.Contains(origin, "\n") {
+		return nil, ErrMalformedCheckpoint
+	}
+	size := int64(len(l.offsets))
+	c := &Checkpoint{Origin: origin, Size: size, Hash: l.treeHash(size)}
+	signed, err := note.Sign(&note.Note{Text: c.String()}, signer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the latest checkpoint atomically
+	tmp, err := os.CreateTemp(l.dir, checkpointFile+".*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(signed); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, checkpointFile)); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// LatestCheckpoint returns the latest checkpoint signed by Checkpoint, or
+// an error satisfying errors.Is(err, fs.ErrNotExist) if there is none.
+func (l *Log) LatestCheckpoint() ([]byte, error) {
+	return os.ReadFile(filepath.Join(l.dir, checkpointFile))
+}
@@ -0,0 +1,255 @@
+package tlog
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	falcon "github.com/Indra4091/falconGo/src"
+	"github.com/Indra4091/falconGo/src/note"
+)
+
+const origin = "example.com/audit"
+
+func testKey(t *testing.T) (note.Signer, note.Verifier) {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/falcon512_priv.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKey, err := falcon.ParsePrivateKeyPEM(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skey, err := note.NewSignerKey(origin, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vkey, err := note.NewVerifierKey(origin, privKey.GetPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := note.NewSigner(skey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := note.NewVerifier(vkey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, verifier
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []string{"login alice", "", "logout alice", strings.Repeat("x", 1000)}
+	for i, r := range records {
+		if index, err := l.Append([]byte(r)); err != nil || index != int64(i) {
+			t.Fatalf("Append returned %d, %v", index, err)
+		}
+	}
+	root, _ := l.TreeHash(l.Size())
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record truncated by an interrupted append is discarded
+	f, err := os.OpenFile(filepath.Join(dir, recordsFile), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 10, 'p', 'a', 'r'})
+	f.Close()
+
+	l, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if l.Size() != int64(len(records)) {
+		t.Fatalf("reopened log of %d records", l.Size())
+	}
+	if h, _ := l.TreeHash(l.Size()); h != root {
+		t.Errorf("reopened log root %s, want %s", h, root)
+	}
+	for i, r := range records {
+		if got, err := l.Record(int64(i)); err != nil || string(got) != r {
+			t.Errorf("Record(%d) = %.20q, %v", i, got, err)
+		}
+	}
+	if _, err := l.Record(int64(len(records))); err != ErrInvalidIndex {
+		t.Errorf("Record beyond the log returned %v", err)
+	}
+	if index, err := l.Append([]byte("login bob")); err != nil || index != int64(len(records)) {
+		t.Errorf("Append after reopening returned %d, %v", index, err)
+	}
+	if got, err := l.Record(int64(len(records))); err != nil || string(got) != "login bob" {
+		t.Errorf("Record of the appended record = %q, %v", got, err)
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	signer, key := testKey(t)
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if _, err := l.LatestCheckpoint(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LatestCheckpoint of a new log returned %v", err)
+	}
+
+	v := NewVerifier(origin, key)
+	for i := 0; i < 5; i++ {
+		l.Append([]byte{byte(i)})
+	}
+	signed, err := l.Checkpoint(origin, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := l.LatestCheckpoint(); err != nil || string(latest) != string(signed) {
+		t.Errorf("LatestCheckpoint returned %v", err)
+	}
+	c, err := v.Update(signed, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Size != 5 || v.Trusted() != *c {
+		t.Errorf("checkpoint %+v", c)
+	}
+	proof, _ := l.InclusionProof(3, 5)
+	if err := v.VerifyRecord(3, []byte{3}, proof); err != nil {
+		t.Error(err)
+	}
+	if err := v.VerifyRecord(3, []byte{4}, proof); err != ErrInvalidProof {
+		t.Errorf("VerifyRecord of another record returned %v", err)
+	}
+
+	for i := 5; i < 12; i++ {
+		l.Append([]byte{byte(i)})
+	}
+	newSigned, err := l.Checkpoint(origin, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Update(newSigned, nil); err != ErrInvalidProof {
+		t.Errorf("Update without a consistency proof returned %v", err)
+	}
+	proof, _ = l.ConsistencyProof(5, 12)
+	if _, err := v.Update(newSigned, proof); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Update(signed, nil); err != ErrRollback {
+		t.Errorf("Update to an older checkpoint returned %v", err)
+	}
+
+	// A checkpoint of a forked log does not verify
+	fork, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fork.Close()
+	for i := 0; i < 13; i++ {
+		fork.Append([]byte{byte(i ^ 1)})
+	}
+	forked, err := fork.Checkpoint(origin, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, _ = fork.ConsistencyProof(12, 13)
+	if _, err := v.Update(forked, proof); err != ErrInvalidProof {
+		t.Errorf("Update to a forked checkpoint returned %v", err)
+	}
+
+	tampered := strings.Replace(string(newSigned), "\n12\n", "\n13\n", 1)
+	var invalid *note.InvalidSignatureError
+	if _, err := OpenCheckpoint([]byte(tampered), key); !errors.As(err, &invalid) {
+		t.Errorf("OpenCheckpoint of a modified checkpoint returned %v", err)
+	}
+	other, err := l.Checkpoint("example.com/other", signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Update(other, nil); err != ErrOriginMismatch {
+		t.Errorf("Update to a checkpoint of another log returned %v", err)
+	}
+	if _, err := l.Checkpoint("two\nlines", signer); err != ErrMalformedCheckpoint {
+		t.Errorf("Checkpoint with an invalid origin returned %v", err)
+	}
+}
+
+func TestCorruption(t *testing.T) {
+	signer, _ := testKey(t)
+	dir := t.TempDir()
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each record takes 7 bytes with its length
+	for i := 0; i < 8; i++ {
+		l.Append([]byte{'r', 'e', byte('0' + i)})
+		if i == 5 {
+			if _, err := l.Checkpoint(origin, signer); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	l.Sync()
+	l.Close()
+	path := filepath.Join(dir, recordsFile)
+	records, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Damage within the checkpoint is reported and the file left intact
+	for _, offset := range []int{2 * 7, 3*7 + 5} {
+		damaged := append([]byte(nil), records...)
+		damaged[offset] ^= 0xff
+		if err := os.WriteFile(path, damaged, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Open(dir); err != ErrCorruptLog {
+			t.Errorf("damaged byte %d: Open returned %v", offset, err)
+		}
+		if data, _ := os.ReadFile(path); len(data) != len(records) {
+			t.Errorf("damaged byte %d: records file truncated to %d bytes", offset, len(data))
+		}
+	}
+
+	// Records past the checkpoint are dropped from the first damaged one
+	damaged := append([]byte(nil), records...)
+	damaged[7*7] = 0xff
+	if err := os.WriteFile(path, damaged, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Size() != 7 {
+		t.Errorf("log of %d records after dropping the damaged tail", l.Size())
+	}
+	l.Close()
+
+	// The records must match the checkpoint
+	if err := os.WriteFile(path, records[:5*7], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir); err != ErrCorruptLog {
+		t.Errorf("Open of a log shorter than its checkpoint returned %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, checkpointFile), []byte("not a note"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir); err != ErrMalformedCheckpoint {
+		t.Errorf("Open with a malformed checkpoint returned %v", err)
+	}
+}
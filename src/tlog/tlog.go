@@ -0,0 +1,192 @@
+// Package tlog implements a tamper-evident, append-only log of records: a
+// Merkle tree with the hashing of RFC 6962, inclusion and consistency
+// proofs, and checkpoints of the tree signed with Falcon keys as signed
+// notes.
+//
+// A checkpoint is the text
+//
+//	<origin>
+//	<tree size>
+//	base64(<root hash>)
+//
+// signed by the log operator with a note.Signer, usually named after the
+// origin. A Verifier holds the latest checkpoint it verified, checks that
+// each new checkpoint is consistent with it, and checks inclusion proofs of
+// records against it.
+package tlog
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// HashSize is the size of a Hash.
+const HashSize = sha256.Size
+
+// A Hash is a hash of the tree, of one of its subtrees or of a record.
+type Hash [HashSize]byte
+
+// String returns the base64 encoding of h.
+func (h Hash) String() string {
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+var (
+	// ErrInvalidProof is returned when a proof does not prove the inclusion
+	// of a record or the consistency of two trees
+	ErrInvalidProof = errors.New("invalid proof")
+	// ErrInvalidIndex is returned for record indices and tree sizes out of range
+	ErrInvalidIndex = errors.New("record index or tree size out of range")
+	// ErrMalformedCheckpoint is returned when a checkpoint text cannot be parsed
+	ErrMalformedCheckpoint = errors.New("malformed checkpoint")
+	// ErrOriginMismatch is returned when a checkpoint is of another log
+	ErrOriginMismatch = errors.New("checkpoint of another log")
+)
+
+// EmptyHash is the root hash of the empty tree.
+var EmptyHash = Hash(sha256.Sum256(nil))
+
+// RecordHash returns the leaf hash of a record, SHA-256(0x00 || record).
+func RecordHash(record []byte) Hash {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write(record)
+	var sum Hash
+	h.Sum(sum[:0])
+	return sum
+}
+
+// NodeHash returns the hash of an interior node, SHA-256(0x01 || left || right).
+func NodeHash(left, right Hash) Hash {
+	var buf [1 + 2*HashSize]byte
+	buf[0] = 0x01
+	copy(buf[1:], left[:])
+	copy(buf[1+HashSize:], right[:])
+	return sha256.Sum256(buf[:])
+}
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n int64) int64 {
+	return 1 << (bits.Len64(uint64(n-1)) - 1)
+}
+
+// VerifyInclusion checks that proof proves the inclusion of the record with
+// leaf hash leaf at position index in the tree of the given size and root
+// hash, with the algorithm of RFC 9162, section 2.1.3.2.
+func VerifyInclusion(index, size int64, leaf Hash, proof []Hash, root Hash) error {
+	if index < 0 || index >= size {
+		return ErrInvalidIndex
+	}
+	fn, sn := index, size-1
+	r := leaf
+	for _, p := range proof {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = NodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = NodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || r != root {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// VerifyConsistency checks that proof proves that the tree of size oldSize
+// and root hash oldRoot is a prefix of the tree of size newSize and root
+// hash newRoot, with the algorithm of RFC 9162, section 2.1.4.2.
+func VerifyConsistency(oldSize, newSize int64, oldRoot, newRoot Hash, proof []Hash) error {
+	switch {
+	case oldSize < 0 || oldSize > newSize:
+		return ErrInvalidIndex
+	case oldSize == newSize:
+		if len(proof) != 0 || oldRoot != newRoot {
+			return ErrInvalidProof
+		}
+		return nil
+	case oldSize == 0:
+		// The empty tree is a prefix of every tree
+		if len(proof) != 0 || oldRoot != EmptyHash {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([]Hash{oldRoot}, proof...)
+	}
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = NodeHash(c, fr)
+			sr = NodeHash(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = NodeHash(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || fr != oldRoot || sr != newRoot {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// A Checkpoint is the size and root hash of the tree of a log.
+type Checkpoint struct {
+	Origin string // unique name of the log
+	Size   int64
+	Hash   Hash
+}
+
+// String returns the text of the checkpoint.
+func (c *Checkpoint) String() string {
+	return c.Origin + "\n" + strconv.FormatInt(c.Size, 10) + "\n" + c.Hash.String() + "\n"
+}
+
+// ParseCheckpoint parses the text of a checkpoint. Extension lines after
+// the root hash are ignored.
+func ParseCheckpoint(text string) (*Checkpoint, error) {
+	lines := strings.SplitN(text, "\n", 4)
+	if len(lines) < 4 || lines[0] == "" {
+		return nil, ErrMalformedCheckpoint
+	}
+	size, err := strconv.ParseInt(lines[1], 10, 64)
+	if err != nil || size < 0 || strconv.FormatInt(size, 10) != lines[1] {
+		return nil, ErrMalformedCheckpoint
+	}
+	hash, err := base64.StdEncoding.Strict().DecodeString(lines[2])
+	if err != nil || len(hash) != HashSize {
+		return nil, ErrMalformedCheckpoint
+	}
+	c := &Checkpoint{Origin: lines[0], Size: size}
+	copy(c.Hash[:], hash)
+	return c, nil
+}
@@ -0,0 +1,159 @@
+package tlog
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// Test vectors of the certificate-transparency reference implementation.
+var testLeaves = [][]byte{
+	{},
+	{0x00},
+	{0x10},
+	{0x20, 0x21},
+	{0x30, 0x31},
+	{0x40, 0x41, 0x42, 0x43},
+	{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+	{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+}
+
+var testRoots = []string{
+	"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+	"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+	"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+	"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+	"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+	"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+	"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+	"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+}
+
+// referenceHash is MTH of RFC 6962, section 2.1, over leaf hashes.
+func referenceHash(leaves []Hash) Hash {
+	switch len(leaves) {
+	case 0:
+		return EmptyHash
+	case 1:
+		return leaves[0]
+	}
+	k := split(int64(len(leaves)))
+	return NodeHash(referenceHash(leaves[:k]), referenceHash(leaves[k:]))
+}
+
+func TestTreeHash(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if h, _ := l.TreeHash(0); h != EmptyHash ||
+		hex.EncodeToString(h[:]) != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("empty tree hash %x", h)
+	}
+	for i, leaf := range testLeaves {
+		if _, err := l.Append(leaf); err != nil {
+			t.Fatal(err)
+		}
+		h, err := l.TreeHash(int64(i + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(h[:]) != testRoots[i] {
+			t.Errorf("root of %d leaves %x, want %s", i+1, h, testRoots[i])
+		}
+	}
+	if _, err := l.TreeHash(9); err != ErrInvalidIndex {
+		t.Errorf("TreeHash beyond the log returned %v", err)
+	}
+}
+
+func TestProofs(t *testing.T) {
+	l, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var leaves []Hash
+	const n = 40
+	for i := 0; i < n; i++ {
+		record := []byte(fmt.Sprintf("record %d", i))
+		leaves = append(leaves, RecordHash(record))
+		if _, err := l.Append(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for size := int64(1); size <= n; size++ {
+		root := referenceHash(leaves[:size])
+		for index := int64(0); index < size; index++ {
+			proof, err := l.InclusionProof(index, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyInclusion(index, size, leaves[index], proof, root); err != nil {
+				t.Fatalf("inclusion of %d in %d: %v", index, size, err)
+			}
+			if err := VerifyInclusion(index, size, leaves[(index+1)%n], proof, root); err != ErrInvalidProof {
+				t.Fatalf("inclusion of another record at %d in %d: %v", index, size, err)
+			}
+			if len(proof) > 0 {
+				if err := VerifyInclusion(index, size, leaves[index], proof[:len(proof)-1], root); err != ErrInvalidProof {
+					t.Fatalf("truncated proof of inclusion of %d in %d: %v", index, size, err)
+				}
+			}
+		}
+
+		for oldSize := int64(0); oldSize <= size; oldSize++ {
+			oldRoot := referenceHash(leaves[:oldSize])
+			proof, err := l.ConsistencyProof(oldSize, size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := VerifyConsistency(oldSize, size, oldRoot, root, proof); err != nil {
+				t.Fatalf("consistency of %d and %d: %v", oldSize, size, err)
+			}
+			if oldSize > 0 && oldSize < size {
+				if err := VerifyConsistency(oldSize, size, RecordHash(nil), root, proof); err != ErrInvalidProof {
+					t.Fatalf("consistency of %d and %d with another old root: %v", oldSize, size, err)
+				}
+				if err := VerifyConsistency(oldSize, size, oldRoot, root, append(proof, root)); err != ErrInvalidProof {
+					t.Fatalf("consistency of %d and %d with an extended proof: %v", oldSize, size, err)
+				}
+			}
+		}
+	}
+
+	for _, bad := range [][2]int64{{-1, 4}, {4, 4}, {0, n + 1}} {
+		if _, err := l.InclusionProof(bad[0], bad[1]); err != ErrInvalidIndex {
+			t.Errorf("InclusionProof(%d, %d) returned %v", bad[0], bad[1], err)
+		}
+	}
+	if _, err := l.ConsistencyProof(5, 4); err != ErrInvalidIndex {
+		t.Errorf("ConsistencyProof(5, 4) returned %v", err)
+	}
+}
+
+func TestParseCheckpoint(t *testing.T) {
+	c := &Checkpoint{Origin: "example.com/audit", Size: 12, Hash: RecordHash([]byte("x"))}
+	parsed, err := ParseCheckpoint(c.String())
+	if err != nil || *parsed != *c {
+		t.Errorf("ParseCheckpoint(%q) = %v, %v", c, parsed, err)
+	}
+	if parsed, err := ParseCheckpoint(c.String() + "extension\n"); err != nil || *parsed != *c {
+		t.Errorf("ParseCheckpoint with an extension line = %v, %v", parsed, err)
+	}
+	for _, text := range []string{
+		"",
+		"example.com/audit\n12\n",
+		"\n12\n" + c.Hash.String() + "\n",
+		"example.com/audit\n012\n" + c.Hash.String() + "\n",
+		"example.com/audit\n-1\n" + c.Hash.String() + "\n",
+		"example.com/audit\n12\nAAAA\n",
+		"example.com/audit\n12\n" + c.Hash.String(),
+	} {
+		if _, err := ParseCheckpoint(text); err != ErrMalformedCheckpoint {
+			t.Errorf("ParseCheckpoint(%q) returned %v", text, err)
+		}
+	}
+}
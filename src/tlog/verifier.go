@@ -0,0 +1,76 @@
+package tlog
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Indra4091/falconGo/src/note"
+)
+
+// ErrRollback is returned when a checkpoint is of a smaller tree than the
+// trusted checkpoint.
+var ErrRollback = errors.New("checkpoint of a smaller tree than the trusted checkpoint")
+
+// OpenCheckpoint verifies the signature of a signed checkpoint by key and
+// parses it.
+func OpenCheckpoint(signed []byte, key note.Verifier) (*Checkpoint, error) {
+	n, err := note.Open(signed, note.VerifierList(key))
+	if err != nil {
+		return nil, err
+	}
+	return ParseCheckpoint(n.Text)
+}
+
+// A Verifier checks records and checkpoints of a log against the latest
+// checkpoint it trusts. It is safe for concurrent use.
+type Verifier struct {
+	origin string
+	key    note.Verifier
+
+	mu      sync.Mutex
+	trusted Checkpoint
+}
+
+// NewVerifier returns a Verifier of the log with the given origin, whose
+// checkpoints are signed by key. It trusts the empty tree until Update is
+// called.
+func NewVerifier(origin string, key note.Verifier) *Verifier {
+	return &Verifier{origin: origin, key: key, trusted: Checkpoint{Origin: origin, Hash: EmptyHash}}
+}
+
+// Trusted returns the latest trusted checkpoint.
+func (v *Verifier) Trusted() Checkpoint {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.trusted
+}
+
+// Update verifies a signed checkpoint and the proof of its consistency with
+// the trusted checkpoint, and then trusts it.
+func (v *Verifier) Update(signed []byte, proof []Hash) (*Checkpoint, error) {
+	c, err := OpenCheckpoint(signed, v.key)
+	if err != nil {
+		return nil, err
+	}
+	if c.Origin != v.origin {
+		return nil, ErrOriginMismatch
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if c.Size < v.trusted.Size {
+		return nil, ErrRollback
+	}
+	if err := VerifyConsistency(v.trusted.Size, c.Size, v.trusted.Hash, c.Hash, proof); err != nil {
+		return nil, err
+	}
+	v.trusted = *c
+	return c, nil
+}
+
+// VerifyRecord checks that proof proves the inclusion of record at index
+// in the tree of the trusted checkpoint.
+func (v *Verifier) VerifyRecord(index int64, record []byte, proof []Hash) error {
+	c := v.Trusted()
+	return VerifyInclusion(index, c.Size, RecordHash(record), proof, c.Hash)
+}
@@ -0,0 +1,110 @@
+package falcon
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Indra4091/falconGo/src/internal"
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+var (
+	// ErrCoefficientRange is returned when a key coefficient is out of the range of its degree
+	ErrCoefficientRange = errors.New("key coefficient out of range")
+	// ErrNTRUEquation is returned when a private key does not satisfy fG - gF = q mod (x^n + 1)
+	ErrNTRUEquation = errors.New("private key does not satisfy the NTRU equation")
+	// ErrNotInvertible is returned when f is not invertible mod q
+	ErrNotInvertible = errors.New("f is not invertible mod q")
+	// ErrGramSchmidtNorm is returned when the Gram-Schmidt norm of the private basis is too large
+	ErrGramSchmidtNorm = errors.New("Gram-Schmidt norm of the private basis is too large")
+)
+
+// maxGsNorm is the bound on the squared Gram-Schmidt norm of the private
+// basis enforced by key generation, 1.17^2 * q.
+var maxGsNorm = math.Pow(1.17, 2) * util.Q
+
+// Validate checks that the public key has a valid degree and that its
+// coefficients lie in [0, q).
+func (pubKey *PublicKey) Validate() error {
+	if !isValidDegree(pubKey.n) || len(pubKey.h) != int(pubKey.n) {
+		return ErrInvalidDegree
+	}
+	for _, coef := range pubKey.h {
+		if coef < 0 || int(coef) >= util.Q {
+			return ErrCoefficientRange
+		}
+	}
+	return nil
+}
+
+// Validate checks that the private key is a valid Falcon key: f, g, F and
+// G have n coefficients within the bounds of the key encoding for the
+// degree, satisfy fG - gF = q mod (x^n + 1), f is invertible mod q, and
+// the Gram-Schmidt norm of the basis is within the bound of key
+// generation. A key which fails these checks may produce signatures which
+// do not verify, or leak information about the key.
+func (privKey *PrivateKey) Validate() error {
+	n := privKey.n
+	if !isValidDegree(n) {
+		return ErrInvalidDegree
+	}
+	if !isValidPolysLength(n, privKey.f, privKey.g, privKey.F, privKey.G) {
+		return ErrInvalidPolysLength
+	}
+	logn := LOGN[n]
+	maxFg := int16(1<<(maxFgBits[logn]-1)) - 1
+	maxFG := int16(1<<(maxFGBits[logn]-1)) - 1
+	for i, poly := range [][]int16{privKey.f, privKey.g, privKey.F, privKey.G} {
+		bound := maxFg
+		if i >= 2 {
+			bound = maxFG
+		}
+		for _, coef := range poly {
+			if coef < -bound || coef > bound {
+				return ErrCoefficientRange
+			}
+		}
+	}
+
+	// fG - gF = q, computed exactly
+	fG := negacyclicMul(privKey.f, privKey.G)
+	gF := negacyclicMul(privKey.g, privKey.F)
+	for i := range fG {
+		want := int64(0)
+		if i == 0 {
+			want = util.Q
+		}
+		if fG[i]-gF[i] != want {
+			return ErrNTRUEquation
+		}
+	}
+
+	if util.AnyZeroes(ntt.NTT(privKey.f)) {
+		return ErrNotInvertible
+	}
+	if internal.GsNorm(util.Int16ToFloat64(privKey.f), util.Int16ToFloat64(privKey.g), util.Q) > maxGsNorm {
+		return ErrGramSchmidtNorm
+	}
+	return nil
+}
+
+// negacyclicMul returns a * b in Z[x]/(x^n + 1).
+func negacyclicMul(a, b []int16) []int64 {
+	n := len(a)
+	res := make([]int64, n)
+	for i, ai := range a {
+		if ai == 0 {
+			continue
+		}
+		for j, bj := range b {
+			v := int64(ai) * int64(bj)
+			if i+j < n {
+				res[i+j] += v
+			} else {
+				res[i+j-n] -= v
+			}
+		}
+	}
+	return res
+}
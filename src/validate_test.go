@@ -0,0 +1,82 @@
+package falcon
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	if err := firstPrivKey512.Validate(); err != nil {
+		t.Fatalf("Validate of a KAT key returned %v", err)
+	}
+	if err := firstPrivKey512.GetPublicKey().Validate(); err != nil {
+		t.Fatalf("Validate of a KAT public key returned %v", err)
+	}
+	privKey, err := GeneratePrivateKey(64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := privKey.Validate(); err != nil {
+		t.Errorf("Validate of a generated key returned %v", err)
+	}
+
+	polys := firstPrivKey512.Polynomials()
+	f, g, F, G := polys[0], polys[1], polys[2], polys[3]
+	modified := func(poly []int16, i int, v int16) []int16 {
+		poly = append([]int16(nil), poly...)
+		poly[i] = v
+		return poly
+	}
+	testCases := []struct {
+		name       string
+		n          uint16
+		f, g, F, G []int16
+		err        error
+	}{
+		// The total length is 4n, but the lengths differ
+		{"lengths", 512, f, g[:256], F, append(G, G[:256]...), ErrInvalidPolysLength},
+		{"f bound", 512, modified(f, 0, 32), g, F, G, ErrCoefficientRange},
+		{"G bound", 512, f, g, F, modified(G, 3, -128), ErrCoefficientRange},
+		{"equation", 512, f, g, modified(F, 0, F[0]+1), G, ErrNTRUEquation},
+		// f = 25 + 108x has norm q, so it vanishes at a root of x^2 + 1 mod q
+		{"invertibility", 2, []int16{25, 108}, []int16{0, 0}, []int16{0, 0}, []int16{25, -108}, ErrNotInvertible},
+		// ||(f, g)||^2 = 18890 > 1.17^2 q
+		{"norm", 2, []int16{1, 0}, []int16{100, 83}, []int16{-73, 61}, []int16{-74, 41}, ErrGramSchmidtNorm},
+	}
+	for _, tc := range testCases {
+		privKey := &PrivateKey{n: tc.n, f: tc.f, g: tc.g, F: tc.F, G: tc.G}
+		if err := privKey.Validate(); err != tc.err {
+			t.Errorf("%s: Validate returned %v, want %v", tc.name, err, tc.err)
+		}
+		if _, _, err := NewKeyPairFromPrivateKey(tc.n, [4][]int16{tc.f, tc.g, tc.F, tc.G}); err == nil {
+			t.Errorf("%s: NewKeyPairFromPrivateKey succeeded", tc.name)
+		}
+	}
+
+	// h is nil when f is not invertible
+	notInvertible := &PrivateKey{n: 2, f: []int16{25, 108}, g: []int16{0, 0}, F: []int16{0, 0}, G: []int16{25, -108}}
+	if err := notInvertible.GetPublicKey().Validate(); err == nil {
+		t.Error("Validate of the public key of a non-invertible f succeeded")
+	}
+	if _, err := notInvertible.DerivePublicKey(); err != ErrNotInvertible {
+		t.Errorf("DerivePublicKey of a non-invertible f returned %v", err)
+	}
+	if pubKey, err := firstPrivKey512.DerivePublicKey(); err != nil || !pubKey.Equal(firstPrivKey512.GetPublicKey()) {
+		t.Errorf("DerivePublicKey returned %v", err)
+	}
+	if pubKey := notInvertible.Public(); pubKey != nil {
+		t.Errorf("Public of a non-invertible f returned %v", pubKey)
+	}
+	if _, err := CreateCertificateRequest(nil, &CertificateRequest{}, notInvertible); err != ErrNotInvertible {
+		t.Errorf("CreateCertificateRequest with a non-invertible f returned %v", err)
+	}
+	for _, pubKey := range []*PublicKey{
+		{n: 512, h: make([]int16, 256)},
+		{n: 48, h: make([]int16, 48)},
+		{n: 4, h: []int16{0, 1, 12289, 2}},
+		{n: 4, h: []int16{0, -1, 1, 2}},
+	} {
+		if err := pubKey.Validate(); err == nil {
+			t.Errorf("Validate of %v succeeded", pubKey)
+		}
+	}
+}
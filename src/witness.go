@@ -0,0 +1,149 @@
+package falcon
+
+import (
+	"math/big"
+
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+/*
+Verification witnesses for zero-knowledge circuits.
+
+The witness follows the notation of the Falcon specification, where the
+signature carries s2 and verification recomputes s1 = c - s2*h mod q (this
+package calls them s1 and s0 elsewhere). The product s2*h is computed in the
+NTT domain, as by the circuits: NTT(s2) and NTT(h) are multiplied pointwise
+and the product is brought back with the inverse NTT.
+*/
+
+// FieldBN254 is the scalar field modulus of BN254, used by most SNARKs on Ethereum.
+var FieldBN254, _ = new(big.Int).SetString("21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+
+// Witness holds every intermediate value of the verification of a signature.
+type Witness struct {
+	N uint16 `json:"n"`
+	// Format is the SignatureFormat.String of the format read from the
+	// header: "compressed" for padded and compressed signatures, which share
+	// their header, or "ct".
+	Format string `json:"format"`
+	Salt   []byte `json:"salt"`
+	// C is the hashed point c = HashToPoint(salt ‖ message), in [0, q).
+	C []int16 `json:"c"`
+	// S2 is the decompressed signature polynomial, with signed coefficients.
+	S2 []int16 `json:"s2"`
+	// H is the public key polynomial, in [0, q).
+	H []int16 `json:"h"`
+	// S2NTT, HNTT and ProductNTT are NTT(s2), NTT(h) and their pointwise
+	// product, in [0, q).
+	S2NTT      []int16 `json:"s2_ntt"`
+	HNTT       []int16 `json:"h_ntt"`
+	ProductNTT []int16 `json:"product_ntt"`
+	// S2H is s2*h mod q, the inverse NTT of ProductNTT, in [0, q).
+	S2H []int16 `json:"s2h"`
+	// S1Reduced is c - s2*h mod q in [0, q) and S1 its centered
+	// representative in [-q/2, q/2].
+	S1Reduced []int16 `json:"s1_reduced"`
+	S1        []int16 `json:"s1"`
+	// Norm is ||s1||^2 + ||s2||^2, accepted if at most Bound.
+	Norm  uint64 `json:"norm"`
+	Bound uint64 `json:"bound"`
+	Valid bool   `json:"valid"`
+}
+
+// newWitness computes the witness of the verification of s2 against the
+// point c under h. It returns an error if n is not a supported degree or if
+// the lengths do not match n.
+func newWitness(n uint16, h, c, s2 []int16) (*Witness, error) {
+	param := GetParamSet(n)
+	if param.n == 0 || len(h) != int(n) {
+		return nil, ErrInvalidDegree
+	}
+	if len(c) != int(n) || len(s2) != int(n) {
+		return nil, ErrInvalidPolysLength
+	}
+	w := &Witness{N: n, C: c, S2: s2, H: h, Bound: uint64(param.sigbound)}
+	w.S2NTT = ntt.NTT(s2)
+	w.HNTT = ntt.NTT(h)
+	w.ProductNTT = make([]int16, n)
+	for i := range w.ProductNTT {
+		w.ProductNTT[i] = int16(int(w.S2NTT[i]) * int(w.HNTT[i]) % util.Q)
+	}
+	w.S2H = ntt.INTT(w.ProductNTT)
+	s1, err := ntt.SubZq(c, w.S2H)
+	if err != nil {
+		return nil, err
+	}
+	w.S1Reduced = s1
+	w.S1 = make([]int16, n)
+	// The norm is accumulated on 64 bits: with 32 bits, the squares of
+	// the coefficients of an invalid signature could wrap below the bound.
+	for i, v := range w.S1Reduced {
+		w.S1[i] = int16((int(v)+(util.Q>>1))%util.Q - (util.Q >> 1))
+		w.Norm += uint64(int64(w.S1[i]) * int64(w.S1[i]))
+	}
+	for _, v := range s2 {
+		w.Norm += uint64(int64(v) * int64(v))
+	}
+	w.Valid = w.Norm <= w.Bound
+	return w, nil
+}
+
+// ExplainVerify runs the verification of signature of message under pubKey,
+// as Verify, and returns its witness. A signature that decodes but is
+// rejected yields a witness with Valid set to false; an error is returned
+// if the signature or the key cannot be decoded.
+func (pubKey *PublicKey) ExplainVerify(message, signature []byte) (*Witness, error) {
+	if !isValidDegree(pubKey.n) || len(pubKey.h) != int(pubKey.n) {
+		return nil, ErrInvalidDegree
+	}
+	format, salt, s2, err := decodeSignature(signature, pubKey.n)
+	if err != nil {
+		return nil, err
+	}
+	var c []int16
+	if format.usesCTHash() {
+		c = hashToPointCT(message, salt, pubKey.n)
+	} else {
+		c = hashToPointN(message, salt, pubKey.n)
+	}
+	w, err := newWitness(pubKey.n, pubKey.h, c, s2)
+	if err != nil {
+		return nil, err
+	}
+	w.Format = format.String()
+	w.Salt = append([]byte(nil), salt...)
+	return w, nil
+}
+
+// FieldElements encodes the witness as elements of the prime field of the
+// given modulus, such as FieldBN254. Each element is little endian over the
+// bytelength of the modulus, and negative values v are encoded as
+// modulus - |v|. The elements are c, s2, h, NTT(s2), NTT(h), the NTT product,
+// s2*h, s1 before and after centering (n elements each), then the norm, the
+// bound and the validity as 0 or 1.
+func (w *Witness) FieldElements(modulus *big.Int) []byte {
+	size := (modulus.BitLen() + 7) / 8
+	polys := [][]int16{w.C, w.S2, w.H, w.S2NTT, w.HNTT, w.ProductNTT, w.S2H, w.S1Reduced, w.S1}
+	out := make([]byte, 0, size*(len(polys)*int(w.N)+3))
+	appendElement := func(v *big.Int) {
+		v.Mod(v, modulus)
+		be := v.FillBytes(make([]byte, size))
+		for i := len(be) - 1; i >= 0; i-- {
+			out = append(out, be[i])
+		}
+	}
+	for _, poly := range polys {
+		for _, c := range poly {
+			appendElement(big.NewInt(int64(c)))
+		}
+	}
+	valid := int64(0)
+	if w.Valid {
+		valid = 1
+	}
+	appendElement(new(big.Int).SetUint64(w.Norm))
+	appendElement(new(big.Int).SetUint64(w.Bound))
+	appendElement(big.NewInt(valid))
+	return out
+}
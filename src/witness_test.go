@@ -0,0 +1,127 @@
+package falcon
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Indra4091/falconGo/src/internal/transforms/ntt"
+	"github.com/Indra4091/falconGo/src/util"
+)
+
+func TestExplainVerify(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	message := []byte("message")
+	for _, format := range []SignatureFormat{FormatPadded, FormatCT} {
+		sig, err := firstPrivKey512.signWithRand(message, format, deterministicReader("witness"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := pub.ExplainVerify(message, sig)
+		if err != nil {
+			t.Fatalf("%v: ExplainVerify returned %v", format, err)
+		}
+		// Padded and compressed signatures are both reported as compressed
+		if !w.Valid || w.Norm > w.Bound || (w.Format == FormatCT.String()) != (format == FormatCT) {
+			t.Errorf("%v: witness of a valid signature: valid %v, norm %d, format %s", format, w.Valid, w.Norm, w.Format)
+		}
+		if !equalInt16(w.C, hashToPointN(message, sig[HeadLen:HeadLen+SaltLen], 512)) {
+			t.Errorf("%v: c is not the hashed point", format)
+		}
+		s2h, _ := ntt.MulZq(w.S2, pub.h)
+		if !equalInt16(w.S2H, s2h) || !equalInt16(w.S2H, ntt.INTT(w.ProductNTT)) {
+			t.Errorf("%v: s2*h does not match", format)
+		}
+		if s1, _ := ntt.SubZq(w.C, w.S2H); !equalInt16(w.S1Reduced, s1) {
+			t.Errorf("%v: s1 does not match c - s2*h", format)
+		}
+		var norm uint64
+		for i := range w.S1 {
+			if d := int(w.S1[i]) - int(w.S1Reduced[i]); d != 0 && d != -util.Q || w.S1[i] < -util.Q/2 || w.S1[i] > util.Q/2 {
+				t.Fatalf("%v: s1[%d] = %d is not the centered %d", format, i, w.S1[i], w.S1Reduced[i])
+			}
+			norm += uint64(int64(w.S1[i])*int64(w.S1[i])) + uint64(int64(w.S2[i])*int64(w.S2[i]))
+		}
+		if norm != w.Norm {
+			t.Errorf("%v: norm = %d, want %d", format, w.Norm, norm)
+		}
+	}
+
+	if _, err := pub.ExplainVerify(message, []byte{0x39}); err == nil {
+		t.Error("ExplainVerify accepted a truncated signature")
+	}
+}
+
+func TestWitnessEncodings(t *testing.T) {
+	pub := firstPrivKey512.GetPublicKey()
+	sig, err := firstPrivKey512.signWithRand([]byte("message"), FormatPadded, deterministicReader("witness"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A rejected signature still has a full witness
+	w, err := pub.ExplainVerify([]byte("other message"), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Valid || w.Norm <= w.Bound {
+		t.Errorf("witness of a rejected signature: valid %v, norm %d", w.Valid, w.Norm)
+	}
+
+	data, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Witness
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !equalInt16(decoded.S1, w.S1) || decoded.Norm != w.Norm || decoded.Valid || !bytes.Equal(decoded.Salt, w.Salt) {
+		t.Error("the JSON witness does not round-trip")
+	}
+	for _, key := range []string{`"c":`, `"s2":`, `"h_ntt":`, `"product_ntt":`, `"s1_reduced":`, `"norm":`} {
+		if !bytes.Contains(data, []byte(key)) {
+			t.Errorf("JSON witness without %s", key)
+		}
+	}
+
+	elements := w.FieldElements(FieldBN254)
+	if len(elements) != 32*(9*512+3) {
+		t.Fatalf("FieldElements returned %d bytes", len(elements))
+	}
+	element := func(i int) *big.Int {
+		le := elements[32*i : 32*i+32]
+		be := make([]byte, 32)
+		for j := range le {
+			be[31-j] = le[j]
+		}
+		return new(big.Int).SetBytes(be)
+	}
+	// s2 starts at element n; negative coefficients are p - |v|
+	for i, v := range w.S2 {
+		want := new(big.Int).Mod(big.NewInt(int64(v)), FieldBN254)
+		if element(512+i).Cmp(want) != 0 {
+			t.Fatalf("element of s2[%d] = %v, want %v", i, element(512+i), want)
+		}
+	}
+	if element(9*512).Uint64() != w.Norm || element(9*512+2).Sign() != 0 {
+		t.Error("norm or validity element does not match")
+	}
+}
+
+func TestNewWitnessLengths(t *testing.T) {
+	h := firstPrivKey512.GetPublicKey().h
+	point := make([]int16, 512)
+	if _, err := newWitness(512, h, point, make([]int16, 511)); err != ErrInvalidPolysLength {
+		t.Errorf("short s2: newWitness returned %v", err)
+	}
+	if _, err := newWitness(512, h[:256], point, point); err != ErrInvalidDegree {
+		t.Errorf("short h: newWitness returned %v", err)
+	}
+	if _, err := newWitness(500, h, point, point); err != ErrInvalidDegree {
+		t.Errorf("unsupported degree: newWitness returned %v", err)
+	}
+	if verifyPoint(512, h, point, make([]int16, 1024)) {
+		t.Error("verifyPoint accepted an s1 of another degree")
+	}
+}